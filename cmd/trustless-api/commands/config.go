@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	home, _ := os.UserHomeDir()
+	defaultPath := fmt.Sprintf("%v/.trustless-api/config.yml", home)
+	configValidateCmd.Flags().StringVar(&configPath, "config", defaultPath, "sets the config that is validated")
+	configInitCmd.Flags().StringVar(&configPath, "config", defaultPath, "sets the config that is created")
+
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the config of the Trustless API",
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create the config from the template",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.InitConfig(configPath); err != nil {
+			fmt.Printf("failed to create config %v: %v\n", configPath, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("created config %v, add your pools and run `trustless-api config validate`\n", configPath)
+	},
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Validate the config and list all problems",
+	Run: func(cmd *cobra.Command, args []string) {
+		if err := config.ReadConfig(configPath); err != nil {
+			fmt.Printf("failed to read config %v: %v\n", configPath, err)
+			os.Exit(1)
+		}
+
+		if err := config.Validate(); err != nil {
+			fmt.Printf("config %v is invalid:\n%v\n", configPath, err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("config %v is valid\n", configPath)
+	},
+}
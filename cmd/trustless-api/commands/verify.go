@@ -0,0 +1,51 @@
+package commands
+
+import (
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/KYVENetwork/trustless-api/verify"
+	"github.com/spf13/cobra"
+)
+
+var (
+	verifyUrl       string
+	verifyChainRest string
+)
+
+func init() {
+	verifyCmd.Flags().StringVar(&verifyUrl, "url", "", "trustless api url that should be verified, e.g. https://data.services.kyve.network/ethereum/beacon/blob_sidecars?block_height=19426587")
+	verifyCmd.Flags().StringVar(&verifyChainRest, "chain-rest", "", "custom KYVE rest endpoint used to fetch the bundle merkle root")
+
+	_ = verifyCmd.MarkFlagRequired("url")
+
+	rootCmd.AddCommand(verifyCmd)
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the inclusion proof of a Trustless API response against KYVE",
+	Run: func(cmd *cobra.Command, args []string) {
+		if verifyChainRest != "" {
+			for chainId := range config.Endpoints.Chains {
+				config.Endpoints.Chains[chainId] = []string{verifyChainRest}
+			}
+			// the custom rest endpoint takes precedence over configured gRPC endpoints
+			config.Endpoints.Grpc = nil
+		}
+
+		proofs, err := verify.VerifyUrlItems(verifyUrl)
+		if err != nil {
+			logger.Fatal().Err(err).Str("url", verifyUrl).Msg("verification failed")
+		}
+
+		for _, proof := range proofs {
+			logger.Info().
+				Str("chainId", proof.ChainId).
+				Int64("poolId", proof.PoolId).
+				Int64("bundleId", proof.BundleId).
+				Msg("successfully verified response")
+		}
+		if len(proofs) == 0 {
+			logger.Info().Msg("response contains no items to verify")
+		}
+	},
+}
@@ -4,39 +4,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"strconv"
+	"strings"
 
 	"github.com/KYVENetwork/trustless-api/config"
 	"github.com/KYVENetwork/trustless-api/types"
 	"github.com/KYVENetwork/trustless-api/utils"
 )
 
-func GetFinalizedBundle(chainId string, poolId int64, bundleId int64) (*types.FinalizedBundle, error) {
-	restEndpoint := config.Endpoints.Chains[chainId]
-
-	var raw []byte
-	var err error
-	for _, r := range restEndpoint {
-		raw, err = utils.GetFromUrlWithBackoff(fmt.Sprintf(
-			"%s/kyve/v1/bundles/%d/%d",
-			r,
-			poolId,
-			bundleId,
-		))
-		if err == nil {
-			break
-		}
+// GetSummaryMerkleRoot returns the merkle root a finalized bundle has committed to in its bundle summary
+func GetSummaryMerkleRoot(bundle *types.FinalizedBundle) (string, error) {
+	var summary types.BundleSummary
+	if err := json.Unmarshal([]byte(bundle.BundleSummary), &summary); err != nil {
+		return "", fmt.Errorf("failed to parse bundle summary of bundle %v: %w", bundle.Id, err)
 	}
-	if err != nil {
-		return nil, err
-	}
-
-	var finalizedBundle types.FinalizedBundle
 
-	if err := json.Unmarshal(raw, &finalizedBundle); err != nil {
-		return nil, err
+	if summary.MerkleRoot == "" {
+		return "", fmt.Errorf("bundle summary of bundle %v has no merkle root", bundle.Id)
 	}
 
-	return &finalizedBundle, nil
+	return strings.ToLower(summary.MerkleRoot), nil
 }
 
 func GetDataFromFinalizedBundle(bundle types.FinalizedBundle) ([]byte, error) {
@@ -0,0 +1,32 @@
+package chain
+
+import (
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+)
+
+var (
+	logger = utils.TrustlessApiLogger("chain")
+)
+
+// ChainClient queries a KYVE chain for the pools and finalized bundles that are crawled and verified
+type ChainClient interface {
+	// GetPoolInfo returns the pool with the given id
+	GetPoolInfo(poolId int64) (*types.PoolResponse, error)
+	// GetFinalizedBundle returns the finalized bundle with the given id
+	GetFinalizedBundle(poolId int64, bundleId int64) (*types.FinalizedBundle, error)
+	// GetFinalizedBundles returns a page of at most `limit` finalized bundles starting at the bundle id `offset`.
+	// The ids of finalized bundles are consecutive, so the offset is the id of the first bundle in the page.
+	GetFinalizedBundles(poolId int64, offset int64, limit int) (*types.FinalizedBundlesResponse, error)
+}
+
+// NewClient returns the client of a chain based on the configured endpoints.
+// If gRPC endpoints are configured for the chain they are used, otherwise the REST endpoints.
+func NewClient(chainId string) ChainClient {
+	if endpoints := config.Endpoints.Grpc[chainId]; len(endpoints) > 0 {
+		return NewGrpcClient(endpoints)
+	}
+
+	return NewRestClient(config.Endpoints.Chains[chainId])
+}
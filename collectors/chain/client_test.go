@@ -0,0 +1,209 @@
+package chain_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KYVENetwork/trustless-api/collectors/chain"
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/KYVENetwork/trustless-api/internal/testutil"
+	"github.com/KYVENetwork/trustless-api/types"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+var _ chain.ChainClient = (*testutil.Chain)(nil)
+
+// clients returns the REST and gRPC client of the fake chain
+func clients(t *testing.T) (*testutil.Chain, map[string]chain.ChainClient) {
+	testutil.LoadConfig(t)
+	fakeChain := testutil.NewChain(t, "kyve-1")
+	if err := fakeChain.AddFixtureBundles(1, testutil.Fixtures[0], 0, 2, 3, 1, 4, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	return fakeChain, map[string]chain.ChainClient{
+		"rest": chain.NewRestClient([]string{fakeChain.URL()}),
+		"grpc": chain.NewGrpcClient([]string{fakeChain.GrpcURL()}),
+	}
+}
+
+func TestChainClients(t *testing.T) {
+	fakeChain, clients := clients(t)
+
+	expectedPool, _ := fakeChain.GetPoolInfo(1)
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			pool, err := client.GetPoolInfo(1)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(pool, expectedPool) {
+				t.Errorf("expected pool %+v, got %+v", expectedPool, pool)
+			}
+
+			for bundleId := int64(0); bundleId < 5; bundleId++ {
+				expected, _ := fakeChain.GetFinalizedBundle(1, bundleId)
+				finalizedBundle, err := client.GetFinalizedBundle(1, bundleId)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(finalizedBundle, expected) {
+					t.Errorf("bundle %v: expected %+v, got %+v", bundleId, expected, finalizedBundle)
+				}
+			}
+
+			// page through the bundles with a limit that doesn't divide the bundle count
+			var offset int64
+			for page := 0; ; page++ {
+				expected, _ := fakeChain.GetFinalizedBundles(1, offset, 2)
+				finalizedBundles, err := client.GetFinalizedBundles(1, offset, 2)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !reflect.DeepEqual(finalizedBundles, expected) {
+					t.Fatalf("page %v: expected %+v, got %+v", page, expected, finalizedBundles)
+				}
+				if len(finalizedBundles.Pagination.NextKey) == 0 {
+					break
+				}
+				offset += int64(len(finalizedBundles.FinalizedBundles))
+			}
+			if offset != 4 {
+				t.Errorf("expected the last page to start at bundle 4, got %v", offset)
+			}
+		})
+	}
+}
+
+func TestChainClientErrors(t *testing.T) {
+	_, clients := clients(t)
+
+	for name, client := range clients {
+		t.Run(name, func(t *testing.T) {
+			if _, err := client.GetPoolInfo(2); err == nil {
+				t.Error("expected error for unknown pool")
+			}
+			if _, err := client.GetFinalizedBundle(1, 5); err == nil {
+				t.Error("expected error for unknown bundle")
+			}
+		})
+	}
+}
+
+func TestNewClient(t *testing.T) {
+	fakeChain, _ := clients(t)
+
+	if _, ok := chain.NewClient("kyve-1").(*chain.RestClient); !ok {
+		t.Error("expected rest client without grpc endpoints")
+	}
+
+	config.Endpoints.Grpc = map[string][]string{"kyve-1": {fakeChain.GrpcURL()}}
+	if _, ok := chain.NewClient("kyve-1").(*chain.GrpcClient); !ok {
+		t.Error("expected grpc client with grpc endpoints")
+	}
+
+	if _, err := chain.NewClient("kaon-1").GetPoolInfo(1); err == nil {
+		t.Error("expected error for chain without endpoints")
+	}
+}
+
+// readGolden reads a hex encoded message of testdata/grpc
+func readGolden(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", "grpc", name+".hex"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message, err := hex.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return message
+}
+
+// TestGrpcClientGolden checks the method paths and the encoding of the gRPC client against messages that were
+// encoded independently of the client with all fields of kyve/query/v1beta1/pools.proto and bundles.proto
+func TestGrpcClientGolden(t *testing.T) {
+	methods := map[string]string{
+		"/kyve.query.v1beta1.QueryPool/Pool":                     "pool",
+		"/kyve.query.v1beta1.QueryBundles/FinalizedBundleQuery":  "finalized_bundle",
+		"/kyve.query.v1beta1.QueryBundles/FinalizedBundlesQuery": "finalized_bundles",
+	}
+
+	server := httptest.NewServer(h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, ok := methods[r.URL.Path]
+		body, _ := io.ReadAll(r.Body)
+		if !ok || len(body) < 5 || !bytes.Equal(body[5:], readGolden(t, name+"_request")) {
+			w.Header().Set("Grpc-Status", "3")
+			w.Header().Set("Grpc-Message", fmt.Sprintf("unexpected request %v %x", r.URL.Path, body))
+			return
+		}
+
+		response := readGolden(t, name+"_response")
+		message := make([]byte, 5, 5+len(response))
+		binary.BigEndian.PutUint32(message[1:], uint32(len(response)))
+		w.Header().Set("Content-Type", "application/grpc+proto")
+		w.Header().Set("Trailer", "Grpc-Status")
+		_, _ = w.Write(append(message, response...))
+		w.Header().Set("Grpc-Status", "0")
+	}), &http2.Server{}))
+	t.Cleanup(server.Close)
+
+	client := chain.NewGrpcClient([]string{server.URL})
+
+	pool, err := client.GetPoolInfo(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var expectedPool types.PoolResponse
+	if err := json.Unmarshal([]byte(`{"pool": {"id": 1, "data": {"runtime": "@kyvejs/tendermint", "config": "ar://config", "start_key": "1", "current_key": "2000", "total_bundles": 20}}}`), &expectedPool); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(*pool, expectedPool) {
+		t.Errorf("expected pool %+v, got %+v", expectedPool, *pool)
+	}
+
+	finalizedBundle := func(id int, from, to string) types.FinalizedBundle {
+		return types.FinalizedBundle{
+			Id:                fmt.Sprint(id),
+			StorageId:         fmt.Sprintf("storage-%v", id),
+			StorageProviderId: "2",
+			CompressionId:     "1",
+			FromKey:           from,
+			ToKey:             to,
+			DataHash:          fmt.Sprintf("hash-%v", id),
+			BundleSummary:     fmt.Sprintf(`{"merkle_root":"%064x"}`, id),
+		}
+	}
+
+	bundle, err := client.GetFinalizedBundle(1, 42)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := finalizedBundle(42, "4200", "4299"); !reflect.DeepEqual(*bundle, expected) {
+		t.Errorf("expected finalized bundle %+v, got %+v", expected, *bundle)
+	}
+
+	bundles, err := client.GetFinalizedBundles(1, 10, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedBundles := []types.FinalizedBundle{finalizedBundle(10, "1000", "1099"), finalizedBundle(11, "1100", "1199")}
+	if !reflect.DeepEqual(bundles.FinalizedBundles, expectedBundles) {
+		t.Errorf("expected finalized bundles %+v, got %+v", expectedBundles, bundles.FinalizedBundles)
+	}
+	if expectedKey := []byte{0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 12}; !bytes.Equal(bundles.Pagination.NextKey, expectedKey) {
+		t.Errorf("expected next key %x, got %x", expectedKey, bundles.Pagination.NextKey)
+	}
+}
@@ -0,0 +1,320 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+	"golang.org/x/net/http2"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// the methods of the services in kyve/query/v1beta1/pools.proto and kyve/query/v1beta1/bundles.proto
+const (
+	grpcMethodPool             = "/kyve.query.v1beta1.QueryPool/Pool"
+	grpcMethodFinalizedBundle  = "/kyve.query.v1beta1.QueryBundles/FinalizedBundleQuery"
+	grpcMethodFinalizedBundles = "/kyve.query.v1beta1.QueryBundles/FinalizedBundlesQuery"
+)
+
+// GrpcClient queries the gRPC API of a KYVE node, the endpoints are tried in order until one succeeds.
+//
+// The requests and responses are encoded by hand with the field numbers of the KYVE query protos,
+// so only the fields that are needed to crawl and verify bundles are decoded.
+// testdata/grpc contains the encoded messages of every method, all fields of the protos are set there.
+// Endpoints with the scheme `http` use HTTP/2 without TLS, e.g. `http://localhost:9090`.
+type GrpcClient struct {
+	endpoints []string
+	plaintext *http.Client
+	tls       *http.Client
+}
+
+func NewGrpcClient(endpoints []string) *GrpcClient {
+	return &GrpcClient{
+		endpoints: endpoints,
+		plaintext: &http.Client{Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+				var dialer net.Dialer
+				return dialer.DialContext(ctx, network, addr)
+			},
+		}},
+		tls: &http.Client{Transport: &http2.Transport{}},
+	}
+}
+
+// invoke calls a unary gRPC method on the first endpoint that responds successfully
+func (c *GrpcClient) invoke(method string, request []byte) ([]byte, error) {
+	err := fmt.Errorf("no grpc endpoints configured")
+	for _, endpoint := range c.endpoints {
+		for i := 0; i < utils.BackoffMaxRetries; i++ {
+			var response []byte
+			response, err = c.invokeEndpoint(endpoint, method, request)
+			if err == nil {
+				return response, nil
+			}
+
+			logger.Error().Err(err).Str("endpoint", endpoint).Msg(fmt.Sprintf("failed to call %v, retrying in 500ms", method))
+			time.Sleep(500 * time.Millisecond)
+		}
+	}
+
+	return nil, fmt.Errorf("failed to call %v: %w", method, err)
+}
+
+func (c *GrpcClient) invokeEndpoint(endpoint string, method string, request []byte) ([]byte, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	client := c.tls
+	if u.Scheme == "http" {
+		client = c.plaintext
+	}
+
+	// every message is prefixed with a compression flag and its length
+	body := make([]byte, 5, 5+len(request))
+	binary.BigEndian.PutUint32(body[1:], uint32(len(request)))
+	body = append(body, request...)
+
+	httpRequest, err := http.NewRequest(http.MethodPost, u.JoinPath(method).String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpRequest.Header.Set("Content-Type", "application/grpc+proto")
+	httpRequest.Header.Set("TE", "trailers")
+
+	response, err := client.Do(httpRequest)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("got status code %d != 200", response.StatusCode)
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	// the status is sent in the trailers, or in the headers if the response has no body
+	status, message := response.Trailer.Get("Grpc-Status"), response.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, message = response.Header.Get("Grpc-Status"), response.Header.Get("Grpc-Message")
+	}
+	if code, err := strconv.Atoi(status); err != nil || code != 0 {
+		return nil, fmt.Errorf("got grpc status %v: %v", status, message)
+	}
+
+	if len(data) < 5 {
+		return nil, fmt.Errorf("grpc response is too short")
+	}
+	if data[0] != 0 {
+		return nil, fmt.Errorf("compressed grpc responses are not supported")
+	}
+	length := binary.BigEndian.Uint32(data[1:5])
+	if uint32(len(data)-5) < length {
+		return nil, fmt.Errorf("grpc response is truncated")
+	}
+
+	return data[5 : 5+length], nil
+}
+
+func (c *GrpcClient) GetPoolInfo(poolId int64) (*types.PoolResponse, error) {
+	// QueryPoolRequest: id = 1
+	request := protowire.AppendTag(nil, 1, protowire.VarintType)
+	request = protowire.AppendVarint(request, uint64(poolId))
+
+	response, err := c.invoke(grpcMethodPool, request)
+	if err != nil {
+		return nil, err
+	}
+
+	var poolResponse types.PoolResponse
+	// QueryPoolResponse: pool = 1
+	err = consumeFields(response, func(num protowire.Number, _ uint64, value []byte) error {
+		if num != 1 {
+			return nil
+		}
+		// PoolResponse: id = 1, data = 2
+		return consumeFields(value, func(num protowire.Number, varint uint64, value []byte) error {
+			switch num {
+			case 1:
+				poolResponse.Pool.Id = int64(varint)
+			case 2:
+				return decodePool(value, &poolResponse)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode pool response: %w", err)
+	}
+
+	return &poolResponse, nil
+}
+
+func (c *GrpcClient) GetFinalizedBundle(poolId int64, bundleId int64) (*types.FinalizedBundle, error) {
+	// QueryFinalizedBundleRequest: pool_id = 1, id = 2, the response is the FinalizedBundle itself
+	request := protowire.AppendTag(nil, 1, protowire.VarintType)
+	request = protowire.AppendVarint(request, uint64(poolId))
+	request = protowire.AppendTag(request, 2, protowire.VarintType)
+	request = protowire.AppendVarint(request, uint64(bundleId))
+
+	response, err := c.invoke(grpcMethodFinalizedBundle, request)
+	if err != nil {
+		return nil, err
+	}
+
+	finalizedBundle, err := decodeFinalizedBundle(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode finalized bundle: %w", err)
+	}
+
+	return finalizedBundle, nil
+}
+
+func (c *GrpcClient) GetFinalizedBundles(poolId int64, offset int64, limit int) (*types.FinalizedBundlesResponse, error) {
+	// PageRequest: offset = 2, limit = 3
+	pagination := protowire.AppendTag(nil, 2, protowire.VarintType)
+	pagination = protowire.AppendVarint(pagination, uint64(offset))
+	pagination = protowire.AppendTag(pagination, 3, protowire.VarintType)
+	pagination = protowire.AppendVarint(pagination, uint64(limit))
+
+	// QueryFinalizedBundlesRequest: pagination = 1, pool_id = 2
+	request := protowire.AppendTag(nil, 1, protowire.BytesType)
+	request = protowire.AppendBytes(request, pagination)
+	request = protowire.AppendTag(request, 2, protowire.VarintType)
+	request = protowire.AppendVarint(request, uint64(poolId))
+
+	response, err := c.invoke(grpcMethodFinalizedBundles, request)
+	if err != nil {
+		return nil, err
+	}
+
+	finalizedBundles := types.FinalizedBundlesResponse{FinalizedBundles: []types.FinalizedBundle{}}
+	// QueryFinalizedBundlesResponse: finalized_bundles = 1, pagination = 2
+	err = consumeFields(response, func(num protowire.Number, _ uint64, value []byte) error {
+		switch num {
+		case 1:
+			finalizedBundle, err := decodeFinalizedBundle(value)
+			if err != nil {
+				return err
+			}
+			finalizedBundles.FinalizedBundles = append(finalizedBundles.FinalizedBundles, *finalizedBundle)
+		case 2:
+			// PageResponse: next_key = 1
+			return consumeFields(value, func(num protowire.Number, _ uint64, value []byte) error {
+				if num == 1 {
+					finalizedBundles.Pagination.NextKey = append([]byte{}, value...)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode finalized bundles: %w", err)
+	}
+
+	return &finalizedBundles, nil
+}
+
+// decodePool decodes the fields of kyve.pool.v1beta1.Pool that are part of the pool response
+func decodePool(b []byte, poolResponse *types.PoolResponse) error {
+	data := &poolResponse.Pool.Data
+	return consumeFields(b, func(num protowire.Number, varint uint64, value []byte) error {
+		switch num {
+		case 3:
+			data.Runtime = string(value)
+		case 5:
+			data.Config = string(value)
+		case 6:
+			data.StartKey = string(value)
+		case 7:
+			data.CurrentKey = string(value)
+		case 10:
+			data.TotalBundles = int64(varint)
+		}
+		return nil
+	})
+}
+
+// decodeFinalizedBundle decodes a kyve.query.v1beta1.FinalizedBundle
+func decodeFinalizedBundle(b []byte) (*types.FinalizedBundle, error) {
+	var finalizedBundle types.FinalizedBundle
+	err := consumeFields(b, func(num protowire.Number, varint uint64, value []byte) error {
+		switch num {
+		case 2:
+			finalizedBundle.Id = strconv.FormatUint(varint, 10)
+		case 3:
+			finalizedBundle.StorageId = string(value)
+		case 7:
+			finalizedBundle.ToKey = string(value)
+		case 8:
+			finalizedBundle.BundleSummary = string(value)
+		case 9:
+			finalizedBundle.DataHash = string(value)
+		case 11:
+			finalizedBundle.FromKey = string(value)
+		case 12:
+			finalizedBundle.StorageProviderId = strconv.FormatUint(varint, 10)
+		case 13:
+			finalizedBundle.CompressionId = strconv.FormatUint(varint, 10)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &finalizedBundle, nil
+}
+
+// consumeFields calls `field` for every field of an encoded protobuf message.
+// Depending on the wire type either `varint` or `value` is set, fields of other wire types are skipped.
+func consumeFields(b []byte, field func(num protowire.Number, varint uint64, value []byte) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var varint uint64
+		var value []byte
+		switch typ {
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if typ != protowire.VarintType && typ != protowire.BytesType {
+			continue
+		}
+		if err := field(num, varint, value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+	tmjson "github.com/tendermint/tendermint/libs/json"
+)
+
+// RestClient queries the REST API of a KYVE chain, the endpoints are tried in order until one succeeds
+type RestClient struct {
+	endpoints []string
+}
+
+func NewRestClient(endpoints []string) *RestClient {
+	return &RestClient{endpoints: endpoints}
+}
+
+// get fetches the path from the first endpoint that responds successfully
+func (c *RestClient) get(path string) ([]byte, error) {
+	err := fmt.Errorf("no rest endpoints configured")
+	for _, endpoint := range c.endpoints {
+		var data []byte
+		data, err = utils.GetFromUrlWithBackoff(endpoint + path)
+		if err == nil {
+			return data, nil
+		}
+	}
+
+	return nil, fmt.Errorf("failed to fetch %v: %w", path, err)
+}
+
+func (c *RestClient) GetPoolInfo(poolId int64) (*types.PoolResponse, error) {
+	data, err := c.get(fmt.Sprintf("/kyve/query/v1beta1/pool/%d", poolId))
+	if err != nil {
+		return nil, err
+	}
+
+	// the chain encodes 64 bit integers as strings
+	var poolResponse types.PoolResponse
+	if err := tmjson.Unmarshal(data, &poolResponse); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pool response: %w", err)
+	}
+
+	return &poolResponse, nil
+}
+
+func (c *RestClient) GetFinalizedBundle(poolId int64, bundleId int64) (*types.FinalizedBundle, error) {
+	data, err := c.get(fmt.Sprintf("/kyve/v1/bundles/%d/%d", poolId, bundleId))
+	if err != nil {
+		return nil, err
+	}
+
+	var finalizedBundle types.FinalizedBundle
+	if err := json.Unmarshal(data, &finalizedBundle); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal finalized bundle: %w", err)
+	}
+
+	return &finalizedBundle, nil
+}
+
+func (c *RestClient) GetFinalizedBundles(poolId int64, offset int64, limit int) (*types.FinalizedBundlesResponse, error) {
+	data, err := c.get(fmt.Sprintf("/kyve/v1/bundles/%d?pagination.offset=%d&pagination.limit=%d", poolId, offset, limit))
+	if err != nil {
+		return nil, err
+	}
+
+	var finalizedBundles types.FinalizedBundlesResponse
+	if err := json.Unmarshal(data, &finalizedBundles); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal finalized bundles: %w", err)
+	}
+
+	return &finalizedBundles, nil
+}
@@ -1,15 +1,20 @@
 package config
 
 import (
+	"bytes"
 	_ "embed"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"sync"
 
 	"github.com/KYVENetwork/trustless-api/db"
 	"github.com/KYVENetwork/trustless-api/db/adapters"
 	"github.com/KYVENetwork/trustless-api/files"
 	"github.com/KYVENetwork/trustless-api/indexer"
+	"github.com/KYVENetwork/trustless-api/indexer/helper"
 	"github.com/KYVENetwork/trustless-api/utils"
 	"github.com/rs/zerolog"
 	"github.com/spf13/viper"
@@ -22,15 +27,29 @@ type PoolsConfig struct {
 	PoolId        int64
 	Slug          string
 	ExcludeProof  bool `json:"excludeProof"`
+	// PrecomputeTransactions stores every transaction of an EVM pool with its proof in its own data item
+	PrecomputeTransactions bool `json:"precomputeTransactions"`
 }
 
 type ConfigEndpoints struct {
 	Storage map[int][]string
 	Chains  map[string][]string
+	// Grpc are the gRPC endpoints of the chains, if set for a chain they are used instead of the REST endpoints
+	Grpc map[string][]string
 }
 
 var (
 	logger    = utils.TrustlessApiLogger("Config")
+	logLevels = map[string]zerolog.Level{
+		"info":    zerolog.InfoLevel,
+		"warning": zerolog.WarnLevel,
+		"debug":   zerolog.DebugLevel,
+		"error":   zerolog.ErrorLevel,
+		"none":    zerolog.Disabled,
+	}
+
+	indexerNames = []string{"EthBlobs", "Height", "Celestia", "Tendermint", "EVM"}
+
 	Endpoints = ConfigEndpoints{
 		Storage: map[int][]string{
 			1: {utils.RestEndpointArweave},
@@ -49,6 +68,15 @@ var (
 //go:embed config.template.yml
 var DefaultTemplate []byte
 
+// ErrInvalidConfig is returned if a reloaded config file can't be read or is invalid
+var ErrInvalidConfig = errors.New("invalid config")
+
+var (
+	configMutex sync.Mutex
+	// currentConfig is the content of the config file that was read last, it is restored if a reloaded config file is invalid
+	currentConfig []byte
+)
+
 func LoadDefaults() {
 	// log level
 	viper.SetDefault("log", "info")
@@ -57,6 +85,10 @@ func LoadDefaults() {
 	viper.SetDefault("RAM", uint64(1024))
 
 	viper.SetDefault("crawler.threads", 4)
+	viper.SetDefault("crawler.health-port", 4243)
+
+	// health
+	viper.SetDefault("health.max-bundle-lag", 10)
 
 	// prometheus
 	viper.SetDefault("prometheus.enabled", false)
@@ -84,42 +116,89 @@ func LoadDefaults() {
 
 	// server
 	viper.SetDefault("server.port", 4242)
+	viper.SetDefault("server.max-batch-size", 100)
+	viper.SetDefault("server.max-range-size", 100)
+	viper.SetDefault("server.admin-token", "")
 
 	var pools []PoolsConfig
 	viper.SetDefault("pools", pools)
 
+	// registry
+	viper.SetDefault("registry.enabled", false)
+	viper.SetDefault("registry.source", utils.DefaultRegistryURL)
+	viper.SetDefault("registry.chainid", utils.DefaultChainId)
+	viper.SetDefault("registry.interval", utils.DefaultRegistryInterval)
+
 	viper.SetDefault("endpoints", Endpoints)
 }
 
-func LoadConfig(configPath string) {
-	viper.AutomaticEnv()
-	LoadDefaults()
-	viper.SetConfigName("config")
-	viper.SetConfigType("yml")
-	viper.SetConfigFile(configPath)
+// InitConfig creates the config file from the template, an existing config file is never overwritten
+func InitConfig(configPath string) error {
+	// first get the config directory and create it if it doesnt exit yet
+	if err := os.MkdirAll(filepath.Dir(configPath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	file, err := os.OpenFile(configPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to create config file: %w", err)
+	}
+	defer file.Close()
+
+	// finally write the embedded template config
+	if _, err := file.Write(DefaultTemplate); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
 
-	// if the config file does not exist yet
+// LoadConfig loads the config file, the process exits if the config file does not exist, see InitConfig.
+// The config is validated and the process exits with all problems of the config if it is invalid.
+func LoadConfig(configPath string) {
 	if _, err := os.Stat(configPath); err != nil {
-		logger.Info().Str("path", configPath).Msg("no config found! will create one with default values.")
+		logger.Fatal().Err(err).Str("path", configPath).Msg("no config found, create one with `trustless-api config init`")
+	}
 
-		// first get the config directory and create it if it doesnt exit yet
-		dirPath := filepath.Dir(configPath)
-		os.MkdirAll(dirPath, os.ModePerm)
+	if err := ReadConfig(configPath); err != nil {
+		logger.Fatal().Err(err).Msg("failed to load config.")
+	}
 
-		// finally write the embedded template config
-		fo, err := os.Create(configPath)
-		if err != nil {
-			logger.Fatal().Err(err).Msg("Failed to create config file")
-			return
+	if errs := validate(); len(errs) > 0 {
+		for _, err := range errs {
+			logger.Error().Msg(err.Error())
 		}
+		logger.Fatal().Str("path", configPath).Int("problems", len(errs)).Msg("invalid config, run `trustless-api config validate` to list all problems")
+	}
+
+	setLogLevel()
 
-		fo.Write(DefaultTemplate)
+	if viper.GetBool("prometheus.enabled") {
+		utils.StartPrometheus(viper.GetString("prometheus.port"))
 	}
 
-	err := viper.ReadInConfig()
+	// setting memory limit
+
+	max_ram := viper.GetInt64("RAM")
+	debug.SetMemoryLimit(max_ram * 1024 * 1024)
+
+}
+
+// ReadConfig reads the config file together with the defaults and the environment variables, the config is not validated.
+func ReadConfig(configPath string) error {
+	viper.AutomaticEnv()
+	LoadDefaults()
+	viper.SetConfigName("config")
+	viper.SetConfigType("yml")
+	viper.SetConfigFile(configPath)
+
+	content, err := os.ReadFile(configPath)
 	if err != nil {
-		logger.Fatal().Err(err).Msg("failed to load config.")
+		return err
 	}
+	if err := viper.ReadConfig(bytes.NewReader(content)); err != nil {
+		return err
+	}
+	currentConfig = content
 
 	_ = viper.BindEnv("RAM", "RAM")
 
@@ -132,6 +211,9 @@ func LoadConfig(configPath string) {
 	_ = viper.BindEnv("server.port", "PORT")
 
 	_ = viper.BindEnv("crawler.threads", "CRAWLER_THREADS")
+	_ = viper.BindEnv("crawler.health-port", "CRAWLER_HEALTH_PORT")
+
+	_ = viper.BindEnv("health.max-bundle-lag", "MAX_BUNDLE_LAG")
 
 	_ = viper.BindEnv("storage.aws-endpoint", "AWS_ENDPOINT")
 	_ = viper.BindEnv("storage.bucketname", "BUCKET_NAME")
@@ -144,101 +226,159 @@ func LoadConfig(configPath string) {
 	_ = viper.BindEnv("prometheus.port", "PROMETHEUS_PORT")
 
 	loadEndpoints()
-	setLogLevel()
 
-	if viper.GetBool("prometheus.enabled") {
-		utils.StartPrometheus(viper.GetString("prometheus.port"))
+	return nil
+}
+
+// ReloadConfig reads the config file again and validates the entire config. If the config file can't be read or
+// the config is invalid, the config that was read before is restored and the problems are returned wrapped in ErrInvalidConfig.
+// Without a config file only the current config is validated.
+func ReloadConfig() error {
+	configMutex.Lock()
+	defer configMutex.Unlock()
+
+	configPath := viper.ConfigFileUsed()
+	if configPath == "" {
+		if err := Validate(); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+		}
+		return nil
 	}
 
-	// setting memory limit
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
 
-	max_ram := viper.GetInt64("RAM")
-	debug.SetMemoryLimit(max_ram * 1024 * 1024)
+	err = viper.ReadConfig(bytes.NewReader(content))
+	if err == nil {
+		err = Validate()
+	}
+	if err != nil {
+		if currentConfig != nil {
+			if restoreErr := viper.ReadConfig(bytes.NewReader(currentConfig)); restoreErr != nil {
+				logger.Error().Err(restoreErr).Msg("failed to restore the previous config")
+			}
+		}
+		return fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+	}
 
+	currentConfig = content
+	loadEndpoints()
+	setLogLevel()
+
+	return nil
 }
 
-func setLogLevel() {
-	switch viper.GetString("log") {
-	case "info":
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
-	case "warning":
-		zerolog.SetGlobalLevel(zerolog.WarnLevel)
-	case "debug":
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-	case "error":
-		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
-	case "none":
-		zerolog.SetGlobalLevel(zerolog.Disabled)
+func loadEndpoints() {
+	// invalid endpoints are reported by the validation, the default endpoints are kept
+	var endpoints ConfigEndpoints
+	if err := viper.UnmarshalKey("endpoints", &endpoints); err == nil {
+		Endpoints = endpoints
 	}
 }
 
-func loadEndpoints() {
-	var config ConfigEndpoints
-	err := viper.UnmarshalKey("endpoints", &config)
-	if err != nil {
-		logger.Fatal().Msg("Failed to parse endpoints")
-		return
+func setLogLevel() {
+	if level, ok := logLevels[viper.GetString("log")]; ok {
+		zerolog.SetGlobalLevel(level)
 	}
-	Endpoints = config
 }
 
 // GetSaveDataItemAdapter returns the SaveDataItem interface that is configured in the config file
-func GetSaveDataItemAdapter() files.SaveDataItem {
-	switch viper.GetString("storage.type") {
+func GetSaveDataItemAdapter() (files.SaveDataItem, error) {
+	switch storageType := viper.GetString("storage.type"); storageType {
 	case "local":
-		return &files.LocalFileAdapter
+		return &files.LocalFileAdapter, nil
 	case "s3":
-		return &files.S3FileAdapter
+		return &files.S3FileAdapter, nil
+	default:
+		return nil, unknownStorageType(storageType)
 	}
-
-	logger.Fatal().Str("type", viper.GetString("storage.type")).Msg("Unknown storage type")
-	return nil
 }
 
 func GetPoolsConfig() []PoolsConfig {
-	var config []PoolsConfig
-	err := viper.UnmarshalKey("pools", &config)
-	if err != nil || len(config) == 0 {
-		logger.Fatal().Msg("Failed to parse pools")
+	config, err := ParsePoolsConfig()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to parse pools")
 	}
 	return config
 }
 
+// ParsePoolsConfig parses and validates the pools of the config, all problems of the pools are returned at once.
+// Without pools there is nothing to do, unless pools are discovered from the registry.
+func ParsePoolsConfig() ([]PoolsConfig, error) {
+	var config []PoolsConfig
+	if err := viper.UnmarshalKey("pools", &config); err != nil {
+		return nil, fieldError("pools", "failed to parse pools: %v", err)
+	}
+
+	if err := errors.Join(validatePools(config)...); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// GetIndexer returns the indexer with the given name
+func GetIndexer(name string) (indexer.Indexer, error) {
+	switch name {
+	case "EthBlobs":
+		return &indexer.EthBlobIndexer, nil
+	case "Height":
+		return &indexer.HeightIndexer, nil
+	case "Celestia":
+		return &indexer.CelestiaIndexer, nil
+	case "Tendermint":
+		return &indexer.TendermintIndexer, nil
+	case "EVM":
+		return &indexer.EVMIndexer, nil
+	}
+	return nil, fmt.Errorf("unknown indexer %v", name)
+}
+
+// GetIndexer returns the indexer of the pool with the indexing options of the pool applied
+func (c PoolsConfig) GetIndexer() (indexer.Indexer, error) {
+	idx, err := GetIndexer(c.Indexer)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.PrecomputeTransactions {
+		if _, ok := idx.(*helper.EVMIndexer); !ok {
+			return nil, fmt.Errorf("indexer %v does not support precomputeTransactions", c.Indexer)
+		}
+		return &helper.EVMIndexer{PrecomputeTransactions: true}, nil
+	}
+
+	return idx, nil
+}
+
 // GetDatabaseAdapter returns the correct db.Adapter that is configured in the config file
-func GetDatabaseAdapter(saveDataItem files.SaveDataItem, indexer indexer.Indexer, poolId int64, chainId string) db.Adapter {
-	switch viper.GetString("database.type") {
+func GetDatabaseAdapter(saveDataItem files.SaveDataItem, indexer indexer.Indexer, poolId int64, chainId string) (db.Adapter, error) {
+	switch databaseType := viper.GetString("database.type"); databaseType {
 	case "sqlite":
 		adapter := adapters.GetSQLite(saveDataItem, indexer, poolId, chainId)
-		return &adapter
+		return &adapter, nil
 	case "postgres":
 		adapter := adapters.GetPostgres(saveDataItem, indexer, poolId, chainId)
-		return &adapter
+		return &adapter, nil
+	default:
+		return nil, unknownDatabaseType(databaseType)
 	}
-	logger.Fatal().Str("type", viper.GetString("database.type")).Msg("Unkown database type")
-	return nil
 }
 
 // GetDatabaseAdapter returns the db.Adapter for each pool config
 // as each pool has its own adapter
-func (c PoolsConfig) GetDatabaseAdapter() db.Adapter {
-	var saveFile files.SaveDataItem = GetSaveDataItemAdapter()
-	var idx indexer.Indexer
-	switch c.Indexer {
-	case "EthBlobs":
-		idx = &indexer.EthBlobIndexer
-	case "Height":
-		idx = &indexer.HeightIndexer
-	case "Celestia":
-		idx = &indexer.CelestiaIndexer
-	case "Tendermint":
-		idx = &indexer.TendermintIndexer
-	case "EVM":
-		idx = &indexer.EVMIndexer
-	default:
-		logger.Fatal().Str("type", c.Indexer).Msg("failed to resolve indexer")
-		return nil
+func (c PoolsConfig) GetDatabaseAdapter() (db.Adapter, error) {
+	saveFile, err := GetSaveDataItemAdapter()
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := c.GetIndexer()
+	if err != nil {
+		return nil, err
 	}
 
-	adapter := GetDatabaseAdapter(saveFile, idx, c.PoolId, c.ChainId)
-	return adapter
+	return GetDatabaseAdapter(saveFile, idx, c.PoolId, c.ChainId)
 }
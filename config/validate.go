@@ -0,0 +1,223 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/KYVENetwork/trustless-api/utils"
+	"github.com/spf13/viper"
+)
+
+// reservedSlugs are paths of the api server that can't be used as the slug of a pool
+var reservedSlugs = map[string]bool{"status": true, "healthz": true, "readyz": true, "admin": true, "openapi.yml": true}
+
+// FieldError is a problem with a single field of the config, the field is the path of the field in the config file, e. g. "pools[1].slug"
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Field, e.Message)
+}
+
+func fieldError(field string, format string, args ...any) error {
+	return &FieldError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validate checks the loaded config and returns all problems at once, each problem is a FieldError
+func Validate() error {
+	return errors.Join(validate()...)
+}
+
+func validate() []error {
+	var errs []error
+
+	if _, ok := logLevels[viper.GetString("log")]; !ok {
+		errs = append(errs, fieldError("log", "unknown log level %q, available options: %v", viper.GetString("log"), strings.Join(getLogLevels(), ", ")))
+	}
+
+	var pools []PoolsConfig
+	if err := viper.UnmarshalKey("pools", &pools); err != nil {
+		errs = append(errs, fieldError("pools", "failed to parse pools: %v", err))
+	} else {
+		errs = append(errs, validatePools(pools)...)
+	}
+
+	var endpoints ConfigEndpoints
+	if err := viper.UnmarshalKey("endpoints", &endpoints); err != nil {
+		errs = append(errs, fieldError("endpoints", "failed to parse endpoints: %v", err))
+	}
+
+	errs = append(errs, validateStorage()...)
+	errs = append(errs, validateDatabase()...)
+
+	errs = append(errs, validateRegistry()...)
+
+	for _, key := range []string{"crawler.threads", "storage.threads", "server.max-batch-size", "server.max-range-size", "registry.interval"} {
+		if viper.GetInt(key) <= 0 {
+			errs = append(errs, fieldError(key, "has to be greater than 0"))
+		}
+	}
+
+	return errs
+}
+
+// validateRegistry checks the source and the chain of the registry if it is enabled.
+// The source is either a http(s) url or the path of a local file.
+func validateRegistry() []error {
+	if !viper.GetBool("registry.enabled") {
+		return nil
+	}
+
+	var errs []error
+	switch source := viper.GetString("registry.source"); {
+	case source == "":
+		errs = append(errs, fieldError("registry.source", "required if the registry is enabled"))
+	case strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://"):
+		if u, err := url.Parse(source); err != nil || u.Host == "" {
+			errs = append(errs, fieldError("registry.source", "invalid url %q", source))
+		}
+	case strings.Contains(source, "://"):
+		errs = append(errs, fieldError("registry.source", "unsupported url %q, only http and https urls or local files are supported", source))
+	default:
+		if info, err := os.Stat(source); err != nil || info.IsDir() {
+			errs = append(errs, fieldError("registry.source", "registry file %v doesn't exist", source))
+		}
+	}
+
+	if chainId := viper.GetString("registry.chainid"); chainId != utils.ChainIdMainnet && chainId != utils.ChainIdKaon {
+		errs = append(errs, fieldError("registry.chainid", "unsupported chain id %q, available options: %v, %v", chainId, utils.ChainIdMainnet, utils.ChainIdKaon))
+	}
+
+	return errs
+}
+
+// validatePools checks that every pool has a known indexer, a chain with endpoints and a unique slug and pool id
+func validatePools(pools []PoolsConfig) []error {
+	if len(pools) == 0 && !viper.GetBool("registry.enabled") {
+		return []error{fieldError("pools", "no pools configured, configure at least one pool or enable the registry")}
+	}
+
+	var errs []error
+	slugs := map[string]int{}
+	poolIds := map[string]int{}
+	for i, pool := range pools {
+		field := fmt.Sprintf("pools[%v]", i)
+
+		if _, err := GetIndexer(pool.Indexer); err != nil {
+			errs = append(errs, fieldError(field+".indexer", "unknown indexer %q, available options: %v", pool.Indexer, strings.Join(indexerNames, ", ")))
+		} else if _, err := pool.GetIndexer(); err != nil {
+			errs = append(errs, fieldError(field+".precomputetransactions", "only supported by the EVM indexer"))
+		}
+
+		if pool.ChainId == "" {
+			errs = append(errs, fieldError(field+".chainid", "required"))
+		} else if len(Endpoints.Chains[pool.ChainId]) == 0 && len(Endpoints.Grpc[pool.ChainId]) == 0 {
+			errs = append(errs, fieldError(field+".chainid", "no endpoints configured for chain %v in endpoints.chains", pool.ChainId))
+		}
+
+		if pool.PoolId < 0 {
+			errs = append(errs, fieldError(field+".poolid", "has to be 0 or greater"))
+		} else if other, ok := poolIds[getPoolKey(pool)]; ok {
+			errs = append(errs, fieldError(field+".poolid", "pool %v on chain %v is already configured in pools[%v]", pool.PoolId, pool.ChainId, other))
+		} else {
+			poolIds[getPoolKey(pool)] = i
+		}
+
+		if pool.BundleStartId < 0 {
+			errs = append(errs, fieldError(field+".bundlestartid", "has to be 0 or greater"))
+		}
+
+		slug := strings.Trim(pool.Slug, "/")
+		if slug == "" {
+			errs = append(errs, fieldError(field+".slug", "required"))
+		} else if strings.Contains(slug, "/") {
+			errs = append(errs, fieldError(field+".slug", "%q must not contain a slash", pool.Slug))
+		} else if reservedSlugs[slug] {
+			errs = append(errs, fieldError(field+".slug", "%q is reserved by the api server", pool.Slug))
+		} else if other, ok := slugs[slug]; ok {
+			errs = append(errs, fieldError(field+".slug", "%q is already used by pools[%v]", pool.Slug, other))
+		} else {
+			slugs[slug] = i
+		}
+	}
+
+	return errs
+}
+
+func validateStorage() []error {
+	var errs []error
+
+	switch storageType := viper.GetString("storage.type"); storageType {
+	case "local":
+		if viper.GetString("storage.path") == "" {
+			errs = append(errs, fieldError("storage.path", "required for storage type local"))
+		}
+	case "s3":
+		for _, key := range []string{"storage.aws-endpoint", "storage.bucketname", "storage.cdn", "storage.credentials.keyid", "storage.credentials.keysecret"} {
+			if viper.GetString(key) == "" {
+				errs = append(errs, fieldError(key, "required for storage type s3"))
+			}
+		}
+	default:
+		errs = append(errs, unknownStorageType(storageType))
+	}
+
+	if compression := viper.GetString("storage.compression"); compression != "gzip" && compression != "none" {
+		errs = append(errs, fieldError("storage.compression", "unknown compression %q, available options: gzip, none", compression))
+	}
+
+	return errs
+}
+
+func validateDatabase() []error {
+	var errs []error
+
+	switch databaseType := viper.GetString("database.type"); databaseType {
+	case "sqlite":
+		if viper.GetString("database.dbname") == "" {
+			errs = append(errs, fieldError("database.dbname", "required for database type sqlite"))
+		}
+	case "postgres":
+		for _, key := range []string{"database.host", "database.dbname", "database.user"} {
+			if viper.GetString(key) == "" {
+				errs = append(errs, fieldError(key, "required for database type postgres"))
+			}
+		}
+		if port := viper.GetInt("database.port"); port <= 0 || port > 65535 {
+			errs = append(errs, fieldError("database.port", "invalid port %v for database type postgres", viper.GetString("database.port")))
+		}
+	default:
+		errs = append(errs, unknownDatabaseType(databaseType))
+	}
+
+	return errs
+}
+
+// unknownStorageType is returned by the validation and by GetSaveDataItemAdapter
+func unknownStorageType(storageType string) error {
+	return fieldError("storage.type", "unknown storage type %q, available options: local, s3", storageType)
+}
+
+// unknownDatabaseType is returned by the validation and by GetDatabaseAdapter
+func unknownDatabaseType(databaseType string) error {
+	return fieldError("database.type", "unknown database type %q, available options: sqlite, postgres", databaseType)
+}
+
+func getPoolKey(pool PoolsConfig) string {
+	return fmt.Sprintf("%v/%v", pool.ChainId, pool.PoolId)
+}
+
+func getLogLevels() []string {
+	levels := make([]string, 0, len(logLevels))
+	for level := range logLevels {
+		levels = append(levels, level)
+	}
+	sort.Strings(levels)
+	return levels
+}
@@ -0,0 +1,164 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestValidate(t *testing.T) {
+	LoadDefaults()
+	defer viper.Reset()
+
+	viper.Set("pools", []PoolsConfig{
+		{ChainId: "kyve-1", PoolId: 1, Indexer: "Height", Slug: "height"},
+		{ChainId: "kyve-1", PoolId: 2, Indexer: "EVM", Slug: "evm", PrecomputeTransactions: true},
+	})
+	if err := Validate(); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+
+	viper.Set("log", "verbose")
+	viper.Set("pools", []PoolsConfig{
+		{ChainId: "kyve-1", PoolId: 1, Indexer: "Heigth", Slug: "height"},
+		{ChainId: "osmosis-1", PoolId: 2, Indexer: "EVM", Slug: "/height"},
+		{ChainId: "kyve-1", PoolId: 1, Indexer: "EVM", Slug: "status"},
+		{ChainId: "kyve-1", PoolId: 3, Indexer: "Tendermint", Slug: "tendermint", PrecomputeTransactions: true},
+	})
+	viper.Set("storage.type", "s3")
+	viper.Set("storage.bucketname", "bucket")
+	viper.Set("storage.aws-endpoint", "http://localhost:9000")
+	viper.Set("storage.cdn", "http://localhost:9000/")
+	viper.Set("database.type", "postgres")
+	viper.Set("database.host", "localhost")
+	viper.Set("database.user", "admin")
+	viper.Set("database.port", 5432)
+
+	var fields []string
+	for _, err := range validate() {
+		var fieldErr *FieldError
+		if !errors.As(err, &fieldErr) {
+			t.Fatalf("expected field error, got %v", err)
+		}
+		fields = append(fields, fieldErr.Field)
+	}
+
+	expected := []string{
+		"log",
+		"pools[0].indexer",
+		"pools[1].chainid",
+		"pools[1].slug",
+		"pools[2].poolid",
+		"pools[2].slug",
+		"pools[3].precomputetransactions",
+		"storage.credentials.keyid",
+		"storage.credentials.keysecret",
+	}
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected problems with %v, got %v", expected, fields)
+	}
+}
+
+func TestValidateRegistry(t *testing.T) {
+	LoadDefaults()
+	defer viper.Reset()
+
+	file := filepath.Join(t.TempDir(), "registry.yml")
+	if err := os.WriteFile(file, []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	viper.Set("registry.enabled", true)
+	for source, valid := range map[string]bool{
+		"https://example.com/registry.yml":   true,
+		"http://localhost:8080/registry.yml": true,
+		file:                                 true,
+		"":                                   false,
+		"https://":                           false,
+		"ftp://localhost/registry.yml":       false,
+		filepath.Join(t.TempDir(), "missing.yml"): false,
+		filepath.Dir(file):                        false,
+	} {
+		viper.Set("registry.source", source)
+		if errs := validateRegistry(); (len(errs) == 0) != valid {
+			t.Errorf("source %q: expected valid %v, got %v", source, valid, errs)
+		}
+	}
+
+	viper.Set("registry.source", file)
+	viper.Set("registry.interval", 0)
+	viper.Set("pools", []PoolsConfig{{ChainId: "kyve-1", PoolId: 1, Indexer: "Height", Slug: "height"}})
+	var fieldErr *FieldError
+	if err := Validate(); !errors.As(err, &fieldErr) || fieldErr.Field != "registry.interval" {
+		t.Errorf("expected error for registry.interval, got %v", err)
+	}
+}
+
+func TestInitConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "trustless-api", "config.yml")
+	if err := InitConfig(configPath); err != nil {
+		t.Fatal(err)
+	}
+	if data, err := os.ReadFile(configPath); err != nil || !bytes.Equal(data, DefaultTemplate) {
+		t.Errorf("expected the template config, got error %v", err)
+	}
+
+	// an existing config is never overwritten
+	if err := os.WriteFile(configPath, []byte("log: error\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := InitConfig(configPath); err == nil {
+		t.Error("expected error for an existing config")
+	}
+	if data, _ := os.ReadFile(configPath); string(data) != "log: error\n" {
+		t.Errorf("expected the existing config to be kept, got %s", data)
+	}
+}
+
+func TestParsePoolsConfig(t *testing.T) {
+	LoadDefaults()
+	defer viper.Reset()
+
+	if _, err := ParsePoolsConfig(); err == nil {
+		t.Error("expected error without pools")
+	}
+
+	viper.Set("registry.enabled", true)
+	if pools, err := ParsePoolsConfig(); err != nil || len(pools) != 0 {
+		t.Errorf("expected no pools without error if the registry is enabled, got %v, %v", pools, err)
+	}
+
+	viper.Set("pools", []PoolsConfig{{ChainId: "kyve-1", PoolId: 1, Indexer: "Height", Slug: "height"}, {ChainId: "kyve-1", PoolId: 2, Indexer: "EVM", Slug: "height"}})
+	if _, err := ParsePoolsConfig(); err == nil || err.Error() != `pools[1].slug: "height" is already used by pools[0]` {
+		t.Errorf("expected duplicate slug error, got %v", err)
+	}
+}
+
+func TestGetAdapterOfUnknownType(t *testing.T) {
+	LoadDefaults()
+	defer viper.Reset()
+
+	viper.Set("storage.type", "ftp")
+	viper.Set("database.type", "mysql")
+
+	var fieldErr *FieldError
+	if _, err := GetSaveDataItemAdapter(); !errors.As(err, &fieldErr) || fieldErr.Field != "storage.type" {
+		t.Errorf("expected error for storage.type, got %v", err)
+	}
+	if _, err := GetDatabaseAdapter(nil, nil, 1, "kyve-1"); !errors.As(err, &fieldErr) || fieldErr.Field != "database.type" {
+		t.Errorf("expected error for database.type, got %v", err)
+	}
+
+	// the validation reports the same problems
+	for _, err := range []error{unknownStorageType("ftp"), unknownDatabaseType("mysql")} {
+		if !strings.Contains(Validate().Error(), err.Error()) {
+			t.Errorf("expected validation to report %v", err)
+		}
+	}
+}
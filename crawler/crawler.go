@@ -2,14 +2,20 @@ package crawler
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
+	"slices"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/KYVENetwork/trustless-api/collectors/bundles"
-	"github.com/KYVENetwork/trustless-api/collectors/pool"
+	"github.com/KYVENetwork/trustless-api/collectors/chain"
 	"github.com/KYVENetwork/trustless-api/config"
 	"github.com/KYVENetwork/trustless-api/db"
+	"github.com/KYVENetwork/trustless-api/merkle"
+	"github.com/KYVENetwork/trustless-api/registry"
+	"github.com/KYVENetwork/trustless-api/status"
 	"github.com/KYVENetwork/trustless-api/types"
 	"github.com/KYVENetwork/trustless-api/utils"
 	"github.com/go-co-op/gocron"
@@ -25,32 +31,42 @@ var (
 // Crawler is a master of mulitple child crawler
 // One child crawler is responsible for crawling a specifc pool
 type Crawler struct {
-	children []*ChildCrawler
+	mutex     sync.Mutex
+	children  map[string]*ChildCrawler // by chain id and pool id
+	pools     []status.Pool
+	semaphore *semaphore.Weighted
+	started   bool
+	// maxBundleLag is the `health.max-bundle-lag` of the readiness probe, it is read whenever the pools are set
+	maxBundleLag int64
 }
 
 type ChildCrawler struct {
 	adapter       db.Adapter
 	bundleStartId int64
 	chainId       string
+	client        chain.ChainClient
 	crawling      sync.Mutex
 	poolId        int64
 	semaphore     *semaphore.Weighted
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	// done is closed when a started crawler is stopped and its last crawl is finished
+	done chan struct{}
+
+	// finalizedBundles caches the metadata of the bundles that still have to be inserted or tracked.
+	// Finalized bundles never change, so the metadata is only fetched once even if inserting a bundle fails.
+	finalizedBundles map[int64]*types.FinalizedBundle
 }
 
 // This is a helper function that will be called from multiple go routines.
 //
 // Downloads the bundle, creates the inclusion proof
 // and inserts the bundle into the database.
-func (crawler *ChildCrawler) insertBundleDataItems(bundleId int64) error {
+func (crawler *ChildCrawler) insertBundleDataItems(bundleId int64, compressedBundle *types.FinalizedBundle) error {
 	start := time.Now()
 	total := time.Now()
 
-	compressedBundle, err := bundles.GetFinalizedBundle(crawler.chainId, crawler.poolId, bundleId)
-	if err != nil {
-		logger.Error().Int64("poolId", crawler.poolId).Int64("bundleId", bundleId).Msg("Something went wrong when retrieving the bundle...")
-		return err
-	}
-
 	dataItems, err := bundles.GetDecompressedBundle(*compressedBundle, crawler.labels())
 
 	if err != nil {
@@ -62,14 +78,36 @@ func (crawler *ChildCrawler) insertBundleDataItems(bundleId int64) error {
 	logger.Debug().Int64("poolId", crawler.poolId).Int64("bundleId", bundleId).Msg(fmt.Sprintf("Downloading bundle took: %v", elapsed))
 
 	bundle := types.Bundle{
-		DataItems: dataItems,
-		PoolId:    crawler.poolId,
-		BundleId:  bundleId,
-		ChainId:   crawler.chainId,
+		DataItems:       dataItems,
+		PoolId:          crawler.poolId,
+		BundleId:        bundleId,
+		ChainId:         crawler.chainId,
+		FinalizedBundle: compressedBundle,
 	}
 	start = time.Now()
 
-	err = crawler.adapter.Save(&bundle)
+	trustlessItems, leafs, err := crawler.adapter.GetIndexer().IndexBundle(&bundle)
+	if err != nil {
+		logger.Error().Int64("poolId", crawler.poolId).Int64("bundleId", bundleId).Msg("Something went wrong when indexing the bundle...")
+		return err
+	}
+
+	elapsed = time.Since(start)
+	logger.Debug().Int64("poolId", crawler.poolId).Int64("bundleId", bundleId).Msg(fmt.Sprintf("Indexing %v data items took: %v", len(*trustlessItems), elapsed))
+
+	merkleRoot := merkle.GetMerkleRoot(*leafs)
+	bundle.MerkleRoot = hex.EncodeToString(merkleRoot[:])
+	bundle.Leafs = *leafs
+
+	// never serve proofs that don't fold up to the merkle root the pool has committed to
+	if err := verifyMerkleRoot(compressedBundle, bundle.MerkleRoot); err != nil {
+		logger.Error().Err(err).Int64("poolId", crawler.poolId).Int64("bundleId", bundleId).Msg("Refusing to insert bundle, merkle root does not match")
+		return err
+	}
+
+	start = time.Now()
+
+	err = crawler.adapter.Save(&bundle, trustlessItems)
 	if err != nil {
 		logger.Error().Int64("poolId", crawler.poolId).Int64("bundleId", bundleId).Msg("Something went wrong when inserting the bundle...")
 		return err
@@ -84,6 +122,82 @@ func (crawler *ChildCrawler) insertBundleDataItems(bundleId int64) error {
 	return nil
 }
 
+// verifyMerkleRoot compares the hex encoded merkle root computed from the leafs of the indexer
+// with the merkle root stored in the bundle summary of the finalized bundle.
+//
+// Bundles without a merkle root in their summary can't be verified, in that case only a warning is logged.
+func verifyMerkleRoot(finalizedBundle *types.FinalizedBundle, computedRoot string) error {
+	summaryRoot, err := bundles.GetSummaryMerkleRoot(finalizedBundle)
+	if err != nil {
+		logger.Warn().Err(err).Str("bundleId", finalizedBundle.Id).Msg("Skipping merkle root verification")
+		return nil
+	}
+
+	if computedRoot != summaryRoot {
+		return fmt.Errorf("merkle root mismatch on bundle %v: expected = %v computed = %v", finalizedBundle.Id, summaryRoot, computedRoot)
+	}
+
+	return nil
+}
+
+// fetchFinalizedBundles makes sure the metadata of all given bundles is cached.
+// Only the metadata of the given bundles is kept, the bundles that are not cached yet are fetched
+// with the paginated list endpoint in pages of `utils.BundlesPageLimit` bundles.
+func (crawler *ChildCrawler) fetchFinalizedBundles(bundleIds []int64) error {
+	cached := crawler.finalizedBundles
+	crawler.finalizedBundles = make(map[int64]*types.FinalizedBundle, len(bundleIds))
+
+	needed := make(map[int64]bool, len(bundleIds))
+	var uncached []int64
+	for _, bundleId := range bundleIds {
+		needed[bundleId] = true
+		if finalizedBundle, ok := cached[bundleId]; ok {
+			crawler.finalizedBundles[bundleId] = finalizedBundle
+		} else {
+			uncached = append(uncached, bundleId)
+		}
+	}
+	slices.Sort(uncached)
+
+	start := time.Now()
+	pages := 0
+	for i := 0; i < len(uncached); {
+		offset := uncached[i]
+		response, err := crawler.client.GetFinalizedBundles(crawler.poolId, offset, utils.BundlesPageLimit)
+		if err != nil {
+			return fmt.Errorf("failed to fetch finalized bundles from bundle %v: %w", offset, err)
+		}
+		pages++
+
+		var to int64
+		for index := range response.FinalizedBundles {
+			finalizedBundle := &response.FinalizedBundles[index]
+			bundleId, err := strconv.ParseInt(finalizedBundle.Id, 10, 64)
+			if err != nil {
+				return fmt.Errorf("failed to parse id of finalized bundle: %w", err)
+			}
+			if needed[bundleId] {
+				crawler.finalizedBundles[bundleId] = finalizedBundle
+			}
+			to = max(to, bundleId+1)
+		}
+
+		if _, ok := crawler.finalizedBundles[offset]; !ok {
+			return fmt.Errorf("finalized bundle %v not found", offset)
+		}
+		// continue with the first bundle that is not part of the page
+		for i < len(uncached) && uncached[i] < to {
+			i++
+		}
+	}
+
+	if pages > 0 {
+		logger.Debug().Int64("poolId", crawler.poolId).Msg(fmt.Sprintf("Fetching %v finalized bundles in %v pages took: %v", len(uncached), pages, time.Since(start)))
+	}
+
+	return nil
+}
+
 func (crawler *ChildCrawler) labels() []string {
 	return []string{fmt.Sprintf("%v", crawler.poolId), crawler.chainId}
 }
@@ -108,7 +222,7 @@ func (crawler *ChildCrawler) CrawlBundles() {
 	// because we want to stop the crawling processes as soon as one request fails and start over again
 	group, ctx := errgroup.WithContext(context.Background())
 
-	poolInfo, err := pool.GetPoolInfo(crawler.chainId, crawler.poolId)
+	poolInfo, err := crawler.client.GetPoolInfo(crawler.poolId)
 	if err != nil {
 		logger.Error().Err(err).Msg("Failed to get latest bundle")
 		return
@@ -117,6 +231,39 @@ func (crawler *ChildCrawler) CrawlBundles() {
 	lastBundle := poolInfo.Pool.Data.TotalBundles - 1
 	missingBundles := crawler.adapter.GetMissingBundles(crawler.bundleStartId, lastBundle)
 
+	untrackedBundles, err := crawler.adapter.GetUntrackedBundles()
+	if err != nil {
+		logger.Error().Err(err).Int64("poolId", crawler.poolId).Msg("Failed to get untracked bundles")
+		return
+	}
+
+	// fetch the metadata of all bundles in bulk before the bundles are downloaded concurrently
+	if err := crawler.fetchFinalizedBundles(append(slices.Clone(untrackedBundles), missingBundles...)); err != nil {
+		logger.Error().Err(err).Int64("poolId", crawler.poolId).Msg("Failed to fetch finalized bundles")
+		return
+	}
+
+	for _, i := range untrackedBundles {
+		if err := crawler.semaphore.Acquire(ctx, 1); err != nil {
+			break
+		}
+
+		localIndex := i
+		finalizedBundle := crawler.finalizedBundles[i]
+		group.Go(func() error {
+			defer crawler.semaphore.Release(1)
+			// the metadata is taken from the finalized bundle, so the bundle doesn't have to be downloaded again
+			err := crawler.adapter.TrackBundle(localIndex, finalizedBundle)
+			if err != nil {
+				logger.Error().Err(err).
+					Int64("poolId", crawler.poolId).
+					Int64("bundleId", localIndex).
+					Msg("Failed to track bundle")
+			}
+			return err
+		})
+	}
+
 	for _, i := range missingBundles {
 		if err := crawler.semaphore.Acquire(ctx, 1); err != nil {
 			break
@@ -124,9 +271,10 @@ func (crawler *ChildCrawler) CrawlBundles() {
 
 		logger.Info().Int64("poolId", crawler.poolId).Int64("bundle-id", i).Msg(fmt.Sprintf("Inserting data items: %v/%v", i+1-crawler.bundleStartId, lastBundle+1-crawler.bundleStartId))
 		localIndex := i
+		finalizedBundle := crawler.finalizedBundles[i]
 		group.Go(func() error {
 			defer crawler.semaphore.Release(1)
-			err := crawler.insertBundleDataItems(localIndex)
+			err := crawler.insertBundleDataItems(localIndex, finalizedBundle)
 			if err != nil {
 				logger.Error().Err(err).
 					Int64("poolId", crawler.poolId).
@@ -152,15 +300,44 @@ func (crawler *ChildCrawler) CrawlBundles() {
 
 // Start starts the crawling processes and
 // creates a scheduler that invokes CrawlBundles.
-// NOTE: This function is blocking.
+// NOTE: This function is blocking until the crawler is stopped and its last crawl is finished.
 func (crawler *ChildCrawler) Start() {
+	defer close(crawler.done)
+
+	// the crawler was stopped before it was started, e.g. because its pool was changed again
+	select {
+	case <-crawler.stop:
+		return
+	default:
+	}
+
 	scheduler := gocron.NewScheduler(time.UTC)
 	scheduler.Every(30).Seconds().Do(crawler.CrawlBundles)
-	scheduler.StartBlocking()
+	scheduler.StartAsync()
+
+	<-crawler.stop
+	scheduler.Stop()
+
+	// wait for a crawl that is in progress
+	crawler.crawling.Lock()
+	crawler.crawling.Unlock()
+}
+
+// Stop stops the scheduler of the crawler, a crawl that is in progress is finished first, see Wait.
+func (crawler *ChildCrawler) Stop() {
+	crawler.stopOnce.Do(func() {
+		close(crawler.stop)
+	})
+}
+
+// Wait blocks until a started crawler is stopped and its last crawl is finished
+func (crawler *ChildCrawler) Wait() {
+	<-crawler.done
 }
 
 func CreateBundleCrawler(
 	adapter db.Adapter,
+	client chain.ChainClient,
 	chainId string,
 	poolId int64,
 	bundleStartId int64,
@@ -170,39 +347,163 @@ func CreateBundleCrawler(
 		adapter:       adapter,
 		bundleStartId: bundleStartId,
 		chainId:       chainId,
+		client:        client,
 		poolId:        poolId,
 		semaphore:     semaphore,
+		stop:          make(chan struct{}),
+		done:          make(chan struct{}),
 	}
 }
 
-// Create creates a crawler based on the config file.
-func Create() Crawler {
-	var bundleCrawler []*ChildCrawler
+// Create creates a crawler based on the config file and the pools of the registry.
+func Create() *Crawler {
+	crawler := &Crawler{
+		children:  map[string]*ChildCrawler{},
+		semaphore: semaphore.NewWeighted(viper.GetInt64("crawler.threads")),
+	}
+	crawler.setPools(registry.GetPoolsConfig())
 
-	semaphore := semaphore.NewWeighted(viper.GetInt64("crawler.threads"))
+	return crawler
+}
 
-	for _, bc := range config.GetPoolsConfig() {
-		adapter := bc.GetDatabaseAdapter()
-		newCrawler := CreateBundleCrawler(adapter, bc.ChainId, bc.PoolId, bc.BundleStartId, semaphore)
-		bundleCrawler = append(bundleCrawler, &newCrawler)
+// setPools creates a child crawler for every pool that is not crawled yet and stops the child crawlers of removed pools.
+// If the indexer, its options or the bundle start id of a pool changed, its child crawler is replaced.
+// If the crawler is already started, the new child crawlers are started as well.
+// The database adapter of a stopped child crawler is closed once its last crawl is finished, only then its replacement is started,
+// so two child crawlers never write the same pool at the same time.
+func (c *Crawler) setPools(pools []config.PoolsConfig) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	previous := map[string]status.Pool{}
+	for _, pool := range c.pools {
+		previous[getPoolKey(pool.Config)] = pool
 	}
 
-	return Crawler{
-		children: bundleCrawler,
+	children := map[string]*ChildCrawler{}
+	var started []*ChildCrawler
+	c.pools = nil
+	for _, bc := range pools {
+		key := getPoolKey(bc)
+		if _, ok := children[key]; ok {
+			logger.Warn().Str("chainId", bc.ChainId).Int64("poolId", bc.PoolId).Msg("Skipping duplicate pool")
+			continue
+		}
+
+		if pool, ok := previous[key]; ok && pool.Config.Indexer == bc.Indexer && pool.Config.BundleStartId == bc.BundleStartId &&
+			pool.Config.PrecomputeTransactions == bc.PrecomputeTransactions {
+			children[key] = c.children[key]
+			c.pools = append(c.pools, status.Pool{Config: bc, Adapter: pool.Adapter})
+			continue
+		}
+
+		adapter, err := bc.GetDatabaseAdapter()
+		if err != nil {
+			logger.Error().Err(err).Str("chainId", bc.ChainId).Int64("poolId", bc.PoolId).Msg("Skipping pool, failed to create its database adapter")
+			continue
+		}
+		newCrawler := CreateBundleCrawler(adapter, chain.NewClient(bc.ChainId), bc.ChainId, bc.PoolId, bc.BundleStartId, c.semaphore)
+		children[key] = &newCrawler
+		c.pools = append(c.pools, status.Pool{Config: bc, Adapter: adapter})
+		started = append(started, &newCrawler)
 	}
+
+	replacements := map[*ChildCrawler]bool{}
+	for key, child := range c.children {
+		if children[key] == child {
+			continue
+		}
+
+		child.Stop()
+		replacement, replaced := children[key]
+		if replaced {
+			replacements[replacement] = true
+			logger.Info().Str("chainId", child.chainId).Int64("poolId", child.poolId).Msg("Replacing crawler of changed pool")
+		} else {
+			logger.Info().Str("chainId", child.chainId).Int64("poolId", child.poolId).Msg("Stopped crawling removed pool")
+			replacement = nil
+		}
+
+		if !c.started {
+			closeAdapter(child)
+			continue
+		}
+
+		localChild := child
+		go func() {
+			localChild.Wait()
+			closeAdapter(localChild)
+			if replacement != nil {
+				logger.Info().Str("chainId", replacement.chainId).Int64("poolId", replacement.poolId).Msg("Started crawling changed pool")
+				replacement.Start()
+			}
+		}()
+	}
+	c.children = children
+	c.maxBundleLag = viper.GetInt64("health.max-bundle-lag")
+
+	if c.started {
+		for _, child := range started {
+			if replacements[child] {
+				continue
+			}
+			logger.Info().Str("chainId", child.chainId).Int64("poolId", child.poolId).Msg("Started crawling new pool")
+			go child.Start()
+		}
+	}
+}
+
+// closeAdapter closes the database adapter of a child crawler that is no longer started
+func closeAdapter(crawler *ChildCrawler) {
+	if err := crawler.adapter.Close(); err != nil {
+		logger.Error().Err(err).Str("chainId", crawler.chainId).Int64("poolId", crawler.poolId).Msg("Failed to close database adapter")
+	}
+}
+
+func getPoolKey(pool config.PoolsConfig) string {
+	return fmt.Sprintf("%v/%v", pool.ChainId, pool.PoolId)
+}
+
+// getPools returns the pools that are crawled
+func (c *Crawler) getPools() []status.Pool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return slices.Clone(c.pools)
 }
 
-// Start starts the crawling process for each child crawler.
+// getProbePools returns the pools that are crawled together with the maximum bundle lag of the readiness probe
+func (c *Crawler) getProbePools() ([]status.Pool, int64) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return slices.Clone(c.pools), c.maxBundleLag
+}
+
+// Start starts the crawling process for each child crawler
+// and serves the health probes on the `crawler.health-port`.
+// When the pools of the config file or the registry change, new pools are crawled and removed pools are no longer crawled.
 // NOTE: This function is blocking.
 func (c *Crawler) Start() {
-	var wg sync.WaitGroup
-	for _, bc := range c.children {
-		current := bc
-		wg.Add(1)
-		go func() {
-			current.Start()
-			wg.Done()
-		}()
+	storage, err := config.GetSaveDataItemAdapter()
+	if err != nil {
+		logger.Fatal().Err(err).Msg("Failed to start health probes")
+	}
+	status.StartProbes(viper.GetString("crawler.health-port"), c.getProbePools, storage)
+
+	c.mutex.Lock()
+	c.started = true
+	var pools []config.PoolsConfig
+	for _, pool := range c.pools {
+		pools = append(pools, pool.Config)
 	}
-	wg.Wait()
+	for _, child := range c.children {
+		go child.Start()
+	}
+	c.mutex.Unlock()
+
+	registry.Watch(pools, c.setPools)
+
+	// the child crawlers never return
+	select {}
 }
@@ -1,39 +1,253 @@
 package crawler
 
 import (
-	"os"
+	"encoding/hex"
+	"sync/atomic"
 	"testing"
+	"time"
 
-	"github.com/KYVENetwork/trustless-api/collectors/pool"
+	"github.com/KYVENetwork/trustless-api/collectors/bundles"
+	"github.com/KYVENetwork/trustless-api/collectors/chain"
 	"github.com/KYVENetwork/trustless-api/config"
 	"github.com/KYVENetwork/trustless-api/db/adapters"
 	"github.com/KYVENetwork/trustless-api/files"
-	"github.com/KYVENetwork/trustless-api/indexer"
-	"github.com/spf13/viper"
+	"github.com/KYVENetwork/trustless-api/internal/testutil"
+	"github.com/KYVENetwork/trustless-api/merkle"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
 	"golang.org/x/sync/semaphore"
 )
 
-func TestOsmosisLast50Bundles(t *testing.T) {
+// countingClient counts the requests of the crawler to the chain
+type countingClient struct {
+	chain.ChainClient
+	bundles atomic.Int64
+	pages   atomic.Int64
+}
+
+func (c *countingClient) GetFinalizedBundle(poolId int64, bundleId int64) (*types.FinalizedBundle, error) {
+	c.bundles.Add(1)
+	return c.ChainClient.GetFinalizedBundle(poolId, bundleId)
+}
+
+func (c *countingClient) GetFinalizedBundles(poolId int64, offset int64, limit int) (*types.FinalizedBundlesResponse, error) {
+	c.pages.Add(1)
+	return c.ChainClient.GetFinalizedBundles(poolId, offset, limit)
+}
+
+func TestCrawlBundles(t *testing.T) {
+	for poolId, fixture := range testutil.Fixtures {
+		t.Run(fixture.Indexer, func(t *testing.T) {
+			testutil.LoadConfig(t)
+			chain := testutil.NewChain(t, "kyve-1")
+
+			// bundles of odd and even sizes, including a single data item
+			if err := chain.AddFixtureBundles(int64(poolId), fixture, 100, 4, 5, 1, 3); err != nil {
+				t.Fatal(err)
+			}
+
+			adapter := adapters.GetSQLite(&files.LocalFileAdapter, fixture.GetIndexer(), int64(poolId), "kyve-1")
+			c := CreateBundleCrawler(&adapter, chain, "kyve-1", int64(poolId), 0, semaphore.NewWeighted(4))
+			c.CrawlBundles()
+
+			if missing := adapter.GetMissingBundles(0, 3); len(missing) != 0 {
+				t.Fatalf("expected all bundles to be indexed, missing %v", missing)
+			}
+
+			for bundleId := int64(0); bundleId < 4; bundleId++ {
+				finalizedBundle, err := chain.GetFinalizedBundle(int64(poolId), bundleId)
+				if err != nil {
+					t.Fatal(err)
+				}
+				summaryRoot, err := bundles.GetSummaryMerkleRoot(finalizedBundle)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				bundle, err := adapter.GetBundle(bundleId)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if bundle.MerkleRoot != summaryRoot {
+					t.Errorf("bundle %v: expected merkle root %v, got %v", bundleId, summaryRoot, bundle.MerkleRoot)
+				}
+				if bundle.DataHash != finalizedBundle.DataHash || bundle.StorageID != finalizedBundle.StorageId {
+					t.Errorf("bundle %v: metadata does not match the finalized bundle", bundleId)
+				}
+			}
+
+			coverage, err := adapter.GetCoverage(0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if coverage.LatestBundleId != 3 || len(coverage.Gaps) != 0 {
+				t.Errorf("unexpected coverage %+v", coverage)
+			}
+		})
+	}
+}
+
+func TestCrawlBundlesMerkleRootMismatch(t *testing.T) {
+	testutil.LoadConfig(t)
+	chain := testutil.NewChain(t, "kyve-1")
 
-	save := files.LocalFileAdapter
+	fixture := testutil.Fixtures[0]
+	if err := chain.AddFixtureBundles(1, fixture, 0, 3); err != nil {
+		t.Fatal(err)
+	}
 
-	config.LoadDefaults()
+	// the second bundle commits to a merkle root that its data items don't fold up to
+	otherItems := fixture.DataItems(100, 3)
+	wrongRoot := merkle.GetMerkleRoot(*merkle.GetBundleHashes(&otherItems))
+	if _, err := chain.AddBundleWithMerkleRoot(1, fixture.DataItems(3, 3), hex.EncodeToString(wrongRoot[:])); err != nil {
+		t.Fatal(err)
+	}
 
-	// remove old db
-	os.Remove("db_test.db")
+	adapter := adapters.GetSQLite(&files.LocalFileAdapter, fixture.GetIndexer(), 1, "kyve-1")
+	client := &countingClient{ChainClient: chain}
+	c := CreateBundleCrawler(&adapter, client, "kyve-1", 1, 0, semaphore.NewWeighted(1))
+	c.CrawlBundles()
 
-	viper.Set("database.dbname", "db_test.db")
-	viper.Set("crawler.threads", 4)
+	missing := adapter.GetMissingBundles(0, 1)
+	if len(missing) != 1 || missing[0] != 1 {
+		t.Fatalf("expected bundle 1 to be refused, missing %v", missing)
+	}
 
-	// get latests api info from osmosis pool
-	p, e := pool.GetPoolInfo("kyve-1", 1)
-	if e != nil {
-		panic(e)
+	// the metadata of the refused bundle is cached for the next crawl
+	c.CrawlBundles()
+	if client.pages.Load() != 1 {
+		t.Errorf("expected the finalized bundles to be fetched once, fetched %v pages", client.pages.Load())
 	}
+}
 
-	adapter := adapters.GetSQLite(&save, &indexer.TendermintIndexer, 1, "kyve-1")
+func TestCrawlBundlesInPages(t *testing.T) {
+	testutil.LoadConfig(t)
+	chain := testutil.NewChain(t, "kyve-1")
 
-	c := CreateBundleCrawler(&adapter, "kyve-1", 1, p.Pool.Data.TotalBundles-50, semaphore.NewWeighted(16))
+	fixture := testutil.Fixtures[0]
+	sizes := make([]int, 2*utils.BundlesPageLimit+10)
+	for i := range sizes {
+		sizes[i] = 1
+	}
+	if err := chain.AddFixtureBundles(1, fixture, 0, sizes...); err != nil {
+		t.Fatal(err)
+	}
 
+	adapter := adapters.GetSQLite(&files.LocalFileAdapter, fixture.GetIndexer(), 1, "kyve-1")
+	client := &countingClient{ChainClient: chain}
+	c := CreateBundleCrawler(&adapter, client, "kyve-1", 1, 5, semaphore.NewWeighted(8))
 	c.CrawlBundles()
+
+	lastBundle := int64(len(sizes) - 1)
+	if missing := adapter.GetMissingBundles(5, lastBundle); len(missing) != 0 {
+		t.Fatalf("expected all bundles to be indexed, missing %v", missing)
+	}
+	if client.bundles.Load() != 0 || client.pages.Load() != 3 {
+		t.Errorf("expected 3 pages and no single bundle requests, got %v pages and %v bundle requests", client.pages.Load(), client.bundles.Load())
+	}
+
+	// the cached metadata is released by the next crawl once the bundles are inserted
+	c.CrawlBundles()
+	if len(c.finalizedBundles) != 0 || client.pages.Load() != 3 {
+		t.Errorf("expected no cached bundles and no further pages, got %v cached bundles and %v pages", len(c.finalizedBundles), client.pages.Load())
+	}
+}
+
+func TestCrawlerSetPools(t *testing.T) {
+	testutil.LoadConfig(t)
+
+	c := &Crawler{children: map[string]*ChildCrawler{}, semaphore: semaphore.NewWeighted(1)}
+	pools := []config.PoolsConfig{
+		{ChainId: "kyve-1", PoolId: 1, Indexer: "Height", Slug: "height"},
+		{ChainId: "kyve-1", PoolId: 2, Indexer: "Tendermint", Slug: "tendermint"},
+	}
+
+	c.setPools(pools[:1])
+	c.setPools(pools)
+	if len(c.children) != 2 || len(c.getPools()) != 2 {
+		t.Fatalf("expected a child crawler for each pool, got %v child crawlers", len(c.children))
+	}
+	if c.children["kyve-1/2"].poolId != 2 {
+		t.Errorf("expected child crawler of pool 2, got %v", c.children["kyve-1/2"].poolId)
+	}
+
+	removed, kept := c.children["kyve-1/1"], c.children["kyve-1/2"]
+	changed := []config.PoolsConfig{{ChainId: "kyve-1", PoolId: 2, Indexer: "Tendermint", Slug: "tendermint", ExcludeProof: true}}
+	c.setPools(changed)
+	if len(c.children) != 1 || c.children["kyve-1/2"] != kept {
+		t.Fatalf("expected the child crawler of pool 2 to be kept, got %v child crawlers", len(c.children))
+	}
+	if !isStopped(removed) {
+		t.Error("expected the child crawler of the removed pool to be stopped")
+	}
+	if err := removed.adapter.Ping(); err == nil {
+		t.Error("expected the database adapter of the removed pool to be closed")
+	}
+	if pools := c.getPools(); len(pools) != 1 || !pools[0].Config.ExcludeProof {
+		t.Errorf("expected the changed pool config, got %+v", pools)
+	}
+
+	changed[0].BundleStartId = 10
+	c.setPools(changed)
+	if c.children["kyve-1/2"] == kept || c.children["kyve-1/2"].bundleStartId != 10 {
+		t.Error("expected the child crawler to be replaced after the bundle start id changed")
+	}
+	if !isStopped(kept) {
+		t.Error("expected the replaced child crawler to be stopped")
+	}
+	if err := kept.adapter.Ping(); err == nil {
+		t.Error("expected the database adapter of the replaced child crawler to be closed")
+	}
+}
+
+func TestCrawlerReplaceStartedChild(t *testing.T) {
+	testutil.LoadConfig(t)
+	fakeChain := testutil.NewChain(t, "kyve-1")
+	if err := fakeChain.AddFixtureBundles(1, testutil.Fixtures[0], 0, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	c := &Crawler{children: map[string]*ChildCrawler{}, semaphore: semaphore.NewWeighted(1), started: true}
+	pools := []config.PoolsConfig{{ChainId: "kyve-1", PoolId: 1, Indexer: "Height", Slug: "height"}}
+	c.setPools(pools)
+	replaced := c.children["kyve-1/1"]
+
+	// the replaced child crawler is possibly still crawling, its replacement is started once it is finished
+	pools[0].BundleStartId = 1
+	c.setPools(pools)
+	replacement := c.children["kyve-1/1"]
+	waitDone(t, replaced)
+	waitClosed(t, replaced)
+
+	// the replacement is started and stops once its pool is removed
+	c.setPools(nil)
+	waitDone(t, replacement)
+	waitClosed(t, replacement)
+}
+
+// waitDone waits until a started child crawler is stopped and its last crawl is finished
+func waitDone(t *testing.T, crawler *ChildCrawler) {
+	select {
+	case <-crawler.done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected the child crawler of pool %v to be done", crawler.poolId)
+	}
+}
+
+// waitClosed waits until the database adapter of a stopped child crawler is closed
+func waitClosed(t *testing.T, crawler *ChildCrawler) {
+	for start := time.Now(); crawler.adapter.Ping() == nil; time.Sleep(10 * time.Millisecond) {
+		if time.Since(start) > 10*time.Second {
+			t.Fatalf("expected the database adapter of pool %v to be closed", crawler.poolId)
+		}
+	}
+}
+
+func isStopped(crawler *ChildCrawler) bool {
+	select {
+	case <-crawler.stop:
+		return true
+	default:
+		return false
+	}
 }
@@ -1,12 +1,17 @@
 package db
 
 import (
+	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/KYVENetwork/trustless-api/files"
 	"github.com/KYVENetwork/trustless-api/indexer"
 	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
 )
 
 type DataItemDocument struct {
@@ -17,22 +22,147 @@ type DataItemDocument struct {
 }
 
 type IndexDocument struct {
-	Value      string `gorm:"primarykey"`
-	IndexID    int    `gorm:"primarykey"`
-	DataItemID uint
+	Value        string `gorm:"primarykey"`
+	IndexID      int    `gorm:"primarykey"`
+	DataItemID   uint
+	NumericValue *int64 // the value if the index is a numeric key, range queries compare this value
+}
+
+// NewIndexDocument creates the index document of an index of the data item
+func NewIndexDocument(dataItemId uint, index types.Index) IndexDocument {
+	document := IndexDocument{
+		DataItemID: dataItemId,
+		Value:      index.Index,
+		IndexID:    index.IndexId,
+	}
+
+	if utils.IsKeyIndex(index.IndexId) {
+		if number, err := strconv.ParseInt(index.Index, 10, 64); err == nil {
+			document.NumericValue = &number
+		}
+	}
+
+	return document
+}
+
+// EventDocument is a single attribute of an ABCI event of a data item, block events have the tx index -1
+type EventDocument struct {
+	ID           uint `gorm:"primarykey"`
+	DataItemID   uint
+	Height       int64
+	TxIndex      int
+	CompositeKey string
+	Value        string
+	NumericValue *float64 // the value if it is a number, range conditions compare this value
+}
+
+// NewEventDocument creates the event document of an event of the data item
+func NewEventDocument(dataItemId uint, event types.Event) EventDocument {
+	document := EventDocument{
+		DataItemID:   dataItemId,
+		Height:       event.Height,
+		TxIndex:      event.TxIndex,
+		CompositeKey: event.CompositeKey,
+		Value:        event.Value,
+	}
+
+	if number, err := strconv.ParseFloat(event.Value, 64); err == nil && !math.IsInf(number, 0) && !math.IsNaN(number) {
+		document.NumericValue = &number
+	}
+
+	return document
+}
+
+// RangeDocument is a data item found by a range query
+type RangeDocument struct {
+	Value    string
+	BundleID int64
+	FileType int
+	FilePath string
+}
+
+// BundleDocument stores the metadata of an indexed bundle
+type BundleDocument struct {
+	BundleID          int64 `gorm:"primarykey;autoIncrement:false"`
+	StorageID         string
+	StorageProviderID string
+	DataHash          string
+	FromKey           string
+	ToKey             string
+	BundleSummary     string
+	MerkleRoot        string // computed merkle root, empty if the bundle was indexed before the metadata was stored
+	LeafHashes        string // JSON array of the hex encoded leafs, empty if the bundle was indexed before the metadata was stored
+	IndexedAt         time.Time
+}
+
+// NewBundleDocument creates the bundle document of an indexed bundle.
+// The key range of the finalized bundle is used if available, otherwise the key range of the data items.
+func NewBundleDocument(bundle *types.Bundle) BundleDocument {
+	document := BundleDocument{
+		BundleID:   bundle.BundleId,
+		MerkleRoot: bundle.MerkleRoot,
+		IndexedAt:  time.Now().UTC(),
+	}
+
+	if len(bundle.Leafs) > 0 {
+		leafHashes, _ := json.Marshal(utils.BytesToHex(&bundle.Leafs))
+		document.LeafHashes = string(leafHashes)
+	}
+
+	if bundle.FinalizedBundle != nil {
+		document.StorageID = bundle.FinalizedBundle.StorageId
+		document.StorageProviderID = bundle.FinalizedBundle.StorageProviderId
+		document.DataHash = bundle.FinalizedBundle.DataHash
+		document.FromKey = bundle.FinalizedBundle.FromKey
+		document.ToKey = bundle.FinalizedBundle.ToKey
+		document.BundleSummary = bundle.FinalizedBundle.BundleSummary
+	}
+
+	if document.FromKey == "" && len(bundle.DataItems) > 0 {
+		document.FromKey = bundle.DataItems[0].Key
+		document.ToKey = bundle.DataItems[len(bundle.DataItems)-1].Key
+	}
+
+	return document
 }
 
 type Adapter interface {
-	Save(bundle *types.Bundle) error
+	Save(bundle *types.Bundle, dataItems *[]types.TrustlessDataItem) error
 	Get(indexId int, key string) (files.SavedFile, error)
+	// GetRange returns at most `limit` data items of the numeric keys `from` to `to` (inclusive) ordered by key,
+	// keys that are not indexed are skipped.
+	GetRange(indexId int, from, to int64, limit int) ([]RangeDocument, error)
 	GetMissingBundles(bundleStartId, lastBundleId int64) []int64
 	GetIndexer() indexer.Indexer
+	// Ping checks the connection to the database
+	Ping() error
+	// Close closes the connection to the database
+	Close() error
+
+	// GetCoverage returns the range of data items that are indexed starting at `bundleStartId`
+	GetCoverage(bundleStartId int64) (*types.Coverage, error)
+	// GetUntrackedBundles returns the ids of indexed bundles without bundle metadata,
+	// this is the case for bundles that were indexed before the metadata was stored
+	GetUntrackedBundles() ([]int64, error)
+	// TrackBundle stores the metadata of an already indexed bundle
+	TrackBundle(bundleId int64, finalizedBundle *types.FinalizedBundle) error
+	// GetBundle returns the metadata of an indexed bundle
+	GetBundle(bundleId int64) (*BundleDocument, error)
+	// SearchEvents returns a page of the data items whose events match all conditions of the search ordered by height and tx index,
+	// together with the total count of matching data items
+	SearchEvents(search *types.EventSearch) ([]files.SavedFile, int64, error)
 }
 
-func GetTableNames(poolId int64, chainId string) (string, string) {
+func GetTableNames(poolId int64, chainId string) (string, string, string) {
 
 	chainId = strings.ReplaceAll(chainId, "-", "_")
 
 	return fmt.Sprintf("data_items_pool_%v_%v", chainId, poolId),
-		fmt.Sprintf("indices_pool_%v_%v", chainId, poolId)
+		fmt.Sprintf("indices_pool_%v_%v", chainId, poolId),
+		fmt.Sprintf("bundles_pool_%v_%v", chainId, poolId)
+}
+
+// GetEventTableName returns the name of the table the events of the data items of a pool are stored in
+func GetEventTableName(poolId int64, chainId string) string {
+	return fmt.Sprintf("events_pool_%v_%v", strings.ReplaceAll(chainId, "-", "_"), poolId)
 }
@@ -2,6 +2,7 @@ package adapters
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -29,6 +30,8 @@ type SQLAdapter struct {
 	indexer       indexer.Indexer
 	dataItemTable string
 	indexTable    string
+	bundleTable   string
+	eventTable    string
 }
 
 func GetSQLite(saveDataItem files.SaveDataItem, indexer indexer.Indexer, poolId int64, chainId string) SQLAdapter {
@@ -41,11 +44,13 @@ func GetSQLite(saveDataItem files.SaveDataItem, indexer indexer.Indexer, poolId
 		logger.Fatal().Err(err).Msg("Cannot open database.")
 	}
 
-	dataItemTable, indexTable := db.GetTableNames(poolId, chainId)
+	dataItemTable, indexTable, bundleTable := db.GetTableNames(poolId, chainId)
 
 	// Migrate the schema
 	database.Table(dataItemTable).AutoMigrate(&db.DataItemDocument{})
-	database.Table(indexTable).AutoMigrate(&db.IndexDocument{})
+	migrateIndexTable(database, indexTable)
+	database.Table(bundleTable).AutoMigrate(&db.BundleDocument{})
+	eventTable := migrateEventTable(database, poolId, chainId)
 
 	return SQLAdapter{
 		db:            database,
@@ -53,6 +58,8 @@ func GetSQLite(saveDataItem files.SaveDataItem, indexer indexer.Indexer, poolId
 		indexer:       indexer,
 		dataItemTable: dataItemTable,
 		indexTable:    indexTable,
+		bundleTable:   bundleTable,
+		eventTable:    eventTable,
 	}
 }
 
@@ -73,11 +80,13 @@ func GetPostgres(saveDataItem files.SaveDataItem, indexer indexer.Indexer, poolI
 		logger.Fatal().Err(err).Msg("Cannot open database.")
 	}
 
-	dataItemTable, indexTable := db.GetTableNames(poolId, chainId)
+	dataItemTable, indexTable, bundleTable := db.GetTableNames(poolId, chainId)
 
 	// Migrate the schema
 	database.Table(dataItemTable).AutoMigrate(&db.DataItemDocument{})
-	database.Table(indexTable).AutoMigrate(&db.IndexDocument{})
+	migrateIndexTable(database, indexTable)
+	database.Table(bundleTable).AutoMigrate(&db.BundleDocument{})
+	eventTable := migrateEventTable(database, poolId, chainId)
 
 	return SQLAdapter{
 		db:            database,
@@ -85,26 +94,58 @@ func GetPostgres(saveDataItem files.SaveDataItem, indexer indexer.Indexer, poolI
 		indexer:       indexer,
 		dataItemTable: dataItemTable,
 		indexTable:    indexTable,
+		bundleTable:   bundleTable,
+		eventTable:    eventTable,
+	}
+}
+
+// migrateIndexTable creates the index table of the pool together with the index of the numeric keys.
+// Numeric keys indexed before the numeric value was stored are filled in from their string value.
+func migrateIndexTable(database *gorm.DB, indexTable string) {
+	database.Table(indexTable).AutoMigrate(&db.IndexDocument{})
+
+	if err := database.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%v_numeric ON %v (index_id, numeric_value)", indexTable, indexTable)).Error; err != nil {
+		logger.Error().Err(err).Str("table", indexTable).Msg("Failed to create numeric index")
+	}
+
+	isNumber := "value NOT GLOB '*[^0-9]*' AND value <> ''"
+	if database.Dialector.Name() == "postgres" {
+		isNumber = "value ~ '^[0-9]+$'"
+	}
+
+	// the indices of utils.IsKeyIndex
+	keyIndices := []int{utils.IndexBlockHeight, utils.IndexBlobByNamespace, utils.IndexTendermintBlock, utils.IndexTendermintBlockResults, utils.IndexAllBlobsByNamespace}
+	query := fmt.Sprintf("UPDATE %v SET numeric_value = CAST(value AS BIGINT) WHERE numeric_value IS NULL AND index_id IN ? AND %v", indexTable, isNumber)
+	if err := database.Exec(query, keyIndices).Error; err != nil {
+		logger.Error().Err(err).Str("table", indexTable).Msg("Failed to migrate numeric keys")
+	}
+}
+
+// migrateEventTable creates the event table of the pool together with its indices and returns its name.
+// The indices are created manually, because index names of gorm tags are not unique across the tables of different pools.
+func migrateEventTable(database *gorm.DB, poolId int64, chainId string) string {
+	eventTable := db.GetEventTableName(poolId, chainId)
+	database.Table(eventTable).AutoMigrate(&db.EventDocument{})
+
+	for name, columns := range map[string]string{
+		"value":        "composite_key, value",
+		"numeric":      "composite_key, numeric_value",
+		"data_item_id": "data_item_id",
+	} {
+		if err := database.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%v_%v ON %v (%v)", eventTable, name, eventTable, columns)).Error; err != nil {
+			logger.Error().Err(err).Str("table", eventTable).Msg("Failed to create event index")
+		}
 	}
+
+	return eventTable
 }
 
-// Save inserts the data items provided into the database.
+// Save inserts the trustless data items of an indexed bundle into the database.
 // The entire array is inserted as one transaction ensuring we don't have incomplete data.
 //
 // NOTE: This function is thread safe.
-func (adapter *SQLAdapter) Save(bundle *types.Bundle) error {
+func (adapter *SQLAdapter) Save(bundle *types.Bundle, dataItems *[]types.TrustlessDataItem) error {
 	start := time.Now()
-	dataItems, err := adapter.indexer.IndexBundle(bundle)
-	if err != nil {
-		return err
-	}
-
-	logger.Debug().
-		Int64("bundleId", bundle.BundleId).
-		Int64("poolId", bundle.PoolId).
-		Msg(fmt.Sprintf("indexed %v data items in %v", len(*dataItems), time.Since(start)))
-
-	start = time.Now()
 
 	type Result struct {
 		item *types.TrustlessDataItem
@@ -185,12 +226,7 @@ func (adapter *SQLAdapter) Save(bundle *types.Bundle) error {
 		// then set the data item ID for each index document
 		for i, item := range items {
 			for _, index := range result[i].item.Indices {
-				index := db.IndexDocument{
-					DataItemID: item.ID,
-					Value:      index.Index,
-					IndexID:    index.IndexId,
-				}
-				indices = append(indices, index)
+				indices = append(indices, db.NewIndexDocument(item.ID, index))
 			}
 		}
 
@@ -204,6 +240,38 @@ func (adapter *SQLAdapter) Save(bundle *types.Bundle) error {
 			return err
 		}
 
+		// the events reference the data item they belong to as well
+		var events []db.EventDocument
+		for i, item := range items {
+			for _, event := range result[i].item.Events {
+				events = append(events, db.NewEventDocument(item.ID, event))
+			}
+		}
+
+		if len(events) > 0 {
+			err = tx.Table(adapter.eventTable).CreateInBatches(events, 200).Error
+			if err != nil {
+				logger.Error().
+					Err(err).
+					Int64("bundleId", bundle.BundleId).
+					Int64("poolId", bundle.PoolId).
+					Msg("Failed to insert events into db")
+				return err
+			}
+		}
+
+		// finally insert the metadata of the bundle
+		bundleDocument := db.NewBundleDocument(bundle)
+		err = tx.Table(adapter.bundleTable).Create(&bundleDocument).Error
+		if err != nil {
+			logger.Error().
+				Err(err).
+				Int64("bundleId", bundle.BundleId).
+				Int64("poolId", bundle.PoolId).
+				Msg("Failed to insert bundle into db")
+			return err
+		}
+
 		return nil
 	})
 }
@@ -227,12 +295,121 @@ func (adapter *SQLAdapter) Get(indexId int, key string) (files.SavedFile, error)
 
 	// data item is not found
 	if rows.RowsAffected == 0 {
-		return files.SavedFile{}, fmt.Errorf("data item not found")
+		return files.SavedFile{}, fmt.Errorf("data item not found: %w", types.ErrNotFound)
 	}
 
 	return files.SavedFile{Path: result.FilePath, Type: result.FileType}, nil
 }
 
+// GetRange returns at most `limit` data items of the numeric keys `from` to `to` (inclusive) ordered by key.
+func (adapter *SQLAdapter) GetRange(indexId int, from, to int64, limit int) ([]db.RangeDocument, error) {
+	if to < from || limit <= 0 {
+		return []db.RangeDocument{}, nil
+	}
+
+	var result []db.RangeDocument
+	joinString := fmt.Sprintf("join %v on %v.id = %v.data_item_id", adapter.dataItemTable, adapter.dataItemTable, adapter.indexTable)
+	rows := adapter.db.Table(adapter.indexTable).
+		Select(fmt.Sprintf("%v.value, %v.bundle_id, %v.file_type, %v.file_path", adapter.indexTable, adapter.dataItemTable, adapter.dataItemTable, adapter.dataItemTable)).
+		Joins(joinString).
+		Where(fmt.Sprintf("%v.index_id = ? AND %v.numeric_value BETWEEN ? AND ?", adapter.indexTable, adapter.indexTable), indexId, from, to).
+		Order(fmt.Sprintf("%v.numeric_value", adapter.indexTable)).
+		Limit(limit).
+		Scan(&result)
+	if rows.Error != nil {
+		return nil, rows.Error
+	}
+
+	return result, nil
+}
+
+// SearchEvents returns a page of the data items whose events match all conditions ordered by height and tx index.
+// The first condition selects the events, every further condition has to be matched by another event of the same data item.
+func (adapter *SQLAdapter) SearchEvents(search *types.EventSearch) ([]files.SavedFile, int64, error) {
+	if len(search.Conditions) == 0 {
+		return nil, 0, fmt.Errorf("event search without conditions: %w", types.ErrInvalidParams)
+	}
+
+	where, args, err := getEventConditionSQL("e.", search.Conditions[0])
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if search.TxEvents {
+		where = "e.tx_index >= 0 AND " + where
+	} else {
+		where = "e.tx_index = -1 AND " + where
+	}
+
+	for _, condition := range search.Conditions[1:] {
+		conditionSQL, conditionArgs, err := getEventConditionSQL("", condition)
+		if err != nil {
+			return nil, 0, err
+		}
+		where += fmt.Sprintf(" AND e.data_item_id IN (SELECT data_item_id FROM %v WHERE %v)", adapter.eventTable, conditionSQL)
+		args = append(args, conditionArgs...)
+	}
+
+	var total int64
+	countQuery := fmt.Sprintf("SELECT COUNT(DISTINCT e.data_item_id) FROM %v e WHERE %v", adapter.eventTable, where)
+	if err := adapter.db.Raw(countQuery, args...).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "ASC"
+	if search.Descending {
+		order = "DESC"
+	}
+
+	template := `SELECT d.file_type, d.file_path
+	FROM   %v e
+		   JOIN %v d ON d.id = e.data_item_id
+	WHERE  %v
+	GROUP BY e.data_item_id, e.height, e.tx_index, d.file_type, d.file_path
+	ORDER BY e.height %v, e.tx_index %v
+	LIMIT ? OFFSET ?`
+	query := fmt.Sprintf(template, adapter.eventTable, adapter.dataItemTable, where, order, order)
+
+	var documents []db.DataItemDocument
+	args = append(args, search.PerPage, (search.Page-1)*search.PerPage)
+	if err := adapter.db.Raw(query, args...).Scan(&documents).Error; err != nil {
+		return nil, 0, err
+	}
+
+	result := make([]files.SavedFile, 0, len(documents))
+	for _, document := range documents {
+		result = append(result, files.SavedFile{Type: document.FileType, Path: document.FilePath})
+	}
+
+	return result, total, nil
+}
+
+// getEventConditionSQL returns the SQL of a single event condition, `prefix` qualifies the columns of the event table
+func getEventConditionSQL(prefix string, condition types.EventCondition) (string, []any, error) {
+	sql := prefix + "composite_key = ?"
+	args := []any{condition.CompositeKey}
+
+	switch condition.Operator {
+	case "EXISTS":
+		return sql, args, nil
+	case "CONTAINS":
+		escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(condition.Value)
+		return sql + " AND " + prefix + `value LIKE ? ESCAPE '\'`, append(args, "%"+escaped+"%"), nil
+	case "=":
+		if condition.Number == nil {
+			return sql + " AND " + prefix + "value = ?", append(args, condition.Value), nil
+		}
+		fallthrough
+	case "<", "<=", ">", ">=":
+		if condition.Number == nil {
+			return "", nil, fmt.Errorf("operator %v of %v requires a number: %w", condition.Operator, condition.CompositeKey, types.ErrInvalidParams)
+		}
+		return sql + " AND " + prefix + "numeric_value " + condition.Operator + " ?", append(args, *condition.Number), nil
+	}
+
+	return "", nil, fmt.Errorf("unknown operator %v: %w", condition.Operator, types.ErrInvalidParams)
+}
+
 func (adapter *SQLAdapter) GetMissingBundles(bundleStartId, lastBundle int64) []int64 {
 	template := `WITH recursive ids AS
 	(
@@ -255,6 +432,121 @@ func (adapter *SQLAdapter) GetMissingBundles(bundleStartId, lastBundle int64) []
 	return ids
 }
 
+// GetCoverage returns the range of data items that are indexed starting at `bundleStartId`.
+// Gaps are derived from the tracked bundles, the keys around a gap are the keys of the neighbouring bundles.
+func (adapter *SQLAdapter) GetCoverage(bundleStartId int64) (*types.Coverage, error) {
+	coverage := types.Coverage{
+		BundleStartId:  bundleStartId,
+		LatestBundleId: -1,
+		Gaps:           []types.CoverageGap{},
+	}
+
+	var lowest, highest db.BundleDocument
+	rows := adapter.db.Table(adapter.bundleTable).Where("bundle_id >= ?", bundleStartId).Order("bundle_id asc").Limit(1).Scan(&lowest)
+	if rows.Error != nil {
+		return nil, rows.Error
+	}
+
+	// nothing is indexed yet
+	if rows.RowsAffected == 0 {
+		return &coverage, nil
+	}
+
+	rows = adapter.db.Table(adapter.bundleTable).Order("bundle_id desc").Limit(1).Scan(&highest)
+	if rows.Error != nil {
+		return nil, rows.Error
+	}
+
+	coverage.LatestBundleId = highest.BundleID
+	coverage.LowestKey = lowest.FromKey
+	coverage.HighestKey = highest.ToKey
+
+	// the first bundles of the pool are not indexed yet
+	if lowest.BundleID > bundleStartId {
+		coverage.Gaps = append(coverage.Gaps, types.CoverageGap{
+			FromBundleId: bundleStartId,
+			ToBundleId:   lowest.BundleID - 1,
+			BeforeKey:    lowest.FromKey,
+		})
+	}
+
+	template := `SELECT previous_id + 1 AS from_bundle_id, bundle_id - 1 AS to_bundle_id, previous_key AS after_key, from_key AS before_key
+	FROM   (
+				SELECT bundle_id,
+					   from_key,
+					   LAG(bundle_id) OVER (ORDER BY bundle_id) AS previous_id,
+					   LAG(to_key) OVER (ORDER BY bundle_id) AS previous_key
+				FROM   %v
+				WHERE  bundle_id >= %v ) bundles
+	WHERE  bundle_id - previous_id > 1
+	ORDER BY bundle_id`
+	query := fmt.Sprintf(template, adapter.bundleTable, bundleStartId)
+
+	var gaps []types.CoverageGap
+	if err := adapter.db.Raw(query).Scan(&gaps).Error; err != nil {
+		return nil, err
+	}
+	coverage.Gaps = append(coverage.Gaps, gaps...)
+
+	return &coverage, nil
+}
+
+// GetUntrackedBundles returns the ids of indexed bundles without bundle metadata
+func (adapter *SQLAdapter) GetUntrackedBundles() ([]int64, error) {
+	template := `SELECT DISTINCT bundle_id
+	FROM   %v
+	WHERE  bundle_id NOT IN
+		   (
+					SELECT bundle_id
+					FROM   %v )
+	ORDER BY bundle_id`
+	query := fmt.Sprintf(template, adapter.dataItemTable, adapter.bundleTable)
+	var ids []int64
+	err := adapter.db.Raw(query).Scan(&ids).Error
+	return ids, err
+}
+
+// GetBundle returns the metadata of an indexed bundle
+func (adapter *SQLAdapter) GetBundle(bundleId int64) (*db.BundleDocument, error) {
+	var bundle db.BundleDocument
+	rows := adapter.db.Table(adapter.bundleTable).Where("bundle_id = ?", bundleId).Limit(1).Scan(&bundle)
+	if rows.Error != nil {
+		return nil, rows.Error
+	}
+
+	if rows.RowsAffected == 0 {
+		return nil, fmt.Errorf("bundle not found: %w", types.ErrNotFound)
+	}
+
+	return &bundle, nil
+}
+
+// TrackBundle stores the metadata of an already indexed bundle
+func (adapter *SQLAdapter) TrackBundle(bundleId int64, finalizedBundle *types.FinalizedBundle) error {
+	bundleDocument := db.NewBundleDocument(&types.Bundle{
+		BundleId:        bundleId,
+		FinalizedBundle: finalizedBundle,
+	})
+	return adapter.db.Table(adapter.bundleTable).Create(&bundleDocument).Error
+}
+
+// Ping checks the connection to the database
+func (adapter *SQLAdapter) Ping() error {
+	database, err := adapter.db.DB()
+	if err != nil {
+		return err
+	}
+	return database.Ping()
+}
+
+func (adapter *SQLAdapter) Close() error {
+	database, err := adapter.db.DB()
+	if err != nil {
+		return err
+	}
+	return database.Close()
+}
+
 func (adapter *SQLAdapter) GetIndexer() indexer.Indexer {
 	return adapter.indexer
 }
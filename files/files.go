@@ -25,20 +25,34 @@ type SaveDataItem interface {
 	// Save returns a SavedFile.
 	// It saves a data item on some form of FileStorage, this can be any storage like: S3, local, etc.
 	Save(dataItem *types.TrustlessDataItem) (SavedFile, error)
+	// Ping checks whether the storage is reachable
+	Ping() error
 }
 
 type Get func(indexId int, key string) (SavedFile, error)
 
+// Search returns a page of the data items that match the event search together with the total count of matching data items
+type Search func(search *types.EventSearch) ([]SavedFile, int64, error)
+
+// Resolve loads the saved data item from its file storage.
+// Errors of the file storage are wrapped with types.ErrStorage.
 func (file *SavedFile) Resolve() ([]byte, error) {
 
 	var rawFile []byte
+	var err error
 
 	switch file.Type {
 	case LocalFile:
-		return LoadLocalFile(file.Path)
+		rawFile, err = LoadLocalFile(file.Path)
 	case S3File:
-		return LoadS3File(file.Path)
+		rawFile, err = LoadS3File(file.Path)
+	default:
+		return rawFile, fmt.Errorf("unkown file type %v", file.Type)
+	}
+
+	if err != nil {
+		return rawFile, fmt.Errorf("%w: %w", types.ErrStorage, err)
 	}
 
-	return rawFile, fmt.Errorf("unkown file type %v", file.Type)
+	return rawFile, nil
 }
@@ -88,3 +88,19 @@ func LoadLocalFile(link string) (json.RawMessage, error) {
 	}
 	return file, nil
 }
+
+// Ping checks whether the storage path is writable
+func (saveFile *SaveLocalFileInterface) Ping() error {
+	path := viper.GetString("storage.path")
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(path, ".ping-*")
+	if err != nil {
+		return err
+	}
+	file.Close()
+
+	return os.Remove(file.Name())
+}
@@ -98,9 +98,41 @@ func LoadS3File(path string) ([]byte, error) {
 		return []byte{}, err
 	}
 	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return []byte{}, fmt.Errorf("failed to load %v: got status code %d != 200", path, res.StatusCode)
+	}
+
 	rawFile, err := io.ReadAll(res.Body)
 	if err != nil {
 		return []byte{}, err
 	}
 	return rawFile, nil
 }
+
+// Ping checks whether the bucket and the CDN are reachable, both checks are skipped if they are not configured
+func (saveFile *S3FileInterface) Ping() error {
+	if viper.GetString("storage.bucketname") != "" {
+		if saveFile.client == nil {
+			saveFile.Init()
+		}
+
+		_, err := saveFile.client.HeadBucket(context.TODO(), &s3.HeadBucketInput{
+			Bucket: aws.String(saveFile.bucket),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if url := viper.GetString("storage.cdn"); url != "" {
+		// any response means the CDN is reachable
+		res, err := http.Head(url)
+		if err != nil {
+			return err
+		}
+		res.Body.Close()
+	}
+
+	return nil
+}
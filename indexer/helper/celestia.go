@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/KYVENetwork/trustless-api/files"
 	"github.com/KYVENetwork/trustless-api/merkle"
@@ -28,17 +29,19 @@ func (*CelestiaIndexer) GetBindings() map[string]types.Endpoint {
 					Description: []string{"celestia block height", "celestia share namespace", "blob commitment"},
 				},
 			},
-			Schema: "JsonRPC",
+			Schema:        "JsonRPC",
+			JsonRpcMethod: "blob.Get",
 		},
 		"/GetAll": {
 			QueryParameter: []types.ParameterIndex{
 				{
 					IndexId:     utils.IndexAllBlobsByNamespace,
 					Parameter:   []string{"height", "namespaces"},
-					Description: []string{"celestia block height", "celestia share namespaces"},
+					Description: []string{"celestia block height", "comma separated celestia share namespaces"},
 				},
 			},
-			Schema: "JsonRPC",
+			Schema:        "JsonRPC",
+			JsonRpcMethod: "blob.GetAll",
 		},
 		"/block": {
 			QueryParameter: []types.ParameterIndex{
@@ -48,7 +51,8 @@ func (*CelestiaIndexer) GetBindings() map[string]types.Endpoint {
 					Description: []string{"block height"},
 				},
 			},
-			Schema: "TendermintBlock",
+			Schema:        "TendermintBlock",
+			JsonRpcMethod: "block",
 		},
 		"/block_results": {
 			QueryParameter: []types.ParameterIndex{
@@ -58,7 +62,25 @@ func (*CelestiaIndexer) GetBindings() map[string]types.Endpoint {
 					Description: []string{"block height"},
 				},
 			},
-			Schema: "TendermintBlockResults",
+			Schema:        "TendermintBlockResults",
+			JsonRpcMethod: "block_results",
+		},
+		"/block_search": {
+			QueryParameter: []types.ParameterIndex{
+				{
+					IndexId:           utils.IndexTendermintBlockSearch,
+					Parameter:         []string{"query"},
+					Description:       []string{"event query, e. g. block.height>=5"},
+					OptionalParameter: []string{"page", "per_page", "order_by"},
+					OptionalDescription: []string{
+						"page number starting at 1",
+						"number of blocks per page, at most 100",
+						"order by height, asc or desc (default)",
+					},
+				},
+			},
+			Schema:        "TendermintBlockSearch",
+			JsonRpcMethod: "block_search",
 		},
 	}
 }
@@ -87,7 +109,7 @@ func (c *CelestiaIndexer) calculateBlobsMerkleRoot(blobs *[]types.CelestiaBlob)
 	return merkle.GetMerkleRoot(leafs)
 }
 
-func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, error) {
+func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, *[][32]byte, error) {
 	type ProcessedDataItem struct {
 		value                  types.TendermintValue
 		blobs                  []types.CelestiaBlob
@@ -106,7 +128,7 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 		var celestiaItem CelestiaTendermintItem
 		err := json.Unmarshal(item.Value, &celestiaItem)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// we assume there are 4 blobs per block
@@ -117,7 +139,7 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 			// tx is encoded in base64
 			txBytes, err := base64.StdEncoding.DecodeString(tx)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 			if err := proto.Unmarshal(txBytes, blobTx); err != nil {
 				// not a BlobTx -> no blobs available
@@ -129,7 +151,7 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 
 			tendermintTx := &celestia.Tx{}
 			if err := proto.Unmarshal(blobTx.Tx, tendermintTx); err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			var msgPayForBlobs *celestia.MsgPayForBlobs
@@ -139,13 +161,13 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 					// initilize pointer
 					msgPayForBlobs = &celestia.MsgPayForBlobs{}
 					if err := proto.Unmarshal(msg.Value, msgPayForBlobs); err != nil {
-						return nil, err
+						return nil, nil, err
 					}
 				}
 			}
 
 			if msgPayForBlobs == nil {
-				return nil, fmt.Errorf("missing MsgPayForBlobs in Tx")
+				return nil, nil, fmt.Errorf("missing MsgPayForBlobs in Tx")
 			}
 
 			for index, blob := range blobTx.Blobs {
@@ -163,7 +185,7 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 		var tendermintValue types.TendermintValue
 		err = json.Unmarshal(item.Value, &tendermintValue)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		blockHash := utils.CalculateSHA256Hash(tendermintValue.Block)
@@ -231,13 +253,19 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 		})
 	}
 
+	tree, err := merkle.NewTree(leafs)
+	if err != nil {
+		return nil, nil, err
+	}
+	bundleRoot := tree.Root()
+
 	// assume we have 4 blobs per block + block & block_results
 	trustlessItems := make([]types.TrustlessDataItem, 0, len(items)*6)
 
 	for index, item := range items {
-		proof, err := merkle.GetHashesCompact(&leafs, index)
+		proof, err := tree.GetProof(index)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		blobLeafs := make([][32]byte, 0, len(item.blobs))
@@ -245,6 +273,14 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 			blobLeafs = append(blobLeafs, utils.CalculateSHA256Hash(blob))
 		}
 
+		// blocks without blobs have no blob tree
+		var blobTree *merkle.Tree
+		if len(blobLeafs) > 0 {
+			if blobTree, err = merkle.NewTree(blobLeafs); err != nil {
+				return nil, nil, err
+			}
+		}
+
 		// only safe blobs once, height-namespace-commitment is not unique, but this does not matter for the blobs.Get request.
 		// It simply returns the first Blob it finds.
 		savedBlobs := map[string]bool{}
@@ -259,23 +295,26 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 
 			blobRaw, err := json.Marshal(blob)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			rpcResponse, err := utils.WrapIntoJsonRpcResponse(json.RawMessage(blobRaw))
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
-			blobProof, err := merkle.GetHashesCompact(&blobLeafs, blobIndex)
+			blobProof, err := blobTree.GetProof(blobIndex)
 			if err != nil {
-				return nil, err
+				return nil, nil, err
 			}
 
 			// append local proof with tendermint block & block results
 			blobProof = append(blobProof, item.localBlobsProof...)
 
-			encodedProof := utils.EncodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, "", "result", append(blobProof, proof...))
+			encodedProof, err := encodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, bundleRoot, utils.LeafSchemeCelestia, "", "result", append(blobProof, proof...))
+			if err != nil {
+				return nil, nil, err
+			}
 
 			trustlessItems = append(trustlessItems, types.TrustlessDataItem{
 				PoolId:   bundle.PoolId,
@@ -294,7 +333,7 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 
 		rawAllBlobs, err := json.Marshal(item.blobs)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// create a trustless item for all blobs
@@ -314,10 +353,19 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 
 		rpcResponse, err := utils.WrapIntoJsonRpcResponse(item.value.Block)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		// only block events are searched, celestia transactions are not indexed on their own
+		blockEvents, _, err := getEvents(item.value.BlockResults, item.key, nil)
+		if err != nil {
+			return nil, nil, err
 		}
 
-		encodedProof := utils.EncodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, "", "result", append(item.localBlockProof, proof...))
+		encodedProof, err := encodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, bundleRoot, utils.LeafSchemeCelestia, "", "result", append(item.localBlockProof, proof...))
+		if err != nil {
+			return nil, nil, err
+		}
 		trustlessItems = append(trustlessItems, types.TrustlessDataItem{
 			PoolId:   bundle.PoolId,
 			BundleId: bundle.BundleId,
@@ -330,14 +378,18 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 					IndexId: utils.IndexTendermintBlock,
 				},
 			},
+			Events: blockEvents,
 		})
 
 		rpcResponse, err = utils.WrapIntoJsonRpcResponse(item.value.BlockResults)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		encodedProof = utils.EncodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, "", "result", append(item.localBlockResultsProof, proof...))
+		encodedProof, err = encodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, bundleRoot, utils.LeafSchemeCelestia, "", "result", append(item.localBlockResultsProof, proof...))
+		if err != nil {
+			return nil, nil, err
+		}
 		trustlessItems = append(trustlessItems, types.TrustlessDataItem{
 			PoolId:   bundle.PoolId,
 			BundleId: bundle.BundleId,
@@ -353,17 +405,24 @@ func (c *CelestiaIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 		})
 	}
 
-	return &trustlessItems, nil
+	return &trustlessItems, &leafs, nil
 }
 
 func (*CelestiaIndexer) GetErrorResponse(message string, data any) any {
 	return utils.WrapIntoJsonRpcErrorResponse(message, data)
 }
 
+func (d *CelestiaIndexer) SearchEvents(_ files.Get, search files.Search, indexId int, query []string) (*types.InterceptionResponse, error) {
+	if indexId != utils.IndexTendermintBlockSearch {
+		return nil, nil
+	}
+	return searchBlocks(search, query)
+}
+
 func (d *CelestiaIndexer) InterceptRequest(get files.Get, indexId int, query []string) (*types.InterceptionResponse, error) {
 	if indexId == utils.IndexAllBlobsByNamespace {
 		if len(query) != 2 {
-			return nil, fmt.Errorf("query paramter count mismatch")
+			return nil, fmt.Errorf("query paramter count mismatch: %w", types.ErrInvalidParams)
 		}
 
 		// the first query parameter (block_height) is our unique identifier
@@ -385,12 +444,15 @@ func (d *CelestiaIndexer) InterceptRequest(get files.Get, indexId int, query []s
 			return nil, err
 		}
 
-		// namespace is the second query parameter
-		namespace := query[1]
+		// namespaces are the second query parameter, multiple namespaces are separated by commas
+		namespaces := map[string]bool{}
+		for _, namespace := range strings.Split(query[1], ",") {
+			namespaces[namespace] = true
+		}
 
 		filtedredBlobs := []types.CelestiaBlob{}
 		for _, b := range celestiaBlobs.Value {
-			if b.Namespace == namespace {
+			if namespaces[b.Namespace] {
 				filtedredBlobs = append(filtedredBlobs, b)
 			}
 		}
@@ -1,8 +1,15 @@
 package helper
 
 import (
+	"encoding/hex"
+
 	"github.com/KYVENetwork/trustless-api/files"
 	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+)
+
+var (
+	logger = utils.TrustlessApiLogger("indexer")
 )
 
 type DefaultIndexer struct{}
@@ -17,3 +24,18 @@ func (d *DefaultIndexer) GetErrorResponse(message string, data any) any {
 func (d *DefaultIndexer) InterceptRequest(get files.Get, indexId int, query []string) (*types.InterceptionResponse, error) {
 	return nil, nil
 }
+
+// encodeProof encodes the proof of a trustless data item of the given bundle with the latest proof version
+func encodeProof(poolId, bundleId int64, chainId string, bundleRoot [32]byte, leafScheme uint8, dataItemKey, dataItemValueKey string, hashes []types.MerkleNode) (string, error) {
+	return utils.EncodeProof(&types.Proof{
+		Version:          utils.ProofVersion2,
+		Hashes:           hashes,
+		BundleId:         bundleId,
+		ChainId:          chainId,
+		PoolId:           poolId,
+		DataItemKey:      dataItemKey,
+		DataItemValueKey: dataItemValueKey,
+		LeafScheme:       leafScheme,
+		BundleRoot:       hex.EncodeToString(bundleRoot[:]),
+	})
+}
@@ -51,24 +51,32 @@ func (*EthBlobsIndexer) getDataItemIndices(dataitem *types.DataItem) ([]types.In
 	return indices, nil
 }
 
-func (e *EthBlobsIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, error) {
+func (e *EthBlobsIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, *[][32]byte, error) {
 	leafs := merkle.GetBundleHashes(&bundle.DataItems)
+	tree, err := merkle.NewTree(*leafs)
+	if err != nil {
+		return nil, nil, err
+	}
+	bundleRoot := tree.Root()
 	var trustlessItems []types.TrustlessDataItem
 	for index, dataitem := range bundle.DataItems {
-		proof, err := merkle.GetHashesCompact(leafs, index)
+		proof, err := tree.GetProof(index)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		indices, err := e.getDataItemIndices(&dataitem)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		encodedProof := utils.EncodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, dataitem.Key, "value", proof)
+		encodedProof, err := encodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, bundleRoot, utils.LeafSchemeDataItem, dataitem.Key, "value", proof)
+		if err != nil {
+			return nil, nil, err
+		}
 
 		bytes, err := json.Marshal(dataitem)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		trustlessDataItem := types.TrustlessDataItem{
@@ -81,5 +89,5 @@ func (e *EthBlobsIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessD
 		}
 		trustlessItems = append(trustlessItems, trustlessDataItem)
 	}
-	return &trustlessItems, nil
+	return &trustlessItems, leafs, nil
 }
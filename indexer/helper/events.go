@@ -0,0 +1,437 @@
+package helper
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/KYVENetwork/trustless-api/files"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+)
+
+const (
+	defaultSearchPerPage = 30
+	maxSearchPerPage     = 100
+)
+
+// abciEvent is an event of the block results, up to CometBFT v0.34 the attributes are base64 encoded
+type abciEvent struct {
+	Type       string `json:"type"`
+	Attributes []struct {
+		Key   string `json:"key"`
+		Value string `json:"value"`
+		Index *bool  `json:"index"`
+	} `json:"attributes"`
+}
+
+// abciBlockEvents are the events of the block results, the block events are emitted by
+// begin_block and end_block up to CometBFT v0.37 and by finalize_block since v0.38
+type abciBlockEvents struct {
+	TxsResults []struct {
+		Events []abciEvent `json:"events"`
+	} `json:"txs_results"`
+	BeginBlockEvents    []abciEvent `json:"begin_block_events"`
+	EndBlockEvents      []abciEvent `json:"end_block_events"`
+	FinalizeBlockEvents []abciEvent `json:"finalize_block_events"`
+}
+
+// getEvents extracts the events of the block results like the kv indexer of CometBFT indexes them.
+// Returns the block events and the events of every transaction, `txHashes` are the hashes of the transactions of the block.
+// Besides the ABCI events, the block is indexed by `block.height` and every transaction by `tx.height` and `tx.hash`.
+// Attributes are only skipped if they are explicitly not indexed.
+func getEvents(blockResults json.RawMessage, key string, txHashes []string) ([]types.Event, [][]types.Event, error) {
+	height, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid height %v: %w", key, err)
+	}
+
+	var results abciBlockEvents
+	if err := json.Unmarshal(blockResults, &results); err != nil {
+		return nil, nil, err
+	}
+
+	blockEvents := results.BeginBlockEvents
+	blockEvents = append(blockEvents, results.EndBlockEvents...)
+	blockEvents = append(blockEvents, results.FinalizeBlockEvents...)
+
+	allEvents := blockEvents
+	for _, txResult := range results.TxsResults {
+		allEvents = append(allEvents, txResult.Events...)
+	}
+	decode := isBase64Encoded(allEvents)
+
+	convert := func(events []abciEvent, txIndex int) []types.Event {
+		var converted []types.Event
+		for _, event := range events {
+			for _, attribute := range event.Attributes {
+				if attribute.Index != nil && !*attribute.Index {
+					continue
+				}
+
+				attributeKey, value := attribute.Key, attribute.Value
+				if decode {
+					attributeKey = decodeBase64(attributeKey)
+					value = decodeBase64(value)
+				}
+				if event.Type == "" || attributeKey == "" {
+					continue
+				}
+
+				converted = append(converted, types.Event{
+					CompositeKey: event.Type + "." + attributeKey,
+					Value:        value,
+					Height:       height,
+					TxIndex:      txIndex,
+				})
+			}
+		}
+		return converted
+	}
+
+	block := append(convert(blockEvents, -1), types.Event{CompositeKey: "block.height", Value: key, Height: height, TxIndex: -1})
+
+	txs := make([][]types.Event, len(results.TxsResults))
+	for index, txResult := range results.TxsResults {
+		txs[index] = append(convert(txResult.Events, index), types.Event{CompositeKey: "tx.height", Value: key, Height: height, TxIndex: index})
+		if index < len(txHashes) {
+			txs[index] = append(txs[index], types.Event{CompositeKey: "tx.hash", Value: txHashes[index], Height: height, TxIndex: index})
+		}
+	}
+
+	return block, txs, nil
+}
+
+// isBase64Encoded returns whether the attributes of the events are base64 encoded, which is the case if every attribute key
+// decodes to printable text. Attribute keys are short identifiers like `recipient`, which are rarely valid base64 themselves.
+func isBase64Encoded(events []abciEvent) bool {
+	found := false
+	for _, event := range events {
+		for _, attribute := range event.Attributes {
+			decoded, err := base64.StdEncoding.DecodeString(attribute.Key)
+			if err != nil || len(decoded) == 0 || !utf8.Valid(decoded) {
+				return false
+			}
+			for _, r := range string(decoded) {
+				if !unicode.IsPrint(r) {
+					return false
+				}
+			}
+			found = true
+		}
+	}
+	return found
+}
+
+// decodeBase64 decodes a base64 encoded attribute, attributes that can't be decoded are returned as they are
+func decodeBase64(value string) string {
+	decoded, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return value
+	}
+	return string(decoded)
+}
+
+// parseEventQuery parses an event query of CometBFT, e. g. `transfer.recipient='kyve1...' AND tx.height>=5`.
+// The conditions are joined with AND, an operand is either a string in single quotes or a number.
+// Conditions on `tm.event` are skipped, the endpoint already selects whether transactions or blocks are searched.
+func parseEventQuery(query string) ([]types.EventCondition, error) {
+	invalid := func(message string, args ...any) error {
+		return fmt.Errorf("invalid query %v: %v: %w", query, fmt.Sprintf(message, args...), types.ErrInvalidParams)
+	}
+
+	var conditions []types.EventCondition
+	rest := strings.TrimSpace(query)
+	for {
+		// composite key
+		end := strings.IndexAny(rest, " =<>'")
+		if end == -1 {
+			end = len(rest)
+		}
+		if end == 0 {
+			return nil, invalid("expected a composite key at '%v'", rest)
+		}
+		condition := types.EventCondition{CompositeKey: rest[:end]}
+		rest = strings.TrimLeft(rest[end:], " ")
+
+		// operator
+		for _, operator := range []string{"<=", ">=", "=", "<", ">", "CONTAINS", "EXISTS"} {
+			if strings.HasPrefix(rest, operator) {
+				condition.Operator = operator
+				break
+			}
+		}
+		if condition.Operator == "" {
+			return nil, invalid("expected an operator after %v", condition.CompositeKey)
+		}
+		rest = strings.TrimLeft(rest[len(condition.Operator):], " ")
+
+		// operand
+		if condition.Operator != "EXISTS" {
+			if strings.HasPrefix(rest, "'") {
+				end := strings.Index(rest[1:], "'")
+				if end == -1 {
+					return nil, invalid("unterminated string")
+				}
+				condition.Value = rest[1 : end+1]
+				rest = rest[end+2:]
+			} else {
+				end := strings.Index(rest, " ")
+				if end == -1 {
+					end = len(rest)
+				}
+				number, err := strconv.ParseFloat(rest[:end], 64)
+				if err != nil {
+					return nil, invalid("operand of %v has to be a string in single quotes or a number", condition.CompositeKey)
+				}
+				condition.Value = rest[:end]
+				condition.Number = &number
+				rest = rest[end:]
+			}
+
+			if condition.Operator == "CONTAINS" && condition.Number != nil {
+				return nil, invalid("operand of CONTAINS has to be a string")
+			}
+			if condition.Operator != "=" && condition.Operator != "CONTAINS" && condition.Number == nil {
+				return nil, invalid("operand of %v has to be a number", condition.Operator)
+			}
+		}
+
+		if condition.CompositeKey != "tm.event" {
+			conditions = append(conditions, condition)
+		}
+
+		rest = strings.TrimLeft(rest, " ")
+		if rest == "" {
+			break
+		}
+		if !strings.HasPrefix(rest, "AND ") {
+			return nil, invalid("expected AND at '%v'", rest)
+		}
+		rest = strings.TrimLeft(rest[len("AND "):], " ")
+	}
+
+	if len(conditions) == 0 {
+		return nil, invalid("no conditions")
+	}
+
+	return conditions, nil
+}
+
+// newEventSearch creates the event search of the query parameters `query, page, per_page, order_by`.
+// Like in CometBFT, string parameters may be wrapped in double quotes, `per_page` is capped at 100
+// and the results are ordered ascending by default unless `descending` is set.
+func newEventSearch(query, page, perPage, orderBy string, txEvents, descending bool) (*types.EventSearch, error) {
+	unquote := func(value string) string {
+		return strings.TrimSpace(strings.Trim(value, `"`))
+	}
+
+	conditions, err := parseEventQuery(unquote(query))
+	if err != nil {
+		return nil, err
+	}
+
+	search := &types.EventSearch{
+		Conditions: conditions,
+		TxEvents:   txEvents,
+		Page:       1,
+		PerPage:    defaultSearchPerPage,
+		Descending: descending,
+	}
+
+	if page := unquote(page); page != "" {
+		search.Page, err = strconv.Atoi(page)
+		if err != nil || search.Page < 1 {
+			return nil, fmt.Errorf("page has to be a positive number: %w", types.ErrInvalidParams)
+		}
+	}
+
+	if perPage := unquote(perPage); perPage != "" {
+		search.PerPage, err = strconv.Atoi(perPage)
+		if err != nil {
+			return nil, fmt.Errorf("per_page has to be a number: %w", types.ErrInvalidParams)
+		}
+		if search.PerPage < 1 {
+			search.PerPage = defaultSearchPerPage
+		}
+		search.PerPage = min(search.PerPage, maxSearchPerPage)
+	}
+
+	switch unquote(orderBy) {
+	case "":
+	case "asc":
+		search.Descending = false
+	case "desc":
+		search.Descending = true
+	default:
+		return nil, fmt.Errorf("order_by has to be asc or desc: %w", types.ErrInvalidParams)
+	}
+
+	return search, nil
+}
+
+// runEventSearch runs the search and resolves the matching data items.
+// Like CometBFT, a page after the last page is invalid, unless nothing matches at all.
+func runEventSearch(search files.Search, eventSearch *types.EventSearch) ([]types.TrustlessDataItem, int64, error) {
+	savedFiles, total, err := search(eventSearch)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if pages := (total + int64(eventSearch.PerPage) - 1) / int64(eventSearch.PerPage); total > 0 && int64(eventSearch.Page) > pages {
+		return nil, 0, fmt.Errorf("page should be within [1, %v] range, given %v: %w", pages, eventSearch.Page, types.ErrInvalidParams)
+	}
+
+	items := make([]types.TrustlessDataItem, 0, len(savedFiles))
+	for _, file := range savedFiles {
+		bytes, err := file.Resolve()
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var item types.TrustlessDataItem
+		if err := json.Unmarshal(bytes, &item); err != nil {
+			return nil, 0, err
+		}
+		items = append(items, item)
+	}
+
+	return items, total, nil
+}
+
+// getJsonRpcResult returns the result of a JSON-RPC response
+func getJsonRpcResult(rpcResponse json.RawMessage) (json.RawMessage, error) {
+	response := struct {
+		Result json.RawMessage `json:"result"`
+	}{}
+	if err := json.Unmarshal(rpcResponse, &response); err != nil {
+		return nil, err
+	}
+	return response.Result, nil
+}
+
+// searchTxs serves `tx_search` with the query `query, prove, page, per_page, order_by`.
+// Every transaction is served like by /tx, its proofs are only included if `prove` is true. Then the KYVE proofs are served
+// in the same order as the transactions, together with the block and block results each transaction is proven with.
+func searchTxs(get files.Get, search files.Search, query []string) (*types.InterceptionResponse, error) {
+	if len(query) != 5 {
+		return nil, fmt.Errorf("query paramter count mismatch: %w", types.ErrInvalidParams)
+	}
+
+	eventSearch, err := newEventSearch(query[0], query[2], query[3], query[4], true, false)
+	if err != nil {
+		return nil, err
+	}
+	prove := strings.Trim(query[1], `"`) == "true"
+
+	items, total, err := runEventSearch(search, eventSearch)
+	if err != nil {
+		return nil, err
+	}
+
+	txs := make([]TendermintTx, 0, len(items))
+	var proofs []string
+	var blocks, blockResults []json.RawMessage
+	for _, item := range items {
+		result, err := getJsonRpcResult(item.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		var tx TendermintTx
+		if err := json.Unmarshal(result, &tx); err != nil {
+			return nil, err
+		}
+		if !prove {
+			tx.Proof = TendermintTxProof{}
+			txs = append(txs, tx)
+			continue
+		}
+
+		block, blockResult, err := getTxBlock(get, result)
+		if err != nil {
+			return nil, err
+		}
+		txs = append(txs, tx)
+		proofs = append(proofs, item.Proof)
+		blocks = append(blocks, block)
+		blockResults = append(blockResults, blockResult)
+	}
+
+	response := struct {
+		JsonRPC string `json:"jsonrpc"`
+		ID      int    `json:"id"`
+		Result  struct {
+			Txs        []TendermintTx `json:"txs"`
+			TotalCount string         `json:"total_count"`
+		} `json:"result"`
+		Blocks       []json.RawMessage `json:"blocks,omitempty"`
+		BlockResults []json.RawMessage `json:"block_results,omitempty"`
+	}{
+		JsonRPC:      "2.0",
+		ID:           -1,
+		Blocks:       blocks,
+		BlockResults: blockResults,
+	}
+	response.Result.Txs = txs
+	response.Result.TotalCount = strconv.FormatInt(total, 10)
+
+	rpcResponse, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+
+	if prove && proofs == nil {
+		proofs = []string{}
+	}
+	return &types.InterceptionResponse{
+		Data:   &rpcResponse,
+		Proofs: proofs,
+	}, nil
+}
+
+// searchBlocks serves `block_search` with the query `query, page, per_page, order_by`, the blocks are ordered descending by default.
+// Every block is served like by /block, the proofs are served in the same order as the blocks.
+func searchBlocks(search files.Search, query []string) (*types.InterceptionResponse, error) {
+	if len(query) != 4 {
+		return nil, fmt.Errorf("query paramter count mismatch: %w", types.ErrInvalidParams)
+	}
+
+	eventSearch, err := newEventSearch(query[0], query[1], query[2], query[3], false, true)
+	if err != nil {
+		return nil, err
+	}
+
+	items, total, err := runEventSearch(search, eventSearch)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := make([]json.RawMessage, 0, len(items))
+	proofs := make([]string, 0, len(items))
+	for _, item := range items {
+		result, err := getJsonRpcResult(item.Value)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, result)
+		proofs = append(proofs, item.Proof)
+	}
+
+	rpcResponse, err := utils.WrapIntoJsonRpcResponse(struct {
+		Blocks     []json.RawMessage `json:"blocks"`
+		TotalCount string            `json:"total_count"`
+	}{blocks, strconv.FormatInt(total, 10)})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.InterceptionResponse{
+		Data:   &rpcResponse,
+		Proofs: proofs,
+	}, nil
+}
@@ -5,6 +5,9 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
 
 	"github.com/KYVENetwork/trustless-api/files"
 	"github.com/KYVENetwork/trustless-api/merkle"
@@ -14,8 +17,33 @@ import (
 
 type EVMIndexer struct {
 	DefaultIndexer
+	// PrecomputeTransactions stores every transaction in its own data item together with its proof,
+	// a transaction lookup then reads a single small file instead of the entire block.
+	PrecomputeTransactions bool
+	// maxRangeSize is the maximum number of blocks of a log request, it is set by the server
+	maxRangeSize atomic.Int64
 }
 
+// evmDefaultMaxRangeSize is the maximum number of blocks of a log request until the server sets the limit,
+// it is the default of `server.max-range-size`
+const evmDefaultMaxRangeSize = 100
+
+// SetMaxRangeSize sets the maximum number of blocks of a log request
+func (e *EVMIndexer) SetMaxRangeSize(size int64) {
+	e.maxRangeSize.Store(size)
+}
+
+// evmTransactionTreeCacheSize is the number of blocks whose transaction trees are cached
+const evmTransactionTreeCacheSize = 256
+
+// evmLogTreeCacheSize is the number of blocks whose log trees are cached
+const evmLogTreeCacheSize = 256
+
+var (
+	transactionTrees = merkle.NewTreeCache(evmTransactionTreeCacheSize)
+	logTrees         = merkle.NewTreeCache(evmLogTreeCacheSize)
+)
+
 func (*EVMIndexer) GetBindings() map[string]types.Endpoint {
 	return map[string]types.Endpoint{
 		"/blockByHash": {
@@ -26,7 +54,19 @@ func (*EVMIndexer) GetBindings() map[string]types.Endpoint {
 					Description: []string{"hash of a block"},
 				},
 			},
-			Schema: "EVMBlock",
+			Schema:        "EVMBlock",
+			JsonRpcMethod: "eth_getBlockByHash",
+		},
+		"/blockByNumber": {
+			QueryParameter: []types.ParameterIndex{
+				{
+					IndexId:     utils.IndexEVMValue,
+					Parameter:   []string{"number"},
+					Description: []string{"number of a block (decimal or hex)"},
+				},
+			},
+			Schema:        "EVMBlock",
+			JsonRpcMethod: "eth_getBlockByNumber",
 		},
 		"/transactionByHash": {
 			QueryParameter: []types.ParameterIndex{
@@ -36,7 +76,8 @@ func (*EVMIndexer) GetBindings() map[string]types.Endpoint {
 					Description: []string{"hash of a transaction"},
 				},
 			},
-			Schema: "EVMTransaction",
+			Schema:        "EVMTransaction",
+			JsonRpcMethod: "eth_getTransactionByHash",
 		},
 		"/blockReceipts": {
 			QueryParameter: []types.ParameterIndex{
@@ -46,7 +87,36 @@ func (*EVMIndexer) GetBindings() map[string]types.Endpoint {
 					Description: []string{"hash of a block"},
 				},
 			},
-			Schema: "EVMBlockReceipts",
+			Schema:        "EVMBlockReceipts",
+			JsonRpcMethod: "eth_getBlockReceipts",
+		},
+		"/transactionReceipt": {
+			QueryParameter: []types.ParameterIndex{
+				{
+					IndexId:     utils.IndexEVMTransactionReceipt,
+					Parameter:   []string{"hash"},
+					Description: []string{"hash of a transaction, the receipt is served together with the receipts of its block in `blockReceipts` to prove it"},
+				},
+			},
+			Schema:        "EVMTransactionReceipt",
+			JsonRpcMethod: "eth_getTransactionReceipt",
+		},
+		"/logs": {
+			QueryParameter: []types.ParameterIndex{
+				{
+					IndexId:           utils.IndexEVMLog,
+					OptionalParameter: []string{"blockHash", "fromBlock", "toBlock", "address", "topics"},
+					OptionalDescription: []string{
+						"hash of a block, can't be combined with fromBlock and toBlock",
+						"first block number of the range (decimal or hex)",
+						"last block number of the range (decimal or hex), defaults to fromBlock",
+						"comma separated contract addresses, a log matches if it was emitted by one of them",
+						"comma separated topics by position, alternatives of a position are separated with `|` and an empty position matches any topic, e.g. `0xddf2...,,0x0000...`",
+					},
+				},
+			},
+			Schema:        "EVMLogs",
+			JsonRpcMethod: "eth_getLogs",
 		},
 	}
 }
@@ -96,11 +166,19 @@ type Transaction struct {
 	Hash string `json:"hash"`
 }
 
+// PrecomputedTransaction is the value of the data item of a single transaction if the transactions are precomputed
+type PrecomputedTransaction struct {
+	Transaction json.RawMessage `json:"transaction"`
+	// Key is the key of the block's data item, it is used to look up the receipt of the transaction
+	Key string `json:"key"`
+}
+
 type Log struct {
-	Address         string `json:"address"`
-	BlockHash       string `json:"blockHash"`
-	LogIndex        string `json:"logIndex"`
-	TransactionHash string `json:"transactionHash"`
+	Address         string   `json:"address"`
+	Topics          []string `json:"topics"`
+	BlockHash       string   `json:"blockHash"`
+	LogIndex        string   `json:"logIndex"`
+	TransactionHash string   `json:"transactionHash"`
 }
 
 type ProcessedDataItem struct {
@@ -117,6 +195,39 @@ type IntermediateItem struct {
 	BundleId    int64              `json:"bundleId"`
 	PoolId      int64              `json:"poolId"`
 	ChainId     string             `json:"chainId"`
+	// BundleRoot is the hex encoded merkle root of the bundle, it is empty for items that were indexed before it was stored
+	BundleRoot string `json:"bundleRoot,omitempty"`
+}
+
+// encodeProof encodes the proof for a value of the intermediate item, `localProof` folds the leaf up to the data item's leaf.
+func (i *IntermediateItem) encodeProof(leaf [32]byte, localProof []types.MerkleNode) (string, error) {
+	return i.encodeSchemeProof(leaf, localProof, utils.LeafSchemeEVM, "result")
+}
+
+// encodeSchemeProof encodes the proof like encodeProof, but with the given leaf scheme and response field of the leaf.
+// The bundle root of the proof is the merkle root of the bundle the item was indexed with, so a verifier can detect
+// a proof that does not fold up to it. Only for items without a stored bundle root it is derived by folding the proof.
+func (i *IntermediateItem) encodeSchemeProof(leaf [32]byte, localProof []types.MerkleNode, leafScheme uint8, dataItemValueKey string) (string, error) {
+	hashes := make([]types.MerkleNode, 0, len(localProof)+len(i.BundleProof))
+	hashes = append(hashes, localProof...)
+	hashes = append(hashes, i.BundleProof...)
+
+	var bundleRoot [32]byte
+	if i.BundleRoot != "" {
+		root, err := hex.DecodeString(i.BundleRoot)
+		if err != nil || len(root) != len(bundleRoot) {
+			return "", fmt.Errorf("invalid bundle root %v of bundle %v", i.BundleRoot, i.BundleId)
+		}
+		copy(bundleRoot[:], root)
+	} else {
+		var err error
+		bundleRoot, err = merkle.GetMerkleRootFromProof(leaf, hashes)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	return encodeProof(i.PoolId, i.BundleId, i.ChainId, bundleRoot, leafScheme, "", dataItemValueKey, hashes)
 }
 
 func getMerkleRoot[T any](array *[]T) [32]byte {
@@ -129,7 +240,7 @@ func getMerkleRoot[T any](array *[]T) [32]byte {
 	return merkle.GetMerkleRoot(leafs)
 }
 
-func (c *EVMIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, error) {
+func (c *EVMIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, *[][32]byte, error) {
 
 	leafs := make([][32]byte, 0, len(bundle.DataItems))
 	items := make([]ProcessedDataItem, 0, len(bundle.DataItems))
@@ -138,7 +249,7 @@ func (c *EVMIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataIt
 		var evmDataItem EVMDataItem
 		err := json.Unmarshal(item.Value, &evmDataItem)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		// Flatten logs array of all receipts into one log array to create a Merkle root
@@ -238,13 +349,19 @@ func (c *EVMIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataIt
 		})
 	}
 
+	tree, err := merkle.NewTree(leafs)
+	if err != nil {
+		return nil, nil, err
+	}
+	bundleRoot := tree.Root()
+
 	trustlessItems := make([]types.TrustlessDataItem, 0, len(items)*6)
 
 	for index, item := range items {
 
-		proof, err := merkle.GetHashesCompact(&leafs, index)
+		proof, err := tree.GetProof(index)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		intermediateItem := IntermediateItem{
@@ -253,12 +370,13 @@ func (c *EVMIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataIt
 			BundleId:    bundle.BundleId,
 			PoolId:      bundle.PoolId,
 			ChainId:     bundle.ChainId,
+			BundleRoot:  hex.EncodeToString(bundleRoot[:]),
 		}
 
 		rawItem, err := json.Marshal(intermediateItem)
 
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		indices := []types.Index{
@@ -270,18 +388,30 @@ func (c *EVMIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataIt
 				Index:   item.Value.Block.Hash,
 				IndexId: utils.IndexEVMReceipt,
 			},
+			{
+				Index:   item.Key,
+				IndexId: utils.IndexEVMValue,
+			},
 		}
 
-		for _, tx := range item.Value.Block.Transactions {
-			var unmarshalledTx Transaction
-			if err = json.Unmarshal(tx, &unmarshalledTx); err != nil {
-				return nil, err
+		if c.PrecomputeTransactions {
+			txItems, err := precomputeTransactions(&intermediateItem)
+			if err != nil {
+				return nil, nil, err
+			}
+			trustlessItems = append(trustlessItems, txItems...)
+		} else {
+			for _, tx := range item.Value.Block.Transactions {
+				var unmarshalledTx Transaction
+				if err = json.Unmarshal(tx, &unmarshalledTx); err != nil {
+					return nil, nil, err
+				}
+
+				indices = append(indices, types.Index{
+					Index:   unmarshalledTx.Hash,
+					IndexId: utils.IndexEVMTransaction,
+				})
 			}
-
-			indices = append(indices, types.Index{
-				Index:   unmarshalledTx.Hash,
-				IndexId: utils.IndexEVMTransaction,
-			})
 		}
 
 		trustlessItems = append(trustlessItems, types.TrustlessDataItem{
@@ -294,7 +424,66 @@ func (c *EVMIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataIt
 		})
 	}
 
-	return &trustlessItems, nil
+	return &trustlessItems, &leafs, nil
+}
+
+// precomputeTransactions creates a trustless data item with its proof for every transaction of the block,
+// the transactions are indexed with these items instead of the intermediate item of the block.
+func precomputeTransactions(intermediateItem *IntermediateItem) ([]types.TrustlessDataItem, error) {
+	item := intermediateItem.Item
+	transactions := item.Value.Block.Transactions
+	if len(transactions) == 0 {
+		return nil, nil
+	}
+
+	txLeafs := make([][32]byte, 0, len(transactions))
+	for _, tx := range transactions {
+		txLeafs = append(txLeafs, utils.CalculateSHA256Hash(tx))
+	}
+
+	txTree, err := merkle.NewTree(txLeafs)
+	if err != nil {
+		return nil, err
+	}
+
+	trustlessItems := make([]types.TrustlessDataItem, 0, len(transactions))
+	for txIndex, tx := range transactions {
+		var unmarshalledTx Transaction
+		if err := json.Unmarshal(tx, &unmarshalledTx); err != nil {
+			return nil, err
+		}
+
+		txProof, err := txTree.GetProof(txIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		encodedProof, err := intermediateItem.encodeProof(txLeafs[txIndex], append(txProof, item.TransactionsProof...))
+		if err != nil {
+			return nil, err
+		}
+
+		rawTx, err := json.Marshal(PrecomputedTransaction{Transaction: tx, Key: item.Key})
+		if err != nil {
+			return nil, err
+		}
+
+		trustlessItems = append(trustlessItems, types.TrustlessDataItem{
+			PoolId:   intermediateItem.PoolId,
+			BundleId: intermediateItem.BundleId,
+			ChainId:  intermediateItem.ChainId,
+			Value:    rawTx,
+			Proof:    encodedProof,
+			Indices: []types.Index{
+				{
+					Index:   unmarshalledTx.Hash,
+					IndexId: utils.IndexEVMTransaction,
+				},
+			},
+		})
+	}
+
+	return trustlessItems, nil
 }
 
 func (*EVMIndexer) GetErrorResponse(message string, data any) any {
@@ -306,11 +495,6 @@ func (*EVMIndexer) serveTransactions(intermediateItem *IntermediateItem, query [
 	hash := query[0]
 	item := intermediateItem.Item
 
-	txLeafs := make([][32]byte, 0, len(item.Value.Block.Transactions))
-	for _, tx := range item.Value.Block.Transactions {
-		txLeafs = append(txLeafs, utils.CalculateSHA256Hash(tx))
-	}
-
 	// Iterate through all transactions and add it to trustless items to serve them individually.
 	for txIndex, tx := range item.Value.Block.Transactions {
 
@@ -319,18 +503,33 @@ func (*EVMIndexer) serveTransactions(intermediateItem *IntermediateItem, query [
 			return nil, err
 		}
 
-		if unmarshalledTx.Hash != hash {
+		if !strings.EqualFold(unmarshalledTx.Hash, hash) {
 			continue
 		}
 
-		txProof, err := merkle.GetHashesCompact(&txLeafs, txIndex)
+		// the transactions of a block are requested one by one, therefore the tree is only built once per block
+		txTree, err := transactionTrees.Get(fmt.Sprintf("%v/%v/%v/%v", intermediateItem.ChainId, intermediateItem.PoolId, intermediateItem.BundleId, item.Value.Block.Hash), func() ([][32]byte, error) {
+			txLeafs := make([][32]byte, 0, len(item.Value.Block.Transactions))
+			for _, tx := range item.Value.Block.Transactions {
+				txLeafs = append(txLeafs, utils.CalculateSHA256Hash(tx))
+			}
+			return txLeafs, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		txProof, err := txTree.GetProof(txIndex)
 		if err != nil {
 			return nil, err
 		}
 
 		txProof = append(txProof, item.TransactionsProof...)
 
-		encodedProof := utils.EncodeProof(intermediateItem.PoolId, intermediateItem.BundleId, intermediateItem.ChainId, "", "result", append(txProof, intermediateItem.BundleProof...))
+		encodedProof, err := intermediateItem.encodeProof(txTree.Leafs()[txIndex], txProof)
+		if err != nil {
+			return nil, err
+		}
 
 		rpcResponse, err := utils.WrapIntoJsonRpcResponse(tx)
 		if err != nil {
@@ -343,47 +542,94 @@ func (*EVMIndexer) serveTransactions(intermediateItem *IntermediateItem, query [
 		}, nil
 	}
 
-	return nil, fmt.Errorf("transaction not found")
+	return nil, fmt.Errorf("transaction not found: %w", types.ErrNotFound)
+}
+
+// serveTransactionReceipt serves the receipt of a single transaction.
+// The receipts of a block are only committed to as a whole with the receipts hash of the data item's leaf, this layout is part of
+// the merkle root on chain. Therefore the receipt is served together with all receipts of its block in `blockReceipts`, the proof
+// folds their hash up to the bundle root and the verifier checks that the receipt is one of them, see utils.LeafSchemeEVMReceipt.
+func (*EVMIndexer) serveTransactionReceipt(intermediateItem *IntermediateItem, hash string) (*types.InterceptionResponse, error) {
+	receipts := intermediateItem.Item.Value.Receipts
+	for _, receipt := range receipts {
+		if !strings.EqualFold(receipt.TransactionHash, hash) {
+			continue
+		}
+
+		rpcResponse, err := json.Marshal(struct {
+			JsonRPC       string    `json:"jsonrpc"`
+			ID            int       `json:"id"`
+			Result        Receipt   `json:"result"`
+			BlockReceipts []Receipt `json:"blockReceipts"`
+		}{
+			JsonRPC:       "2.0",
+			ID:            -1,
+			Result:        receipt,
+			BlockReceipts: receipts,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		encodedProof, err := intermediateItem.encodeSchemeProof(utils.CalculateSHA256Hash(receipts), intermediateItem.Item.ReceiptsProof, utils.LeafSchemeEVMReceipt, "blockReceipts")
+		if err != nil {
+			return nil, err
+		}
+
+		return &types.InterceptionResponse{
+			Data:  &rpcResponse,
+			Proof: encodedProof,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("receipt not found: %w", types.ErrNotFound)
 }
 
 func (e *EVMIndexer) InterceptRequest(get files.Get, indexId int, query []string) (*types.InterceptionResponse, error) {
+	if indexId == utils.IndexEVMLog {
+		return e.serveLogs(get, query)
+	}
+
 	if len(query) != 1 {
-		return nil, fmt.Errorf("query paramter count mismatch")
+		return nil, fmt.Errorf("query paramter count mismatch: %w", types.ErrInvalidParams)
 	}
 
-	item, err := get(indexId, query[0])
-	if err != nil {
-		return nil, err
+	if indexId == utils.IndexEVMTransaction || indexId == utils.IndexEVMTransactionReceipt {
+		return e.serveTransaction(get, indexId, query[0])
 	}
 
-	bytes, err := item.Resolve()
-	if err != nil {
-		return nil, err
+	// blocks are indexed by the decimal block number, the data item key
+	key := query[0]
+	if indexId == utils.IndexEVMValue {
+		number, err := parseBlockNumber(key)
+		if err != nil || number < 0 {
+			return nil, fmt.Errorf("invalid block number %v: %w", key, types.ErrInvalidParams)
+		}
+		key = strconv.FormatInt(number, 10)
 	}
 
-	intermediateItem := struct {
-		Value IntermediateItem `json:"value"`
-	}{}
-	err = json.Unmarshal(bytes, &intermediateItem)
+	rawItem, err := getIntermediateItem(get, indexId, key)
 	if err != nil {
 		return nil, err
 	}
 
-	rawItem := intermediateItem.Value
-
 	switch indexId {
-	case utils.IndexEVMTransaction:
-		return e.serveTransactions(&rawItem, query)
 	case utils.IndexEVMReceipt:
 		rpcResponse, err := utils.WrapIntoJsonRpcResponse(rawItem.Item.Value.Receipts)
-		encodedProof := utils.EncodeProof(rawItem.PoolId, rawItem.BundleId, rawItem.ChainId, "", "result", append(rawItem.Item.ReceiptsProof, rawItem.BundleProof...))
+		if err != nil {
+			return nil, err
+		}
+		encodedProof, err := rawItem.encodeProof(utils.CalculateSHA256Hash(rawItem.Item.Value.Receipts), rawItem.Item.ReceiptsProof)
 		return &types.InterceptionResponse{
 			Data:  &rpcResponse,
 			Proof: encodedProof,
 		}, err
-	case utils.IndexEVMBlock:
+	case utils.IndexEVMBlock, utils.IndexEVMValue:
 		rpcResponse, err := utils.WrapIntoJsonRpcResponse(rawItem.Item.Value.Block)
-		encodedProof := utils.EncodeProof(rawItem.PoolId, rawItem.BundleId, rawItem.ChainId, "", "result", append(rawItem.Item.BlockProof, rawItem.BundleProof...))
+		if err != nil {
+			return nil, err
+		}
+		encodedProof, err := rawItem.encodeProof(utils.CalculateSHA256Hash(rawItem.Item.Value.Block), rawItem.Item.BlockProof)
 		return &types.InterceptionResponse{
 			Data:  &rpcResponse,
 			Proof: encodedProof,
@@ -392,3 +638,298 @@ func (e *EVMIndexer) InterceptRequest(get files.Get, indexId int, query []string
 
 	return nil, nil
 }
+
+// serveTransaction serves a transaction or its receipt. The hash of a transaction either points to the precomputed transaction
+// or, if the transactions of the bundle were not precomputed, to the intermediate item of its block.
+func (e *EVMIndexer) serveTransaction(get files.Get, indexId int, hash string) (*types.InterceptionResponse, error) {
+	file, err := get(utils.IndexEVMTransaction, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := file.Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	// the fields of both values are distinct, therefore the file only has to be parsed once
+	item := struct {
+		Value struct {
+			PrecomputedTransaction
+			IntermediateItem
+		} `json:"value"`
+		Proof string `json:"proof"`
+	}{}
+	if err := json.Unmarshal(bytes, &item); err != nil {
+		return nil, err
+	}
+
+	if item.Value.Transaction == nil {
+		if indexId == utils.IndexEVMTransactionReceipt {
+			return e.serveTransactionReceipt(&item.Value.IntermediateItem, hash)
+		}
+		return e.serveTransactions(&item.Value.IntermediateItem, []string{hash})
+	}
+
+	if indexId == utils.IndexEVMTransactionReceipt {
+		block, err := getIntermediateItem(get, utils.IndexEVMValue, item.Value.Key)
+		if err != nil {
+			return nil, err
+		}
+		return e.serveTransactionReceipt(block, hash)
+	}
+
+	rpcResponse, err := utils.WrapIntoJsonRpcResponse(item.Value.Transaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.InterceptionResponse{
+		Data:  &rpcResponse,
+		Proof: item.Proof,
+	}, nil
+}
+
+// getIntermediateItem loads the intermediate item of a block by one of its indices
+func getIntermediateItem(get files.Get, indexId int, key string) (*IntermediateItem, error) {
+	item, err := get(indexId, key)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := item.Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	intermediateItem := struct {
+		Value IntermediateItem `json:"value"`
+	}{}
+	if err := json.Unmarshal(bytes, &intermediateItem); err != nil {
+		return nil, err
+	}
+
+	return &intermediateItem.Value, nil
+}
+
+// logFilter selects the logs of an `eth_getLogs` request
+type logFilter struct {
+	addresses map[string]bool
+	// topics contains the allowed topics of each position, an empty position matches any topic
+	topics [][]string
+}
+
+// parseLogFilter parses the address and topics of a logs request.
+// Topics are either passed as JSON array like the `eth_getLogs` filter or comma separated by position, alternatives separated with `|`.
+func parseLogFilter(address, topics string) (*logFilter, error) {
+	filter := logFilter{}
+
+	address = strings.Trim(address, "[]\" ")
+	if address != "" {
+		filter.addresses = map[string]bool{}
+		for _, a := range strings.Split(address, ",") {
+			filter.addresses[strings.ToLower(strings.Trim(a, "\" "))] = true
+		}
+	}
+
+	if strings.HasPrefix(topics, "[") {
+		var positions []json.RawMessage
+		if err := json.Unmarshal([]byte(topics), &positions); err != nil {
+			return nil, fmt.Errorf("invalid topics: %w", types.ErrInvalidParams)
+		}
+		for _, position := range positions {
+			var topic *string
+			var alternatives []string
+			if err := json.Unmarshal(position, &topic); err == nil {
+				if topic != nil {
+					alternatives = []string{*topic}
+				}
+			} else if err := json.Unmarshal(position, &alternatives); err != nil {
+				return nil, fmt.Errorf("invalid topics: %w", types.ErrInvalidParams)
+			}
+			filter.topics = append(filter.topics, alternatives)
+		}
+	} else if topics != "" {
+		for _, position := range strings.Split(topics, ",") {
+			var alternatives []string
+			if position != "" {
+				alternatives = strings.Split(position, "|")
+			}
+			filter.topics = append(filter.topics, alternatives)
+		}
+	}
+
+	return &filter, nil
+}
+
+// matches returns whether the log was emitted by one of the addresses and has the topics at their positions
+func (f *logFilter) matches(log *Log) bool {
+	if f.addresses != nil && !f.addresses[strings.ToLower(log.Address)] {
+		return false
+	}
+
+	for position, alternatives := range f.topics {
+		if len(alternatives) == 0 {
+			continue
+		}
+		if position >= len(log.Topics) {
+			return false
+		}
+		matches := false
+		for _, topic := range alternatives {
+			if strings.EqualFold(topic, log.Topics[position]) {
+				matches = true
+				break
+			}
+		}
+		if !matches {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseBlockNumber parses a decimal or a hex block number
+func parseBlockNumber(number string) (int64, error) {
+	if strings.HasPrefix(number, "0x") {
+		return strconv.ParseInt(number[2:], 16, 64)
+	}
+	return strconv.ParseInt(number, 10, 64)
+}
+
+// serveLogs serves the logs of a block or a range of blocks that match the filter of the query `blockHash, fromBlock, toBlock, address, topics`.
+// Every log is proven on its own, the proof folds the log up through the logs tree of its block, the receipts and logs root and the bundle.
+// The proofs are served in the same order as the logs.
+func (e *EVMIndexer) serveLogs(get files.Get, query []string) (*types.InterceptionResponse, error) {
+	if len(query) != 5 {
+		return nil, fmt.Errorf("query paramter count mismatch: %w", types.ErrInvalidParams)
+	}
+	blockHash, fromBlock, toBlock, address, topics := query[0], query[1], query[2], query[3], query[4]
+
+	filter, err := parseLogFilter(address, topics)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []*IntermediateItem
+	switch {
+	case blockHash != "" && (fromBlock != "" || toBlock != ""):
+		return nil, fmt.Errorf("blockHash can't be combined with fromBlock and toBlock: %w", types.ErrInvalidParams)
+	case blockHash != "":
+		block, err := getIntermediateItem(get, utils.IndexEVMBlock, blockHash)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	case fromBlock != "":
+		from, err := parseBlockNumber(fromBlock)
+		if err != nil || from < 0 {
+			return nil, fmt.Errorf("fromBlock has to be a positive block number: %w", types.ErrInvalidParams)
+		}
+		to := from
+		if toBlock != "" {
+			to, err = parseBlockNumber(toBlock)
+			if err != nil || to < from {
+				return nil, fmt.Errorf("toBlock has to be a block number greater or equal to fromBlock: %w", types.ErrInvalidParams)
+			}
+		}
+		maxRangeSize := e.maxRangeSize.Load()
+		if maxRangeSize <= 0 {
+			maxRangeSize = evmDefaultMaxRangeSize
+		}
+		if to-from+1 > maxRangeSize {
+			return nil, fmt.Errorf("block range exceeds limit of %v blocks: %w", maxRangeSize, types.ErrInvalidParams)
+		}
+
+		// a block that is not indexed fails the request, otherwise its logs would be missing silently
+		for number := from; number <= to; number++ {
+			block, err := getIntermediateItem(get, utils.IndexEVMValue, strconv.FormatInt(number, 10))
+			if err != nil {
+				return nil, fmt.Errorf("block %v: %w", number, err)
+			}
+			blocks = append(blocks, block)
+		}
+	default:
+		return nil, fmt.Errorf("either blockHash or fromBlock is required: %w", types.ErrInvalidParams)
+	}
+
+	logs := []json.RawMessage{}
+	proofs := []string{}
+	for _, block := range blocks {
+		blockLogs, blockProofs, err := block.getLogs(filter)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, blockLogs...)
+		proofs = append(proofs, blockProofs...)
+	}
+
+	rpcResponse, err := utils.WrapIntoJsonRpcResponse(logs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.InterceptionResponse{
+		Data:   &rpcResponse,
+		Proofs: proofs,
+	}, nil
+}
+
+// getLogs returns the logs of the block that match the filter together with their proofs
+func (i *IntermediateItem) getLogs(filter *logFilter) ([]json.RawMessage, []string, error) {
+	var logs []json.RawMessage
+	for _, receipt := range i.Item.Value.Receipts {
+		logs = append(logs, receipt.Logs...)
+	}
+	if len(logs) == 0 {
+		return nil, nil, nil
+	}
+
+	logTree, err := logTrees.Get(fmt.Sprintf("%v/%v/%v/%v", i.ChainId, i.PoolId, i.BundleId, i.Item.Value.Block.Hash), func() ([][32]byte, error) {
+		logLeafs := make([][32]byte, 0, len(logs))
+		for _, log := range logs {
+			logLeafs = append(logLeafs, utils.CalculateSHA256Hash(log))
+		}
+		return logLeafs, nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the receipts proof starts with the logs root, followed by the path of the receipts and logs root up to the data item's leaf
+	receiptsProof := i.Item.ReceiptsProof
+	logsRoot := logTree.Root()
+	if len(receiptsProof) == 0 || receiptsProof[0].Hash != hex.EncodeToString(logsRoot[:]) {
+		return nil, nil, fmt.Errorf("logs root of block %v does not match the indexed logs root", i.Item.Value.Block.Hash)
+	}
+	receiptsHash := utils.CalculateSHA256Hash(i.Item.Value.Receipts)
+	logsRootProof := append([]types.MerkleNode{{Left: false, Hash: hex.EncodeToString(receiptsHash[:])}}, receiptsProof[1:]...)
+
+	var matched []json.RawMessage
+	var proofs []string
+	for index, rawLog := range logs {
+		var log Log
+		if err := json.Unmarshal(rawLog, &log); err != nil {
+			return nil, nil, err
+		}
+		if !filter.matches(&log) {
+			continue
+		}
+
+		logProof, err := logTree.GetProof(index)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		encodedProof, err := i.encodeProof(logTree.Leafs()[index], append(logProof, logsRootProof...))
+		if err != nil {
+			return nil, nil, err
+		}
+
+		matched = append(matched, rawLog)
+		proofs = append(proofs, encodedProof)
+	}
+
+	return matched, proofs, nil
+}
@@ -28,20 +28,28 @@ func (eth *HeightIndexer) GetBindings() map[string]types.Endpoint {
 	}
 }
 
-func (*HeightIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, error) {
+func (*HeightIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, *[][32]byte, error) {
 	leafs := merkle.GetBundleHashes(&bundle.DataItems)
+	tree, err := merkle.NewTree(*leafs)
+	if err != nil {
+		return nil, nil, err
+	}
+	bundleRoot := tree.Root()
 	var trustlessItems []types.TrustlessDataItem
 	for index, dataitem := range bundle.DataItems {
-		proof, err := merkle.GetHashesCompact(leafs, index)
+		proof, err := tree.GetProof(index)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		raw, err := json.Marshal(dataitem)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
-		encodedProof := utils.EncodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, dataitem.Key, "value", proof)
+		encodedProof, err := encodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, bundleRoot, utils.LeafSchemeDataItem, dataitem.Key, "value", proof)
+		if err != nil {
+			return nil, nil, err
+		}
 
 		trustlessDataItem := types.TrustlessDataItem{
 			Value:    raw,
@@ -55,5 +63,5 @@ func (*HeightIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataI
 		}
 		trustlessItems = append(trustlessItems, trustlessDataItem)
 	}
-	return &trustlessItems, nil
+	return &trustlessItems, leafs, nil
 }
@@ -2,9 +2,13 @@ package helper
 
 import (
 	"crypto/sha256"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"strings"
 
+	"github.com/KYVENetwork/trustless-api/files"
 	"github.com/KYVENetwork/trustless-api/utils"
 
 	"github.com/KYVENetwork/trustless-api/merkle"
@@ -25,7 +29,8 @@ func (t *TendermintIndexer) GetBindings() map[string]types.Endpoint {
 					Description: []string{"block height"},
 				},
 			},
-			Schema: "TendermintBlock",
+			Schema:        "TendermintBlock",
+			JsonRpcMethod: "block",
 		},
 		"/block_results": {
 			QueryParameter: []types.ParameterIndex{
@@ -35,7 +40,8 @@ func (t *TendermintIndexer) GetBindings() map[string]types.Endpoint {
 					Description: []string{"block height"},
 				},
 			},
-			Schema: "TendermintBlockResults",
+			Schema:        "TendermintBlockResults",
+			JsonRpcMethod: "block_results",
 		},
 		"/block_by_hash": {
 			QueryParameter: []types.ParameterIndex{
@@ -45,14 +51,78 @@ func (t *TendermintIndexer) GetBindings() map[string]types.Endpoint {
 					Description: []string{"block hash"},
 				},
 			},
-			Schema: "TendermintBlock",
+			Schema:        "TendermintBlock",
+			JsonRpcMethod: "block_by_hash",
+		},
+		"/tx": {
+			QueryParameter: []types.ParameterIndex{
+				{
+					IndexId:     utils.IndexTendermintTx,
+					Parameter:   []string{"hash"},
+					Description: []string{"hash of a transaction (hex, with or without 0x), the transaction is served together with its block and block results to prove it"},
+				},
+			},
+			Schema:        "TendermintTx",
+			JsonRpcMethod: "tx",
+		},
+		"/tx_search": {
+			QueryParameter: []types.ParameterIndex{
+				{
+					IndexId:           utils.IndexTendermintTxSearch,
+					Parameter:         []string{"query"},
+					Description:       []string{"event query, e. g. transfer.recipient='kyve1...' AND tx.height>=5"},
+					OptionalParameter: []string{"prove", "page", "per_page", "order_by"},
+					OptionalDescription: []string{
+						"include the proof of each transaction (true or false)",
+						"page number starting at 1",
+						"number of transactions per page, at most 100",
+						"order by height, asc (default) or desc",
+					},
+				},
+			},
+			Schema:        "TendermintTxSearch",
+			JsonRpcMethod: "tx_search",
+		},
+		"/block_search": {
+			QueryParameter: []types.ParameterIndex{
+				{
+					IndexId:           utils.IndexTendermintBlockSearch,
+					Parameter:         []string{"query"},
+					Description:       []string{"event query, e. g. block.height>=5"},
+					OptionalParameter: []string{"page", "per_page", "order_by"},
+					OptionalDescription: []string{
+						"page number starting at 1",
+						"number of blocks per page, at most 100",
+						"order by height, asc or desc (default)",
+					},
+				},
+			},
+			Schema:        "TendermintBlockSearch",
+			JsonRpcMethod: "block_search",
 		},
 	}
 }
 
-func (t *TendermintIndexer) CalculateProof(dataItem *types.TendermintDataItem, leafs [][32]byte, dataItemIndex int) ([]types.MerkleNode, []types.MerkleNode, error) {
+// TendermintTx is a transaction together with its DeliverTx result, like the response of `tx` with `prove=true` of CometBFT
+type TendermintTx struct {
+	Hash     string            `json:"hash"`
+	Height   string            `json:"height"`
+	Index    uint32            `json:"index"`
+	TxResult json.RawMessage   `json:"tx_result"`
+	Tx       []byte            `json:"tx"`
+	Proof    TendermintTxProof `json:"proof"`
+}
+
+// TendermintTxProof proves that a transaction is part of the txs merkle tree of its block, the root is the `data_hash` of the block header
+type TendermintTxProof struct {
+	RootHash string             `json:"root_hash"`
+	Data     []byte             `json:"data"`
+	Proof    merkle.SimpleProof `json:"proof"`
+}
+
+func (t *TendermintIndexer) CalculateProof(dataItem *types.TendermintDataItem, tree *merkle.Tree, dataItemIndex int) ([]types.MerkleNode, []types.MerkleNode, error) {
 	// Create proof for API response.
-	proof, err := merkle.GetHashesCompact(&leafs, dataItemIndex)
+	proof, err := tree.GetProof(dataItemIndex)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -89,23 +159,87 @@ func (t *TendermintIndexer) CalculateProof(dataItem *types.TendermintDataItem, l
 	return totalBlockProof, totalBlockResultsProof, nil
 }
 
-func (t *TendermintIndexer) getBlockHash(dataItem *types.TendermintDataItem) (string, error) {
-	var blockHash types.TendermintBlock
-	err := json.Unmarshal(dataItem.Value.Block, &blockHash)
+// calculateTxProof creates the proof of the transactions of a data item, see utils.LeafSchemeTendermintTx.
+// It folds the merkle root of the block and block results up to the bundle root, like the proofs of CalculateProof
+// without their first node.
+func (t *TendermintIndexer) calculateTxProof(dataItem *types.TendermintDataItem, tree *merkle.Tree, dataItemIndex int) ([]types.MerkleNode, error) {
+	proof, err := tree.GetProof(dataItemIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	keyBytes := sha256.Sum256([]byte(dataItem.Key))
+	return append([]types.MerkleNode{{Left: false, Hash: hex.EncodeToString(keyBytes[:])}}, proof...), nil
+}
+
+func (t *TendermintIndexer) getBlock(dataItem *types.TendermintDataItem) (*types.TendermintBlock, error) {
+	var block types.TendermintBlock
+	err := json.Unmarshal(dataItem.Value.Block, &block)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-	return blockHash.BlockId.Hash, nil
+	return &block, nil
 }
 
-func (t *TendermintIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, error) {
+// getTransactions returns every transaction of the block together with its DeliverTx result and the proof of the txs merkle tree
+// of CometBFT, its root is the `data_hash` in the header of the block. If the transactions don't match the `data_hash`,
+// the block is indexed without its transactions.
+func (t *TendermintIndexer) getTransactions(dataItem *types.TendermintDataItem, block *types.TendermintBlock) ([]TendermintTx, error) {
+	txs := block.Block.Data.Txs
+	if len(txs) == 0 {
+		return nil, nil
+	}
+
+	var blockResults types.TendermintBlockResults
+	if err := json.Unmarshal(dataItem.Value.BlockResults, &blockResults); err != nil {
+		return nil, err
+	}
+
+	hashes := make([][]byte, 0, len(txs))
+	for _, tx := range txs {
+		hash := sha256.Sum256(tx)
+		hashes = append(hashes, hash[:])
+	}
+
+	root, proofs := merkle.SimpleProofsFromByteSlices(hashes)
+	rootHash := strings.ToUpper(hex.EncodeToString(root))
+	if dataHash := block.Block.Header.DataHash; dataHash != "" && !strings.EqualFold(dataHash, rootHash) {
+		logger.Warn().Str("height", dataItem.Key).Str("dataHash", dataHash).Str("txsRoot", rootHash).Msg("data hash of block does not match its transactions, skipping transactions")
+		return nil, nil
+	}
+
+	transactions := make([]TendermintTx, 0, len(txs))
+	for index, tx := range txs {
+		var txResult json.RawMessage
+		if index < len(blockResults.TxsResults) {
+			txResult = blockResults.TxsResults[index]
+		}
+
+		transactions = append(transactions, TendermintTx{
+			Hash:     strings.ToUpper(hex.EncodeToString(hashes[index])),
+			Height:   dataItem.Key,
+			Index:    uint32(index),
+			TxResult: txResult,
+			Tx:       tx,
+			Proof: TendermintTxProof{
+				RootHash: rootHash,
+				Data:     tx,
+				Proof:    proofs[index],
+			},
+		})
+	}
+
+	return transactions, nil
+}
+
+func (t *TendermintIndexer) IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, *[][32]byte, error) {
 	var dataItems []types.TendermintDataItem
 	var leafs [][32]byte
 
 	for _, item := range bundle.DataItems {
 		var tendermintValue types.TendermintValue
 		if err := json.Unmarshal(item.Value, &tendermintValue); err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		tendermintItem := types.TendermintDataItem{Key: item.Key, Value: tendermintValue}
@@ -115,18 +249,27 @@ func (t *TendermintIndexer) IndexBundle(bundle *types.Bundle) (*[]types.Trustles
 		dataItems = append(dataItems, tendermintItem)
 	}
 
+	tree, err := merkle.NewTree(leafs)
+	if err != nil {
+		return nil, nil, err
+	}
+	bundleRoot := tree.Root()
+
 	// we have 2 turstless items per normal data item
 	trustlessItems := make([]types.TrustlessDataItem, len(dataItems)*2)
 	for index, dataItem := range dataItems {
 
-		blockProof, blockResultsProof, err := t.CalculateProof(&dataItem, leafs, index)
+		blockProof, blockResultsProof, err := t.CalculateProof(&dataItem, tree, index)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		insertTurstlessDataItem := func(value *json.RawMessage, proof []types.MerkleNode, indices []types.Index, dest *types.TrustlessDataItem) error {
 
-			encodedProof := utils.EncodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, "", "result", proof)
+			encodedProof, err := encodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, bundleRoot, utils.LeafSchemeTendermint, "", "result", proof)
+			if err != nil {
+				return err
+			}
 
 			rpcResponse, err := utils.WrapIntoJsonRpcResponse(*value)
 			if err != nil {
@@ -144,10 +287,11 @@ func (t *TendermintIndexer) IndexBundle(bundle *types.Bundle) (*[]types.Trustles
 			return nil
 		}
 
-		blockHash, err := t.getBlockHash(&dataItem)
+		block, err := t.getBlock(&dataItem)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
+		blockHash := block.BlockId.Hash
 
 		// Create and append trustless data items for block and block_results
 		err = insertTurstlessDataItem(&dataItem.Value.Block, blockProof, []types.Index{
@@ -162,7 +306,7 @@ func (t *TendermintIndexer) IndexBundle(bundle *types.Bundle) (*[]types.Trustles
 		}, &trustlessItems[index])
 
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 
 		err = insertTurstlessDataItem(&dataItem.Value.BlockResults, blockResultsProof, []types.Index{
@@ -173,10 +317,67 @@ func (t *TendermintIndexer) IndexBundle(bundle *types.Bundle) (*[]types.Trustles
 		}, &trustlessItems[index+len(dataItems)])
 
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+
+		transactions, err := t.getTransactions(&dataItem, block)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		txHashes := make([]string, 0, len(transactions))
+		for _, tx := range transactions {
+			txHashes = append(txHashes, tx.Hash)
+		}
+
+		// block events are searched with block_search, the events of the transactions with tx_search
+		blockEvents, txEvents, err := getEvents(dataItem.Value.BlockResults, dataItem.Key, txHashes)
+		if err != nil {
+			return nil, nil, err
+		}
+		trustlessItems[index].Events = blockEvents
+
+		// transactions are proven with the block and block results of their data item, see utils.LeafSchemeTendermintTx
+		var txProof string
+		if len(transactions) > 0 {
+			proof, err := t.calculateTxProof(&dataItem, tree, index)
+			if err != nil {
+				return nil, nil, err
+			}
+			txProof, err = encodeProof(bundle.PoolId, bundle.BundleId, bundle.ChainId, bundleRoot, utils.LeafSchemeTendermintTx, "", "result", proof)
+			if err != nil {
+				return nil, nil, err
+			}
+		}
+
+		for txIndex, tx := range transactions {
+			var events []types.Event
+			if txIndex < len(txEvents) {
+				events = txEvents[txIndex]
+			}
+
+			rpcResponse, err := utils.WrapIntoJsonRpcResponse(tx)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			trustlessItems = append(trustlessItems, types.TrustlessDataItem{
+				Value:    rpcResponse,
+				Proof:    txProof,
+				BundleId: bundle.BundleId,
+				PoolId:   bundle.PoolId,
+				ChainId:  bundle.ChainId,
+				Indices: []types.Index{
+					{
+						Index:   tx.Hash,
+						IndexId: utils.IndexTendermintTx,
+					},
+				},
+				Events: events,
+			})
 		}
 	}
-	return &trustlessItems, nil
+	return &trustlessItems, &leafs, nil
 }
 
 func (*TendermintIndexer) tendermintDataItemToSha256(dataItem *types.TendermintDataItem) [32]byte {
@@ -201,3 +402,130 @@ func createHashesForTendermintValue(value *types.TendermintValue) [32]byte {
 func (t *TendermintIndexer) GetErrorResponse(message string, data any) any {
 	return utils.WrapIntoJsonRpcErrorResponse(message, data)
 }
+
+func (t *TendermintIndexer) InterceptRequest(get files.Get, indexId int, query []string) (*types.InterceptionResponse, error) {
+	if indexId != utils.IndexTendermintTx {
+		return nil, nil
+	}
+
+	if len(query) != 1 {
+		return nil, fmt.Errorf("query paramter count mismatch: %w", types.ErrInvalidParams)
+	}
+
+	hash, err := parseTxHash(query[0])
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := getTrustlessDataItem(get, indexId, hash)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcResponse := []byte(item.Value)
+
+	// transactions of bundles indexed before transactions were proven are served without KYVE proof
+	if item.Proof == "" {
+		return &types.InterceptionResponse{
+			Data: &rpcResponse,
+		}, nil
+	}
+
+	result, err := getJsonRpcResult(item.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	block, blockResults, err := getTxBlock(get, result)
+	if err != nil {
+		return nil, err
+	}
+
+	rpcResponse, err = json.Marshal(struct {
+		JsonRPC      string          `json:"jsonrpc"`
+		ID           int             `json:"id"`
+		Result       json.RawMessage `json:"result"`
+		Block        json.RawMessage `json:"block"`
+		BlockResults json.RawMessage `json:"block_results"`
+	}{
+		JsonRPC:      "2.0",
+		ID:           -1,
+		Result:       result,
+		Block:        block,
+		BlockResults: blockResults,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.InterceptionResponse{
+		Data:  &rpcResponse,
+		Proof: item.Proof,
+	}, nil
+}
+
+// getTxBlock returns the block and block results of a transaction. The leaf of a data item only commits to the hash of
+// the entire block and block results, therefore a transaction is served together with both to prove it, see utils.LeafSchemeTendermintTx.
+func getTxBlock(get files.Get, tx json.RawMessage) (json.RawMessage, json.RawMessage, error) {
+	var transaction TendermintTx
+	if err := json.Unmarshal(tx, &transaction); err != nil {
+		return nil, nil, err
+	}
+
+	results := make([]json.RawMessage, 2)
+	for index, indexId := range []int{utils.IndexTendermintBlock, utils.IndexTendermintBlockResults} {
+		item, err := getTrustlessDataItem(get, indexId, transaction.Height)
+		if err != nil {
+			return nil, nil, err
+		}
+		results[index], err = getJsonRpcResult(item.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return results[0], results[1], nil
+}
+
+// getTrustlessDataItem loads the trustless data item with the given index
+func getTrustlessDataItem(get files.Get, indexId int, key string) (*types.TrustlessDataItem, error) {
+	file, err := get(indexId, key)
+	if err != nil {
+		return nil, err
+	}
+
+	bytes, err := file.Resolve()
+	if err != nil {
+		return nil, err
+	}
+
+	var item types.TrustlessDataItem
+	if err := json.Unmarshal(bytes, &item); err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (t *TendermintIndexer) SearchEvents(get files.Get, search files.Search, indexId int, query []string) (*types.InterceptionResponse, error) {
+	switch indexId {
+	case utils.IndexTendermintTxSearch:
+		return searchTxs(get, search, query)
+	case utils.IndexTendermintBlockSearch:
+		return searchBlocks(search, query)
+	}
+	return nil, nil
+}
+
+// parseTxHash returns the upper case hex hash of a transaction, the hash is passed as hex with or without 0x
+// or base64 encoded like in a JSON-RPC request to CometBFT
+func parseTxHash(hash string) (string, error) {
+	raw, err := hex.DecodeString(strings.TrimPrefix(strings.TrimPrefix(hash, "0x"), "0X"))
+	if err != nil || len(raw) != sha256.Size {
+		raw, err = base64.StdEncoding.DecodeString(hash)
+	}
+	if err != nil || len(raw) != sha256.Size {
+		return "", fmt.Errorf("invalid transaction hash %v: %w", hash, types.ErrInvalidParams)
+	}
+
+	return strings.ToUpper(hex.EncodeToString(raw)), nil
+}
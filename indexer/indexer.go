@@ -8,7 +8,7 @@ import (
 
 type Indexer interface {
 
-	// IndexBundle indexes a bundle and returns an array of trustless data items.
+	// IndexBundle indexes a bundle and returns an array of trustless data items together with the leafs of the bundle's merkle tree.
 	// One trustless data item contains the actual data item and all necessary information to verify it:
 	// - proof
 	// - chainId
@@ -16,9 +16,12 @@ type Indexer interface {
 	//
 	// Also, each trustless data item has an array indices that will be stored in the database and associated with the response.
 	//
+	// The returned leafs are the ones every proof of the bundle folds up to, therefore their merkle root
+	// has to be identical to the `merkle_root` in the bundle summary on chain.
+	//
 	// NOTE: 	If you want to create compound indices, you have to separate them with dashes '-' e. g.: '<blockHeight>-<namespace>'
 	// 			the order has to be identical to the order defined in `GetBindings`
-	IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, error)
+	IndexBundle(bundle *types.Bundle) (*[]types.TrustlessDataItem, *[][32]byte, error)
 
 	// GetErrorResponse returns a wrapped error response
 	GetErrorResponse(message string, data any) any
@@ -69,6 +72,20 @@ type Indexer interface {
 	InterceptRequest(get files.Get, indexId int, query []string) (*types.InterceptionResponse, error)
 }
 
+// EventSearcher is implemented by indexers that store the events of their data items and serve searches over them,
+// e. g. `tx_search` and `block_search` of the Tendermint indexer
+type EventSearcher interface {
+	// SearchEvents serves the search of the index, nil is returned if the index is not a search.
+	// `get` resolves other data items that are needed to prove the results, e. g. the block of a transaction.
+	SearchEvents(get files.Get, search files.Search, indexId int, query []string) (*types.InterceptionResponse, error)
+}
+
+// RangeLimiter is implemented by indexers that serve ranges of blocks within a single request, e. g. `/{slug}/logs` of the EVM indexer.
+// The server sets the maximum size of a range whenever it loads the config.
+type RangeLimiter interface {
+	SetMaxRangeSize(size int64)
+}
+
 var (
 	EthBlobIndexer    = helper.EthBlobsIndexer{}
 	HeightIndexer     = helper.HeightIndexer{}
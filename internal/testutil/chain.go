@@ -0,0 +1,293 @@
+package testutil
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/KYVENetwork/trustless-api/indexer"
+	"github.com/KYVENetwork/trustless-api/merkle"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// StorageProviderId is the id of the storage provider the fake chain stores its bundles at
+const StorageProviderId = 1
+
+// Chain is a fake KYVE chain together with a storage provider.
+// It serves the REST and gRPC endpoints the chain clients use and the compressed bundles of its pools.
+// The chain implements chain.ChainClient itself, so it can be injected without going through HTTP.
+type Chain struct {
+	ChainId string
+
+	api     *httptest.Server
+	grpc    *httptest.Server
+	storage *httptest.Server
+
+	mutex   sync.Mutex
+	pools   map[int64][]types.FinalizedBundle
+	bundles map[string][]byte // compressed bundles by storage id
+}
+
+// NewChain starts a fake chain and storage provider and points the endpoints of `chainId` and
+// StorageProviderId to them. The servers are closed and the endpoints are restored when the test finishes.
+func NewChain(t testing.TB, chainId string) *Chain {
+	chain := &Chain{
+		ChainId: chainId,
+		pools:   map[int64][]types.FinalizedBundle{},
+		bundles: map[string][]byte{},
+	}
+
+	chain.api = httptest.NewServer(http.HandlerFunc(chain.serveApi))
+	chain.grpc = httptest.NewServer(h2c.NewHandler(http.HandlerFunc(chain.serveGrpc), &http2.Server{}))
+	chain.storage = httptest.NewServer(http.HandlerFunc(chain.serveStorage))
+
+	endpoints := config.Endpoints
+	config.Endpoints = config.ConfigEndpoints{
+		Storage: map[int][]string{StorageProviderId: {chain.storage.URL}},
+		Chains:  map[string][]string{chainId: {chain.api.URL}},
+	}
+
+	t.Cleanup(func() {
+		config.Endpoints = endpoints
+		chain.api.Close()
+		chain.grpc.Close()
+		chain.storage.Close()
+	})
+
+	return chain
+}
+
+// URL returns the url of the REST API of the chain
+func (c *Chain) URL() string {
+	return c.api.URL
+}
+
+// GrpcURL returns the url of the gRPC API of the chain, it is served with HTTP/2 without TLS
+func (c *Chain) GrpcURL() string {
+	return c.grpc.URL
+}
+
+// AddBundle finalizes a new bundle of the pool. The merkle root of the bundle summary is computed with `idx`,
+// like the protocol nodes of the pool do. Returns the finalized bundle.
+func (c *Chain) AddBundle(poolId int64, idx indexer.Indexer, dataItems []types.DataItem) (*types.FinalizedBundle, error) {
+	c.mutex.Lock()
+	bundleId := int64(len(c.pools[poolId]))
+	c.mutex.Unlock()
+
+	_, leafs, err := idx.IndexBundle(&types.Bundle{DataItems: dataItems, PoolId: poolId, BundleId: bundleId, ChainId: c.ChainId})
+	if err != nil {
+		return nil, err
+	}
+	merkleRoot := merkle.GetMerkleRoot(*leafs)
+
+	return c.AddBundleWithMerkleRoot(poolId, dataItems, hex.EncodeToString(merkleRoot[:]))
+}
+
+// AddFixtureBundles finalizes one bundle of the fixture for each size, the keys of the bundles are consecutive starting from `from`
+func (c *Chain) AddFixtureBundles(poolId int64, fixture Fixture, from int, sizes ...int) error {
+	for _, size := range sizes {
+		if _, err := c.AddBundle(poolId, fixture.GetIndexer(), fixture.DataItems(from, size)); err != nil {
+			return err
+		}
+		from += size
+	}
+	return nil
+}
+
+// AddBundleWithMerkleRoot finalizes a new bundle of the pool with the given merkle root in its bundle summary
+func (c *Chain) AddBundleWithMerkleRoot(poolId int64, dataItems []types.DataItem, merkleRoot string) (*types.FinalizedBundle, error) {
+	raw, err := json.Marshal(dataItems)
+	if err != nil {
+		return nil, err
+	}
+
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(raw); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	summary, err := json.Marshal(types.BundleSummary{MerkleRoot: merkleRoot})
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	bundleId := len(c.pools[poolId])
+	finalizedBundle := types.FinalizedBundle{
+		Id:                strconv.Itoa(bundleId),
+		StorageId:         fmt.Sprintf("pool-%v-bundle-%v", poolId, bundleId),
+		StorageProviderId: strconv.Itoa(StorageProviderId),
+		CompressionId:     "1",
+		DataHash:          utils.CreateSha256Checksum(compressed.Bytes()),
+		BundleSummary:     string(summary),
+	}
+	if len(dataItems) > 0 {
+		finalizedBundle.FromKey = dataItems[0].Key
+		finalizedBundle.ToKey = dataItems[len(dataItems)-1].Key
+	}
+
+	c.pools[poolId] = append(c.pools[poolId], finalizedBundle)
+	c.bundles[finalizedBundle.StorageId] = compressed.Bytes()
+
+	return &finalizedBundle, nil
+}
+
+// GetPoolInfo returns the pool like the chain does, the fake chain implements chain.ChainClient itself
+func (c *Chain) GetPoolInfo(poolId int64) (*types.PoolResponse, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	bundles, ok := c.pools[poolId]
+	if !ok {
+		return nil, fmt.Errorf("pool %v not found", poolId)
+	}
+
+	var poolResponse types.PoolResponse
+	poolResponse.Pool.Id = poolId
+	poolResponse.Pool.Data.Runtime = "@kyvejs/test"
+	poolResponse.Pool.Data.Config = "{}"
+	poolResponse.Pool.Data.TotalBundles = int64(len(bundles))
+	if len(bundles) > 0 {
+		poolResponse.Pool.Data.StartKey = bundles[0].FromKey
+		poolResponse.Pool.Data.CurrentKey = bundles[len(bundles)-1].ToKey
+	}
+
+	return &poolResponse, nil
+}
+
+func (c *Chain) GetFinalizedBundle(poolId int64, bundleId int64) (*types.FinalizedBundle, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	bundles := c.pools[poolId]
+	if bundleId < 0 || bundleId >= int64(len(bundles)) {
+		return nil, fmt.Errorf("bundle %v of pool %v not found", bundleId, poolId)
+	}
+
+	finalizedBundle := bundles[bundleId]
+	return &finalizedBundle, nil
+}
+
+func (c *Chain) GetFinalizedBundles(poolId int64, offset int64, limit int) (*types.FinalizedBundlesResponse, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	bundles := c.pools[poolId]
+	from := min(max(offset, 0), int64(len(bundles)))
+	to := min(from+int64(limit), int64(len(bundles)))
+
+	response := types.FinalizedBundlesResponse{FinalizedBundles: append([]types.FinalizedBundle{}, bundles[from:to]...)}
+	if to < int64(len(bundles)) {
+		// the key of the next bundle, like the chain stores it
+		response.Pagination.NextKey = binary.BigEndian.AppendUint64(binary.BigEndian.AppendUint64(nil, uint64(poolId)), uint64(to))
+	}
+
+	return &response, nil
+}
+
+// serveApi serves `/kyve/query/v1beta1/pool/{id}`, `/kyve/v1/bundles/{pool}` and `/kyve/v1/bundles/{pool}/{id}`
+func (c *Chain) serveApi(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+
+	switch {
+	case len(segments) == 5 && strings.Join(segments[:4], "/") == "kyve/query/v1beta1/pool":
+		poolId, err := strconv.ParseInt(segments[4], 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		poolResponse, err := c.GetPoolInfo(poolId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+
+		// the pool is served like the chain does, with 64 bit integers encoded as strings
+		data := poolResponse.Pool.Data
+		writeJson(w, map[string]any{"pool": map[string]any{
+			"id": strconv.FormatInt(poolId, 10),
+			"data": map[string]any{
+				"runtime":       data.Runtime,
+				"start_key":     data.StartKey,
+				"current_key":   data.CurrentKey,
+				"total_bundles": strconv.FormatInt(data.TotalBundles, 10),
+				"config":        data.Config,
+			},
+		}})
+	case len(segments) == 4 && strings.Join(segments[:3], "/") == "kyve/v1/bundles":
+		poolId, err := strconv.ParseInt(segments[3], 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		offset, _ := strconv.ParseInt(r.URL.Query().Get("pagination.offset"), 10, 64)
+		limit, err := strconv.Atoi(r.URL.Query().Get("pagination.limit"))
+		if err != nil {
+			limit = 100
+		}
+		finalizedBundles, err := c.GetFinalizedBundles(poolId, offset, limit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJson(w, finalizedBundles)
+	case len(segments) == 5 && strings.Join(segments[:3], "/") == "kyve/v1/bundles":
+		poolId, err := strconv.ParseInt(segments[3], 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		bundleId, err := strconv.ParseInt(segments[4], 10, 64)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		finalizedBundle, err := c.GetFinalizedBundle(poolId, bundleId)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		writeJson(w, finalizedBundle)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// serveStorage serves the compressed bundles by their storage id
+func (c *Chain) serveStorage(w http.ResponseWriter, r *http.Request) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	bundle, ok := c.bundles[strings.Trim(r.URL.Path, "/")]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	_, _ = w.Write(bundle)
+}
+
+func writeJson(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
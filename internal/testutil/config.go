@@ -0,0 +1,23 @@
+package testutil
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/spf13/viper"
+)
+
+// LoadConfig loads the default config with a local storage and a sqlite database in a temporary directory,
+// settings of previous tests are reset
+func LoadConfig(t testing.TB) {
+	viper.Reset()
+	config.LoadDefaults()
+
+	dir := t.TempDir()
+	viper.Set("storage.type", "local")
+	viper.Set("storage.path", dir)
+	viper.Set("database.type", "sqlite")
+	viper.Set("database.dbname", filepath.Join(dir, "database.db"))
+	viper.Set("log", "error")
+}
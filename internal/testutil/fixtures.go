@@ -0,0 +1,294 @@
+package testutil
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/KYVENetwork/trustless-api/indexer"
+	"github.com/KYVENetwork/trustless-api/merkle"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/types/celestia"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+)
+
+// Fixture creates the data items of a bundle for a specific indexer
+type Fixture struct {
+	// Indexer is the name of the indexer as used in the pools config
+	Indexer string
+	// DataItems returns `count` data items starting from the key `from`
+	DataItems func(from, count int) []types.DataItem
+}
+
+// Fixtures contains a fixture for every indexer
+var Fixtures = []Fixture{
+	{Indexer: "Height", DataItems: HeightDataItems},
+	{Indexer: "EthBlobs", DataItems: EthBlobsDataItems},
+	{Indexer: "Tendermint", DataItems: TendermintDataItems},
+	{Indexer: "Celestia", DataItems: CelestiaDataItems},
+	{Indexer: "EVM", DataItems: EVMDataItems},
+}
+
+// GetIndexer returns the indexer of the fixture
+func (f Fixture) GetIndexer() indexer.Indexer {
+	switch f.Indexer {
+	case "Height":
+		return &indexer.HeightIndexer
+	case "EthBlobs":
+		return &indexer.EthBlobIndexer
+	case "Tendermint":
+		return &indexer.TendermintIndexer
+	case "Celestia":
+		return &indexer.CelestiaIndexer
+	case "EVM":
+		return &indexer.EVMIndexer
+	}
+	panic(fmt.Sprintf("unknown indexer %v", f.Indexer))
+}
+
+func createDataItems(from, count int, value func(key int) any) []types.DataItem {
+	dataItems := make([]types.DataItem, 0, count)
+	for key := from; key < from+count; key++ {
+		raw, err := json.Marshal(value(key))
+		if err != nil {
+			panic(err)
+		}
+		dataItems = append(dataItems, types.DataItem{Key: fmt.Sprintf("%v", key), Value: raw})
+	}
+	return dataItems
+}
+
+// HeightDataItems creates data items of a height based pool
+func HeightDataItems(from, count int) []types.DataItem {
+	return createDataItems(from, count, func(key int) any {
+		return map[string]any{"height": key, "hash": BlockHash(key)}
+	})
+}
+
+// EthBlobsDataItems creates data items of an Ethereum blobs pool, the slot number of a block is `key + 1000`
+func EthBlobsDataItems(from, count int) []types.DataItem {
+	return createDataItems(from, count, func(key int) any {
+		return map[string]any{
+			"slot":  key + 1000,
+			"blobs": []any{map[string]any{"index": "0", "blob": fmt.Sprintf("0x%x", key)}},
+		}
+	})
+}
+
+// TendermintDataItems creates data items of a Tendermint pool, every block contains two transactions
+func TendermintDataItems(from, count int) []types.DataItem {
+	return createDataItems(from, count, func(key int) any {
+		var txs []string
+		var txHashes [][]byte
+		var txsResults []any
+		for index := 0; index < 2; index++ {
+			tx := TendermintTransaction(key, index)
+			txHash := sha256.Sum256(tx)
+			txs = append(txs, base64.StdEncoding.EncodeToString(tx))
+			txHashes = append(txHashes, txHash[:])
+			txsResults = append(txsResults, map[string]any{"code": 0, "gas_wanted": "200000", "gas_used": fmt.Sprintf("%v", 100000+index), "events": tendermintTxEvents(key, index)})
+		}
+
+		block := tendermintBlock(key, txs)
+		block["block"].(map[string]any)["header"].(map[string]any)["data_hash"] = fmt.Sprintf("%X", merkle.SimpleHashFromByteSlices(txHashes))
+		return map[string]any{
+			"block": block,
+			"block_results": map[string]any{
+				"height":                fmt.Sprintf("%v", key),
+				"txs_results":           txsResults,
+				"finalize_block_events": []any{tendermintEvent("commission", false, "validator", TendermintValidator(key))},
+			},
+		}
+	})
+}
+
+// TendermintRecipient returns the recipient of the transfer of a transaction of a Tendermint block,
+// the first transaction of every block transfers to the same recipient and so does the second one
+func TendermintRecipient(index int) string {
+	return fmt.Sprintf("kyve1recipient%v", index)
+}
+
+// TendermintValidator returns the validator of the commission event of a Tendermint block, it alternates between two validators
+func TendermintValidator(key int) string {
+	return fmt.Sprintf("kyvevaloper1validator%v", key%2)
+}
+
+// tendermintTxEvents returns the events of a transaction of a Tendermint block, only the first transaction transfers an amount
+func tendermintTxEvents(key, index int) []any {
+	transfer := []string{"recipient", TendermintRecipient(index)}
+	if index == 0 {
+		transfer = append(transfer, "amount", fmt.Sprintf("%vukyve", key))
+	}
+	return []any{
+		tendermintEvent("message", false, "action", "/cosmos.bank.v1beta1.MsgSend"),
+		tendermintEvent("transfer", false, transfer...),
+	}
+}
+
+// tendermintEvent creates an ABCI event with the attributes given as key value pairs, up to CometBFT v0.34 they are base64 encoded
+func tendermintEvent(eventType string, base64Encoded bool, attributes ...string) map[string]any {
+	encode := func(value string) string {
+		if base64Encoded {
+			return base64.StdEncoding.EncodeToString([]byte(value))
+		}
+		return value
+	}
+
+	var encoded []any
+	for index := 0; index+1 < len(attributes); index += 2 {
+		encoded = append(encoded, map[string]any{"key": encode(attributes[index]), "value": encode(attributes[index+1]), "index": true})
+	}
+	return map[string]any{"type": eventType, "attributes": encoded}
+}
+
+// TendermintTransaction returns the raw transaction with the index of a Tendermint block
+func TendermintTransaction(key, index int) []byte {
+	return []byte(fmt.Sprintf("tx %v of block %v", index, key))
+}
+
+// TendermintTransactionHash returns the upper case hex hash of a transaction of a Tendermint block, like CometBFT encodes it
+func TendermintTransactionHash(key, index int) string {
+	return fmt.Sprintf("%X", sha256.Sum256(TendermintTransaction(key, index)))
+}
+
+// CelestiaDataItems creates data items of a Celestia pool, every block contains a BlobTx with the blobs of CelestiaNamespaces
+func CelestiaDataItems(from, count int) []types.DataItem {
+	return createDataItems(from, count, func(key int) any {
+		return map[string]any{
+			"block": tendermintBlock(key, []string{celestiaBlobTx(key)}),
+			"block_results": map[string]any{
+				"height":             fmt.Sprintf("%v", key),
+				"txs_results":        []any{},
+				"begin_block_events": []any{tendermintEvent("commission", true, "validator", TendermintValidator(key))},
+			},
+		}
+	})
+}
+
+// EVMTransferTopic is the first topic of every log of the EVM data items
+const EVMTransferTopic = "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+
+// EVMLogAddress returns the address that emitted the log of the transaction with the index
+func EVMLogAddress(index int) string {
+	return fmt.Sprintf("0x%040x", index+1)
+}
+
+// EVMLogTopic returns the second topic of the logs of an EVM block
+func EVMLogTopic(key int) string {
+	return fmt.Sprintf("0x%064x", key)
+}
+
+// EVMDataItems creates data items of an EVM pool, every block contains two transactions with one log each.
+// The log of the transaction with the index is emitted by EVMLogAddress(index) and has the topics EVMTransferTopic and EVMLogTopic(key).
+func EVMDataItems(from, count int) []types.DataItem {
+	return createDataItems(from, count, func(key int) any {
+		var transactions, receipts []any
+		for index := 0; index < 2; index++ {
+			txHash := TransactionHash(key, index)
+			transactions = append(transactions, map[string]any{"hash": txHash, "blockNumber": fmt.Sprintf("0x%x", key)})
+			receipts = append(receipts, map[string]any{
+				"status":            "0x1",
+				"cumulativeGasUsed": "0x5208",
+				"transactionHash":   txHash,
+				"blockHash":         BlockHash(key),
+				"logs": []any{map[string]any{
+					"address":         EVMLogAddress(index),
+					"topics":          []string{EVMTransferTopic, EVMLogTopic(key)},
+					"blockHash":       BlockHash(key),
+					"logIndex":        fmt.Sprintf("0x%x", index),
+					"transactionHash": txHash,
+				}},
+			})
+		}
+
+		return map[string]any{
+			"block": map[string]any{
+				"hash":         BlockHash(key),
+				"parentHash":   BlockHash(key - 1),
+				"number":       key,
+				"timestamp":    1700000000 + key,
+				"transactions": transactions,
+			},
+			"receipts": receipts,
+		}
+	})
+}
+
+// BlockHash returns the block hash of the block with the given key in all fixtures
+func BlockHash(key int) string {
+	return fmt.Sprintf("0x%064x", key)
+}
+
+// TransactionHash returns the hash of a transaction of an EVM block
+func TransactionHash(key, index int) string {
+	return fmt.Sprintf("0x%062x%02x", key, index)
+}
+
+// CelestiaNamespaces are the base64 encoded namespaces of the blobs of every Celestia block
+var CelestiaNamespaces = []string{
+	base64.StdEncoding.EncodeToString(celestiaNamespace(1)),
+	base64.StdEncoding.EncodeToString(celestiaNamespace(2)),
+}
+
+// CelestiaCommitment returns the base64 encoded commitment of a blob of a Celestia block
+func CelestiaCommitment(key, index int) string {
+	return base64.StdEncoding.EncodeToString(celestiaCommitment(key, index))
+}
+
+func celestiaNamespace(id byte) []byte {
+	namespace := make([]byte, 29)
+	namespace[28] = id
+	return namespace
+}
+
+func celestiaCommitment(key, index int) []byte {
+	return []byte(fmt.Sprintf("commitment-%032d-%v", key, index))
+}
+
+func tendermintBlock(key int, txs []string) map[string]any {
+	return map[string]any{
+		"block_id": map[string]any{"hash": BlockHash(key)},
+		"block": map[string]any{
+			"header":      map[string]any{"chain_id": "test-1", "height": fmt.Sprintf("%v", key)},
+			"data":        map[string]any{"square_size": "1", "txs": txs},
+			"evidence":    map[string]any{"evidence": []any{}},
+			"last_commit": map[string]any{"height": fmt.Sprintf("%v", key-1)},
+		},
+	}
+}
+
+// celestiaBlobTx creates a base64 encoded BlobTx that pays for one blob in every namespace of CelestiaNamespaces
+func celestiaBlobTx(key int) string {
+	msgPayForBlobs := &celestia.MsgPayForBlobs{Signer: "celestia1test"}
+	blobTx := &celestia.BlobTx{TypeId: "BLOB"}
+
+	for index := range CelestiaNamespaces {
+		namespace := celestiaNamespace(byte(index + 1))
+		msgPayForBlobs.Namespaces = append(msgPayForBlobs.Namespaces, namespace)
+		msgPayForBlobs.ShareCommitments = append(msgPayForBlobs.ShareCommitments, celestiaCommitment(key, index))
+		blobTx.Blobs = append(blobTx.Blobs, &celestia.Blob{
+			NamespaceId: namespace,
+			Data:        []byte(fmt.Sprintf("blob %v of block %v", index, key)),
+		})
+	}
+
+	msg, err := proto.Marshal(msgPayForBlobs)
+	if err != nil {
+		panic(err)
+	}
+	tx, err := proto.Marshal(&celestia.Tx{Body: &celestia.TxBody{
+		Messages: []*anypb.Any{{TypeUrl: "/celestia.blob.v1.MsgPayForBlobs", Value: msg}},
+	}})
+	if err != nil {
+		panic(err)
+	}
+	blobTx.Tx = tx
+
+	raw, err := proto.Marshal(blobTx)
+	if err != nil {
+		panic(err)
+	}
+	return base64.StdEncoding.EncodeToString(raw)
+}
@@ -0,0 +1,153 @@
+package testutil
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/KYVENetwork/trustless-api/types"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// serveGrpc serves the unary gRPC methods of the KYVE query service that the chain client uses.
+// Messages are encoded by hand with the field numbers of the KYVE query protos.
+func (c *Chain) serveGrpc(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil || len(body) < 5 || uint32(len(body)-5) != binary.BigEndian.Uint32(body[1:5]) {
+		writeGrpcStatus(w, 3, "invalid request")
+		return
+	}
+	request := readFields(body[5:])
+
+	var response []byte
+	switch r.URL.Path {
+	case "/kyve.query.v1beta1.QueryPool/Pool":
+		poolResponse, err := c.GetPoolInfo(int64(request[1].varint))
+		if err != nil {
+			writeGrpcStatus(w, 5, err.Error())
+			return
+		}
+		response = protowire.AppendTag(nil, 1, protowire.BytesType)
+		response = protowire.AppendBytes(response, encodePoolResponse(poolResponse))
+	case "/kyve.query.v1beta1.QueryBundles/FinalizedBundleQuery":
+		finalizedBundle, err := c.GetFinalizedBundle(int64(request[1].varint), int64(request[2].varint))
+		if err != nil {
+			writeGrpcStatus(w, 5, err.Error())
+			return
+		}
+		response = encodeFinalizedBundle(finalizedBundle)
+	case "/kyve.query.v1beta1.QueryBundles/FinalizedBundlesQuery":
+		pagination := readFields(request[1].bytes)
+		finalizedBundles, err := c.GetFinalizedBundles(int64(request[2].varint), int64(pagination[2].varint), int(pagination[3].varint))
+		if err != nil {
+			writeGrpcStatus(w, 13, err.Error())
+			return
+		}
+		for _, finalizedBundle := range finalizedBundles.FinalizedBundles {
+			response = protowire.AppendTag(response, 1, protowire.BytesType)
+			response = protowire.AppendBytes(response, encodeFinalizedBundle(&finalizedBundle))
+		}
+		var pageResponse []byte
+		if len(finalizedBundles.Pagination.NextKey) > 0 {
+			pageResponse = protowire.AppendTag(nil, 1, protowire.BytesType)
+			pageResponse = protowire.AppendBytes(pageResponse, finalizedBundles.Pagination.NextKey)
+		}
+		response = protowire.AppendTag(response, 2, protowire.BytesType)
+		response = protowire.AppendBytes(response, pageResponse)
+	default:
+		writeGrpcStatus(w, 12, fmt.Sprintf("unknown method %v", r.URL.Path))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/grpc+proto")
+	w.Header().Set("Trailer", "Grpc-Status, Grpc-Message")
+	message := make([]byte, 5, 5+len(response))
+	binary.BigEndian.PutUint32(message[1:], uint32(len(response)))
+	_, _ = w.Write(append(message, response...))
+	w.Header().Set("Grpc-Status", "0")
+}
+
+// writeGrpcStatus writes a response without body, the status is sent in the headers
+func writeGrpcStatus(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "application/grpc+proto")
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	w.Header().Set("Grpc-Message", message)
+	w.WriteHeader(http.StatusOK)
+}
+
+type field struct {
+	varint uint64
+	bytes  []byte
+}
+
+// readFields decodes the varint and bytes fields of a message by their field number, invalid messages result in no fields
+func readFields(b []byte) map[protowire.Number]field {
+	fields := map[protowire.Number]field{}
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return fields
+		}
+		b = b[n:]
+
+		var value field
+		switch typ {
+		case protowire.VarintType:
+			value.varint, n = protowire.ConsumeVarint(b)
+		case protowire.BytesType:
+			value.bytes, n = protowire.ConsumeBytes(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return fields
+		}
+		b = b[n:]
+		fields[num] = value
+	}
+	return fields
+}
+
+func appendString(b []byte, num protowire.Number, value string) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+func appendUint(b []byte, num protowire.Number, value string) []byte {
+	v, _ := strconv.ParseUint(value, 10, 64)
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+// encodePoolResponse encodes a kyve.query.v1beta1.PoolResponse
+func encodePoolResponse(poolResponse *types.PoolResponse) []byte {
+	data := poolResponse.Pool.Data
+
+	var pool []byte
+	pool = appendUint(pool, 1, strconv.FormatInt(poolResponse.Pool.Id, 10))
+	pool = appendString(pool, 3, data.Runtime)
+	pool = appendString(pool, 5, data.Config)
+	pool = appendString(pool, 6, data.StartKey)
+	pool = appendString(pool, 7, data.CurrentKey)
+	pool = appendUint(pool, 10, strconv.FormatInt(data.TotalBundles, 10))
+
+	response := appendUint(nil, 1, strconv.FormatInt(poolResponse.Pool.Id, 10))
+	response = protowire.AppendTag(response, 2, protowire.BytesType)
+	return protowire.AppendBytes(response, pool)
+}
+
+// encodeFinalizedBundle encodes a kyve.query.v1beta1.FinalizedBundle
+func encodeFinalizedBundle(finalizedBundle *types.FinalizedBundle) []byte {
+	var b []byte
+	b = appendUint(b, 2, finalizedBundle.Id)
+	b = appendString(b, 3, finalizedBundle.StorageId)
+	b = appendString(b, 7, finalizedBundle.ToKey)
+	b = appendString(b, 8, finalizedBundle.BundleSummary)
+	b = appendString(b, 9, finalizedBundle.DataHash)
+	b = appendString(b, 11, finalizedBundle.FromKey)
+	b = appendUint(b, 12, finalizedBundle.StorageProviderId)
+	b = appendUint(b, 13, finalizedBundle.CompressionId)
+	return b
+}
@@ -0,0 +1,103 @@
+package merkle
+
+import (
+	"fmt"
+	"testing"
+)
+
+var benchmarkLeafCounts = []int{100, 1000, 10000}
+
+func BenchmarkGetMerkleRoot(b *testing.B) {
+	for _, leafCount := range benchmarkLeafCounts {
+		leafs := createLeafs(leafCount)
+		b.Run(fmt.Sprintf("leafs=%v", leafCount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				GetMerkleRoot(leafs)
+			}
+		})
+	}
+}
+
+func BenchmarkLegacyGetMerkleRoot(b *testing.B) {
+	for _, leafCount := range benchmarkLeafCounts {
+		leafs := createLeafs(leafCount)
+		b.Run(fmt.Sprintf("leafs=%v", leafCount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				legacyGetMerkleRoot(leafs)
+			}
+		})
+	}
+}
+
+func BenchmarkNewTree(b *testing.B) {
+	for _, leafCount := range benchmarkLeafCounts {
+		leafs := createLeafs(leafCount)
+		b.Run(fmt.Sprintf("leafs=%v", leafCount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := NewTree(leafs); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkTreeProofs creates the proofs of all leafs, like an indexer does for a bundle
+func BenchmarkTreeProofs(b *testing.B) {
+	for _, leafCount := range benchmarkLeafCounts {
+		leafs := createLeafs(leafCount)
+		b.Run(fmt.Sprintf("leafs=%v", leafCount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				tree, err := NewTree(leafs)
+				if err != nil {
+					b.Fatal(err)
+				}
+				for index := range leafs {
+					if _, err := tree.GetProof(index); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkLegacyProofs creates the proofs of all leafs by rebuilding the hex encoded tree for every leaf
+func BenchmarkLegacyProofs(b *testing.B) {
+	for _, leafCount := range benchmarkLeafCounts[:2] {
+		leafs := createLeafs(leafCount)
+		b.Run(fmt.Sprintf("leafs=%v", leafCount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				for index := range leafs {
+					if _, err := legacyGetHashesCompact(&leafs, index); err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMultiProof(b *testing.B) {
+	for _, leafCount := range benchmarkLeafCounts {
+		leafs := createLeafs(leafCount)
+		tree, err := NewTree(leafs)
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		// a range of 100 consecutive leafs, like a page of a range query
+		var indices []int
+		for index := leafCount / 2; index < leafCount/2+100 && index < leafCount; index++ {
+			indices = append(indices, index)
+		}
+
+		b.Run(fmt.Sprintf("leafs=%v", leafCount), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := tree.GetMultiProof(indices); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
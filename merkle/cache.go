@@ -0,0 +1,67 @@
+package merkle
+
+import (
+	"container/list"
+	"sync"
+)
+
+// TreeCache keeps the most recently used trees, so trees that are requested repeatedly
+// don't have to be rebuilt, e. g. the transaction tree of an EVM block.
+type TreeCache struct {
+	mutex    sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List // most recently used first
+}
+
+type treeCacheEntry struct {
+	key  string
+	tree *Tree
+}
+
+// NewTreeCache creates a cache that holds up to `capacity` trees
+func NewTreeCache(capacity int) *TreeCache {
+	return &TreeCache{
+		capacity: capacity,
+		entries:  map[string]*list.Element{},
+		order:    list.New(),
+	}
+}
+
+// Get returns the cached tree of the key, if the tree is not cached yet it is built from the leafs returned by `getLeafs`
+func (c *TreeCache) Get(key string, getLeafs func() ([][32]byte, error)) (*Tree, error) {
+	c.mutex.Lock()
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		c.mutex.Unlock()
+		return element.Value.(*treeCacheEntry).tree, nil
+	}
+	c.mutex.Unlock()
+
+	// the tree is built without holding the lock, concurrent misses of the same key build it twice
+	leafs, err := getLeafs()
+	if err != nil {
+		return nil, err
+	}
+	tree, err := NewTree(leafs)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if element, ok := c.entries[key]; ok {
+		c.order.MoveToFront(element)
+		return element.Value.(*treeCacheEntry).tree, nil
+	}
+
+	c.entries[key] = c.order.PushFront(&treeCacheEntry{key: key, tree: tree})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*treeCacheEntry).key)
+	}
+
+	return tree, nil
+}
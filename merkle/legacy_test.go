@@ -0,0 +1,137 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+)
+
+// The legacy algorithms build the tree with hex encoded levels and rebuild it for every proof.
+// They are kept to ensure that the tree creates byte-for-byte identical proofs.
+
+func legacyBuildMerkleTree(hashes *[][32]byte, tree *[][]string) {
+	if len(*hashes)%2 == 1 {
+		padded := make([][32]byte, len(*hashes), len(*hashes)+1)
+		copy(padded, *hashes)
+		padded = append(padded, (*hashes)[len(*hashes)-1])
+		hashes = &padded
+	}
+
+	hexHashes := utils.BytesToHex(hashes)
+	*tree = append(*tree, hexHashes)
+
+	var computedHashes = [][32]byte{}
+
+	for i := 0; i < len(*hashes); i += 2 {
+		left := (*hashes)[i]
+		right := (*hashes)[i+1]
+		combined := append(left[:], right[:]...)
+		parentHash := sha256.Sum256(combined)
+		computedHashes = append(computedHashes, parentHash)
+	}
+
+	if len(computedHashes) == 1 {
+		return
+	}
+
+	legacyBuildMerkleTree(&computedHashes, tree)
+}
+
+func legacyGetMerkleRoot(hashes [][32]byte) [32]byte {
+	if len(hashes) == 0 {
+		return [32]byte{}
+	}
+
+	var computedHashes = [][32]byte{}
+
+	for i := 0; i < len(hashes); i += 2 {
+		left := hashes[i]
+		if i+1 == len(hashes) {
+			combined := append(left[:], left[:]...)
+			computedHashes = append(computedHashes, sha256.Sum256(combined))
+			continue
+		}
+		right := hashes[i+1]
+		combined := append(left[:], right[:]...)
+		computedHashes = append(computedHashes, sha256.Sum256(combined))
+	}
+
+	if len(computedHashes) == 1 {
+		return computedHashes[0]
+	}
+	return legacyGetMerkleRoot(computedHashes)
+}
+
+func legacyGetHashesCompact(hashes *[][32]byte, leafIndex int) ([]types.MerkleNode, error) {
+	var tree [][]string
+	legacyBuildMerkleTree(hashes, &tree)
+	if leafIndex < 0 || leafIndex >= len(*hashes) {
+		return []types.MerkleNode{}, fmt.Errorf("leafIndex out of bounds")
+	}
+
+	var compactHashes []types.MerkleNode
+	var currentIndex = leafIndex
+
+	for level := 0; level < len(tree); level++ {
+		if currentIndex%2 == 0 {
+			compactHashes = append(compactHashes, types.MerkleNode{Left: true, Hash: tree[level][currentIndex+1]})
+		} else {
+			compactHashes = append(compactHashes, types.MerkleNode{Left: false, Hash: tree[level][currentIndex-1]})
+		}
+		currentIndex /= 2
+	}
+
+	return compactHashes, nil
+}
+
+func TestLegacyEquivalence(t *testing.T) {
+	for leafCount := 1; leafCount <= 130; leafCount++ {
+		leafs := createLeafs(leafCount)
+
+		if GetMerkleRoot(leafs) != legacyGetMerkleRoot(leafs) {
+			t.Fatalf("leafs %v: merkle root differs from the legacy algorithm", leafCount)
+		}
+
+		tree, err := NewTree(leafs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for index := range leafs {
+			expected, err := legacyGetHashesCompact(&leafs, index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			proof, err := tree.GetProof(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			compact, err := GetHashesCompact(&leafs, index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(proof, expected) || !reflect.DeepEqual(compact, expected) {
+				t.Fatalf("leafs %v, index %v: proof differs from the legacy algorithm", leafCount, index)
+			}
+
+			encoded, err := utils.EncodeProof(&types.Proof{Version: utils.ProofVersion1, Hashes: proof})
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedEncoded, err := utils.EncodeProof(&types.Proof{Version: utils.ProofVersion1, Hashes: expected})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if encoded != expectedEncoded {
+				t.Fatalf("leafs %v, index %v: encoded proof differs from the legacy algorithm", leafCount, index)
+			}
+		}
+	}
+}
@@ -2,67 +2,45 @@ package merkle
 
 import (
 	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"sort"
 
 	"github.com/KYVENetwork/trustless-api/types"
 	"github.com/KYVENetwork/trustless-api/utils"
 )
 
-// builds a merkle tree based of the hashes
-// each level will be inserted in `tree`,
-// where the first item are the leafs and the last element are the two leafs that make the root
-func buildMerkleTree(hashes *[][32]byte, tree *[][]string) {
-
-	// make sure we have an even number of hashes
-	if len(*hashes)%2 == 1 {
-		*hashes = append(*hashes, (*hashes)[len(*hashes)-1])
-	}
-
-	hexHashes := utils.BytesToHex(hashes)
-	*tree = append(*tree, hexHashes)
-
-	var computedHashes = [][32]byte{}
-
-	for i := 0; i < len(*hashes); i += 2 {
-		left := (*hashes)[i]
-		right := (*hashes)[i+1]
-		combined := append(left[:], right[:]...)
-		parentHash := sha256.Sum256(combined)
-		computedHashes = append(computedHashes, parentHash)
-	}
-
-	if len(computedHashes) == 1 {
-		return
-	}
-
-	buildMerkleTree(&computedHashes, tree)
+// hashPair computes the parent of two nodes of the merkle tree
+func hashPair(left, right [32]byte) [32]byte {
+	var combined [64]byte
+	copy(combined[:32], left[:])
+	copy(combined[32:], right[:])
+	return sha256.Sum256(combined[:])
 }
 
+// GetMerkleRoot computes the merkle root of the hashes, a node without a sibling is paired with itself
 func GetMerkleRoot(hashes [][32]byte) [32]byte {
 	if len(hashes) == 0 {
 		return [32]byte{}
 	}
 
-	var computedHashes = [][32]byte{}
-
-	for i := 0; i < len(hashes); i += 2 {
-		left := hashes[i]
-		if i+1 == len(hashes) {
-			combined := append(left[:], left[:]...)
-			parentHash := sha256.Sum256(combined)
-			computedHashes = append(computedHashes, parentHash)
-			continue
+	// the parents of a level are computed in place, without modifying the hashes of the caller
+	level := make([][32]byte, len(hashes))
+	copy(level, hashes)
+
+	for count := len(level); ; count = (count + 1) / 2 {
+		for i := 0; i < count; i += 2 {
+			right := level[i]
+			if i+1 < count {
+				right = level[i+1]
+			}
+			level[i/2] = hashPair(level[i], right)
 		}
-		right := hashes[i+1]
-		combined := append(left[:], right[:]...)
-		parentHash := sha256.Sum256(combined)
-		computedHashes = append(computedHashes, parentHash)
-	}
 
-	if len(computedHashes) == 1 {
-		return computedHashes[0]
+		if count <= 2 {
+			return level[0]
+		}
 	}
-	return GetMerkleRoot(computedHashes)
 }
 
 func GetBundleHashes(bundle *[]types.DataItem) *[][32]byte {
@@ -78,40 +56,153 @@ func GetBundleHashesHex(bundle *[]types.DataItem) []string {
 	return utils.BytesToHex(hashes)
 }
 
-// GetHashesCompact creates a compact merkle tree for the given leaf
-// this function will construct a merkle tree based on the hashes and
-// construct only the necessary hashes for building the merkle tree
+// GetHashesCompact creates the compact merkle tree for the given leaf,
+// it only contains the hashes that are necessary to fold the leaf up to the merkle root.
+// To create the proofs of multiple leafs of the same hashes, build the tree once with NewTree instead.
 func GetHashesCompact(hashes *[][32]byte, leafIndex int) ([]types.MerkleNode, error) {
-	var tree [][]string
-	buildMerkleTree(hashes, &tree)
-	length := len(tree)
-	if length == 0 {
-		// failed to construct merkle tree
-		return []types.MerkleNode{}, fmt.Errorf("failed to create tree")
-	}
-	if leafIndex < 0 || leafIndex >= len(*hashes) {
-		// leafIndex not within the hashes
-		return []types.MerkleNode{}, fmt.Errorf("leafIndex out of bounds")
+	tree, err := NewTree(*hashes)
+	if err != nil {
+		return []types.MerkleNode{}, err
 	}
+	return tree.GetProof(leafIndex)
+}
+
+// GetMerkleRootFromProof folds the compact merkle tree of a proof, starting from the given leaf, up to the merkle root.
+// A node marked as `Left` means that the current hash is the left child and the node's hash its right sibling.
+func GetMerkleRootFromProof(leaf [32]byte, proof []types.MerkleNode) ([32]byte, error) {
+	current := leaf
+	for _, node := range proof {
+		sibling, err := hex.DecodeString(node.Hash)
+		if err != nil || len(sibling) != 32 {
+			return [32]byte{}, fmt.Errorf("invalid merkle node hash %v", node.Hash)
+		}
 
-	var compactHashes []types.MerkleNode
-	var level = 0 // we start at level 0
-	var currentIndex = leafIndex
-
-	for level < len(tree) {
-		// even means the leaf is on the left side
-		if currentIndex%2 == 0 {
-			node := types.MerkleNode{Left: true, Hash: tree[level][currentIndex+1]}
-			compactHashes = append(compactHashes, node)
-			currentIndex /= 2
-			level++
+		var siblingHash [32]byte
+		copy(siblingHash[:], sibling)
+		if node.Left {
+			current = hashPair(current, siblingHash)
 		} else {
-			node := types.MerkleNode{Left: false, Hash: tree[level][currentIndex-1]}
-			compactHashes = append(compactHashes, node)
-			currentIndex /= 2
-			level++
+			current = hashPair(siblingHash, current)
 		}
 	}
+	return current, nil
+}
 
-	return compactHashes, nil
+// GetMerkleDepth returns the number of levels of the merkle tree of `leafCount` leafs,
+// which is the number of nodes a compact proof of a single leaf contains
+func GetMerkleDepth(leafCount int) int {
+	depth := 0
+	for {
+		leafCount = (leafCount + 1) / 2
+		depth++
+		if leafCount <= 1 {
+			return depth
+		}
+	}
+}
+
+// GetLeafIndex returns the index of the leaf a compact proof starts from, `proof` must only contain the nodes of the tree
+func GetLeafIndex(proof []types.MerkleNode) int {
+	index := 0
+	for level, node := range proof {
+		if !node.Left {
+			index |= 1 << level
+		}
+	}
+	return index
+}
+
+// GetMultiProof creates a compact proof for multiple leafs of the same tree.
+// The hashes are ordered level by level, from the leafs to the root, and within a level by their index.
+// Only hashes that can't be computed from the given leafs are part of the proof,
+// a node without a sibling is paired with itself, like in GetMerkleRoot.
+func GetMultiProof(leafs [][32]byte, indices []int) ([]string, error) {
+	tree, err := NewTree(leafs)
+	if err != nil {
+		return nil, err
+	}
+	return tree.GetMultiProof(indices)
+}
+
+// GetMerkleRootFromMultiProof folds a multi proof created by GetMultiProof up to the merkle root.
+// `leafs` maps the index of each proven leaf to its hash.
+func GetMerkleRootFromMultiProof(leafCount int, leafs map[int][32]byte, hashes []string) ([32]byte, error) {
+	if leafCount == 0 || len(leafs) == 0 {
+		return [32]byte{}, fmt.Errorf("no leafs to prove")
+	}
+
+	nodes := map[int][32]byte{}
+	for index, leaf := range leafs {
+		if index < 0 || index >= leafCount {
+			return [32]byte{}, fmt.Errorf("leafIndex out of bounds")
+		}
+		nodes[index] = leaf
+	}
+
+	next := 0
+	for {
+		parents := map[int][32]byte{}
+		for _, index := range sortedKeys(nodes) {
+			if _, ok := parents[index/2]; ok {
+				continue
+			}
+
+			sibling := index ^ 1
+			siblingHash, ok := nodes[sibling]
+			if !ok {
+				if sibling >= leafCount {
+					// the node has no sibling, it is paired with itself
+					siblingHash = nodes[index]
+				} else {
+					if next >= len(hashes) {
+						return [32]byte{}, fmt.Errorf("multi proof is missing hashes")
+					}
+					decoded, err := hex.DecodeString(hashes[next])
+					if err != nil || len(decoded) != 32 {
+						return [32]byte{}, fmt.Errorf("invalid merkle node hash %v", hashes[next])
+					}
+					copy(siblingHash[:], decoded)
+					next++
+				}
+			}
+
+			if index%2 == 0 {
+				parents[index/2] = hashPair(nodes[index], siblingHash)
+			} else {
+				parents[index/2] = hashPair(siblingHash, nodes[index])
+			}
+		}
+
+		nodes = parents
+		leafCount = (leafCount + 1) / 2
+
+		if leafCount == 1 {
+			if next != len(hashes) {
+				return [32]byte{}, fmt.Errorf("multi proof contains unused hashes")
+			}
+			return nodes[0], nil
+		}
+	}
+}
+
+// getParents computes the parent level of a level of the merkle tree
+func getParents(level [][32]byte) [][32]byte {
+	parents := make([][32]byte, 0, (len(level)+1)/2)
+	for i := 0; i < len(level); i += 2 {
+		right := level[i]
+		if i+1 < len(level) {
+			right = level[i+1]
+		}
+		parents = append(parents, hashPair(level[i], right))
+	}
+	return parents
+}
+
+func sortedKeys[T any](m map[int]T) []int {
+	keys := make([]int, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+	return keys
 }
@@ -0,0 +1,70 @@
+package merkle
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"testing"
+)
+
+func createLeafs(count int) [][32]byte {
+	leafs := make([][32]byte, count)
+	for i := range leafs {
+		leafs[i] = sha256.Sum256([]byte(fmt.Sprintf("leaf-%v", i)))
+	}
+	return leafs
+}
+
+func TestMultiProof(t *testing.T) {
+	for leafCount := 1; leafCount <= 17; leafCount++ {
+		leafs := createLeafs(leafCount)
+		expectedRoot := GetMerkleRoot(leafs)
+
+		// prove every consecutive range of leafs, like a range query does
+		for from := 0; from < leafCount; from++ {
+			for to := from; to < leafCount; to++ {
+				var indices []int
+				proven := map[int][32]byte{}
+				for i := from; i <= to; i++ {
+					indices = append(indices, i)
+					proven[i] = leafs[i]
+				}
+
+				hashes, err := GetMultiProof(leafs, indices)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				root, err := GetMerkleRootFromMultiProof(leafCount, proven, hashes)
+				if err != nil {
+					t.Fatalf("leafs %v, range %v-%v: %v", leafCount, from, to, err)
+				}
+				if root != expectedRoot {
+					t.Fatalf("leafs %v, range %v-%v: expected root %x, got %x", leafCount, from, to, expectedRoot, root)
+				}
+			}
+		}
+	}
+}
+
+func TestLeafIndexAndDepth(t *testing.T) {
+	for leafCount := 1; leafCount <= 17; leafCount++ {
+		leafs := createLeafs(leafCount)
+		for index := range leafs {
+			proof, err := GetHashesCompact(&leafs, index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(proof) != GetMerkleDepth(leafCount) {
+				t.Errorf("leafs %v: expected depth %v, got %v", leafCount, len(proof), GetMerkleDepth(leafCount))
+			}
+			if GetLeafIndex(proof) != index {
+				t.Errorf("leafs %v: expected index %v, got %v", leafCount, index, GetLeafIndex(proof))
+			}
+		}
+
+		if len(leafs) != leafCount {
+			t.Errorf("GetHashesCompact modified the leafs, expected %v leafs, got %v", leafCount, len(leafs))
+		}
+	}
+}
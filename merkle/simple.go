@@ -0,0 +1,155 @@
+package merkle
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"math/bits"
+)
+
+// The simple merkle tree is the tree CometBFT commits to the transactions of a block with (RFC 6962),
+// its root is the `data_hash` in the header of a block. Unlike the tree of a bundle, leafs and inner nodes
+// are hashed with different prefixes and the tree is split at the largest power of two smaller than the item count.
+var (
+	simpleLeafPrefix  = []byte{0}
+	simpleInnerPrefix = []byte{1}
+)
+
+// SimpleProof is the proof of a single item of a simple merkle tree, it is encoded like the proof of CometBFT.
+// The aunts are the siblings from the leaf up to the root.
+type SimpleProof struct {
+	Total    int64    `json:"total,string"`
+	Index    int64    `json:"index,string"`
+	LeafHash []byte   `json:"leaf_hash"`
+	Aunts    [][]byte `json:"aunts"`
+}
+
+func simpleLeafHash(item []byte) []byte {
+	hash := sha256.Sum256(append(simpleLeafPrefix, item...))
+	return hash[:]
+}
+
+func simpleInnerHash(left, right []byte) []byte {
+	data := make([]byte, 0, len(simpleInnerPrefix)+len(left)+len(right))
+	data = append(data, simpleInnerPrefix...)
+	data = append(data, left...)
+	data = append(data, right...)
+	hash := sha256.Sum256(data)
+	return hash[:]
+}
+
+// getSplitPoint returns the largest power of two smaller than `count`
+func getSplitPoint(count int64) int64 {
+	split := int64(1) << (bits.Len64(uint64(count)) - 1)
+	if split == count {
+		split >>= 1
+	}
+	return split
+}
+
+// SimpleHashFromByteSlices computes the root of the simple merkle tree of the items
+func SimpleHashFromByteSlices(items [][]byte) []byte {
+	switch len(items) {
+	case 0:
+		hash := sha256.Sum256(nil)
+		return hash[:]
+	case 1:
+		return simpleLeafHash(items[0])
+	}
+
+	split := getSplitPoint(int64(len(items)))
+	return simpleInnerHash(SimpleHashFromByteSlices(items[:split]), SimpleHashFromByteSlices(items[split:]))
+}
+
+// SimpleProofsFromByteSlices computes the root of the simple merkle tree of the items together with the proof of every item
+func SimpleProofsFromByteSlices(items [][]byte) ([]byte, []SimpleProof) {
+	root, aunts := simpleAunts(items)
+
+	proofs := make([]SimpleProof, len(items))
+	for index, item := range items {
+		proofs[index] = SimpleProof{
+			Total:    int64(len(items)),
+			Index:    int64(index),
+			LeafHash: simpleLeafHash(item),
+			Aunts:    aunts[index],
+		}
+	}
+
+	return root, proofs
+}
+
+// simpleAunts returns the root of the subtree of the items and the aunts of every item within the subtree
+func simpleAunts(items [][]byte) ([]byte, [][][]byte) {
+	switch len(items) {
+	case 0:
+		return SimpleHashFromByteSlices(nil), nil
+	case 1:
+		return simpleLeafHash(items[0]), [][][]byte{nil}
+	}
+
+	split := getSplitPoint(int64(len(items)))
+	left, leftAunts := simpleAunts(items[:split])
+	right, rightAunts := simpleAunts(items[split:])
+
+	aunts := make([][][]byte, 0, len(items))
+	for _, a := range leftAunts {
+		aunts = append(aunts, append(a, right))
+	}
+	for _, a := range rightAunts {
+		aunts = append(aunts, append(a, left))
+	}
+
+	return simpleInnerHash(left, right), aunts
+}
+
+// Verify checks that the proof folds the item up to the root
+func (p *SimpleProof) Verify(root []byte, item []byte) error {
+	if p.Total <= 0 || p.Index < 0 || p.Index >= p.Total {
+		return fmt.Errorf("invalid proof index %v of %v items", p.Index, p.Total)
+	}
+
+	leafHash := simpleLeafHash(item)
+	if !bytes.Equal(leafHash, p.LeafHash) {
+		return fmt.Errorf("leaf hash mismatch: expected %X, got %X", leafHash, p.LeafHash)
+	}
+
+	computed, err := computeSimpleHashFromAunts(p.Index, p.Total, leafHash, p.Aunts)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(computed, root) {
+		return fmt.Errorf("root mismatch: expected %X, got %X", root, computed)
+	}
+
+	return nil
+}
+
+// computeSimpleHashFromAunts folds the leaf hash with the aunts, the last aunt is the sibling below the root
+func computeSimpleHashFromAunts(index, total int64, leafHash []byte, aunts [][]byte) ([]byte, error) {
+	if total == 1 {
+		if len(aunts) != 0 {
+			return nil, fmt.Errorf("unexpected %v aunts", len(aunts))
+		}
+		return leafHash, nil
+	}
+
+	if len(aunts) == 0 {
+		return nil, fmt.Errorf("missing aunts")
+	}
+
+	split := getSplitPoint(total)
+	sibling := aunts[len(aunts)-1]
+	if index < split {
+		left, err := computeSimpleHashFromAunts(index, split, leafHash, aunts[:len(aunts)-1])
+		if err != nil {
+			return nil, err
+		}
+		return simpleInnerHash(left, sibling), nil
+	}
+
+	right, err := computeSimpleHashFromAunts(index-split, total-split, leafHash, aunts[:len(aunts)-1])
+	if err != nil {
+		return nil, err
+	}
+	return simpleInnerHash(sibling, right), nil
+}
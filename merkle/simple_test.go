@@ -0,0 +1,51 @@
+package merkle
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+// TestSimpleHashFromByteSlices checks the roots against the test vectors of CometBFT
+func TestSimpleHashFromByteSlices(t *testing.T) {
+	tests := []struct {
+		items    [][]byte
+		expected string
+	}{
+		{[][]byte{}, "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{[][]byte{{1, 2, 3}}, "054edec1d0211f624fed0cbca9d4f9400b0e491c43742af2c5b0abebf0c990d8"},
+		{[][]byte{{}}, "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d"},
+		{[][]byte{{1, 2, 3}, {4, 5, 6}}, "82e6cfce00453804379b53962939eaa7906b39904be0813fcadd31b100773c4b"},
+		{[][]byte{{1, 2}, {3, 4}, {5, 6}, {7, 8}, {9, 10}}, "f326493eceab4f2d9ffbc78c59432a0a005d6ea98392045c74df5d14a113be18"},
+	}
+
+	for _, test := range tests {
+		if root := hex.EncodeToString(SimpleHashFromByteSlices(test.items)); root != test.expected {
+			t.Errorf("items %v: expected root %v, got %v", test.items, test.expected, root)
+		}
+	}
+}
+
+func TestSimpleProof(t *testing.T) {
+	for itemCount := 1; itemCount <= 33; itemCount++ {
+		items := make([][]byte, itemCount)
+		for index := range items {
+			items[index] = []byte(fmt.Sprintf("item %v", index))
+		}
+
+		root, proofs := SimpleProofsFromByteSlices(items)
+		if !bytes.Equal(root, SimpleHashFromByteSlices(items)) {
+			t.Fatalf("items %v: root differs from SimpleHashFromByteSlices", itemCount)
+		}
+
+		for index, proof := range proofs {
+			if err := proof.Verify(root, items[index]); err != nil {
+				t.Fatalf("items %v, index %v: %v", itemCount, index, err)
+			}
+			if itemCount > 1 && proof.Verify(root, items[(index+1)%itemCount]) == nil {
+				t.Fatalf("items %v, index %v: verified proof of a different item", itemCount, index)
+			}
+		}
+	}
+}
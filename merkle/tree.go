@@ -0,0 +1,116 @@
+package merkle
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/KYVENetwork/trustless-api/types"
+)
+
+// Tree is a merkle tree that is built once and keeps all of its levels,
+// so proofs of many leafs can be created without rebuilding the tree.
+// The tree is constructed like in GetMerkleRoot, a node without a sibling is paired with itself.
+type Tree struct {
+	// levels[0] are the leafs, the last level only contains the root
+	levels [][][32]byte
+}
+
+// NewTree builds the merkle tree of the given leafs
+func NewTree(leafs [][32]byte) (*Tree, error) {
+	if len(leafs) == 0 {
+		return nil, fmt.Errorf("failed to create tree")
+	}
+
+	level := make([][32]byte, len(leafs))
+	copy(level, leafs)
+
+	// a single leaf is still hashed with itself, therefore the tree has at least two levels
+	levels := [][][32]byte{level}
+	for {
+		level = getParents(level)
+		levels = append(levels, level)
+		if len(level) == 1 {
+			return &Tree{levels: levels}, nil
+		}
+	}
+}
+
+// Root returns the merkle root of the tree
+func (t *Tree) Root() [32]byte {
+	return t.levels[len(t.levels)-1][0]
+}
+
+// LeafCount returns the number of leafs of the tree
+func (t *Tree) LeafCount() int {
+	return len(t.levels[0])
+}
+
+// Depth returns the number of nodes of a single proof, see GetMerkleDepth
+func (t *Tree) Depth() int {
+	return len(t.levels) - 1
+}
+
+// Leafs returns the leafs of the tree, they must not be modified
+func (t *Tree) Leafs() [][32]byte {
+	return t.levels[0]
+}
+
+// GetProof returns the compact proof of a single leaf, it is equal to the proof created by GetHashesCompact
+func (t *Tree) GetProof(leafIndex int) ([]types.MerkleNode, error) {
+	if leafIndex < 0 || leafIndex >= t.LeafCount() {
+		return []types.MerkleNode{}, fmt.Errorf("leafIndex out of bounds")
+	}
+
+	proof := make([]types.MerkleNode, 0, t.Depth())
+	index := leafIndex
+	for _, level := range t.levels[:t.Depth()] {
+		sibling := index ^ 1
+		if sibling >= len(level) {
+			sibling = index
+		}
+		proof = append(proof, types.MerkleNode{Left: index%2 == 0, Hash: hex.EncodeToString(level[sibling][:])})
+		index /= 2
+	}
+
+	return proof, nil
+}
+
+// GetMultiProof returns the compact proof of multiple leafs, see GetMultiProof.
+// Siblings that are shared between the leafs or that can be computed from the leafs are only contained once or not at all.
+func (t *Tree) GetMultiProof(indices []int) ([]string, error) {
+	known := map[int]bool{}
+	for _, index := range indices {
+		if index < 0 || index >= t.LeafCount() {
+			return nil, fmt.Errorf("leafIndex out of bounds")
+		}
+		known[index] = true
+	}
+
+	var hashes []string
+	for _, level := range t.levels[:t.Depth()] {
+		parentKnown := map[int]bool{}
+		for _, index := range sortedKeys(known) {
+			sibling := index ^ 1
+			if sibling < len(level) && !known[sibling] {
+				hashes = append(hashes, hex.EncodeToString(level[sibling][:]))
+			}
+			parentKnown[index/2] = true
+		}
+		known = parentKnown
+	}
+
+	return hashes, nil
+}
+
+// VerifyProof returns whether the proof folds the leaf up to the root of the tree
+func (t *Tree) VerifyProof(leaf [32]byte, proof []types.MerkleNode) bool {
+	root, err := GetMerkleRootFromProof(leaf, proof)
+	return err == nil && root == t.Root()
+}
+
+// VerifyMultiProof returns whether the multi proof folds the leafs up to the root of the tree,
+// `leafs` maps the index of each proven leaf to its hash
+func (t *Tree) VerifyMultiProof(leafs map[int][32]byte, hashes []string) bool {
+	root, err := GetMerkleRootFromMultiProof(t.LeafCount(), leafs, hashes)
+	return err == nil && root == t.Root()
+}
@@ -0,0 +1,130 @@
+package merkle
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTreeProof(t *testing.T) {
+	for leafCount := 1; leafCount <= 33; leafCount++ {
+		leafs := createLeafs(leafCount)
+
+		tree, err := NewTree(leafs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tree.Root() != GetMerkleRoot(leafs) {
+			t.Fatalf("leafs %v: expected root %x, got %x", leafCount, GetMerkleRoot(leafs), tree.Root())
+		}
+
+		for index, leaf := range leafs {
+			proof, err := tree.GetProof(index)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			expected, err := GetHashesCompact(&leafs, index)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(proof, expected) {
+				t.Fatalf("leafs %v, index %v: proof differs from GetHashesCompact", leafCount, index)
+			}
+
+			if !tree.VerifyProof(leaf, proof) {
+				t.Fatalf("leafs %v, index %v: failed to verify proof", leafCount, index)
+			}
+			if tree.VerifyProof(leafs[(index+1)%leafCount], proof) && leafCount > 1 {
+				t.Fatalf("leafs %v, index %v: verified proof of a different leaf", leafCount, index)
+			}
+		}
+	}
+}
+
+func TestTreeMultiProof(t *testing.T) {
+	for leafCount := 1; leafCount <= 9; leafCount++ {
+		leafs := createLeafs(leafCount)
+
+		tree, err := NewTree(leafs)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// prove every subset of the leafs
+		for subset := 1; subset < 1<<leafCount; subset++ {
+			var indices []int
+			proven := map[int][32]byte{}
+			for index := range leafs {
+				if subset&(1<<index) != 0 {
+					indices = append(indices, index)
+					proven[index] = leafs[index]
+				}
+			}
+
+			hashes, err := tree.GetMultiProof(indices)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !tree.VerifyMultiProof(proven, hashes) {
+				t.Fatalf("leafs %v, indices %v: failed to verify multi proof", leafCount, indices)
+			}
+
+			// shared siblings are only contained once
+			if len(hashes) > len(indices)*tree.Depth() {
+				t.Fatalf("leafs %v, indices %v: multi proof contains %v hashes", leafCount, indices, len(hashes))
+			}
+
+			if len(hashes) > 0 && tree.VerifyMultiProof(proven, hashes[1:]) {
+				t.Fatalf("leafs %v, indices %v: verified incomplete multi proof", leafCount, indices)
+			}
+		}
+	}
+}
+
+func TestTreeErrors(t *testing.T) {
+	if _, err := NewTree(nil); err == nil {
+		t.Error("expected error for empty tree")
+	}
+
+	tree, err := NewTree(createLeafs(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tree.GetProof(3); err == nil {
+		t.Error("expected error for leaf index out of bounds")
+	}
+	if _, err := tree.GetMultiProof([]int{0, -1}); err == nil {
+		t.Error("expected error for leaf index out of bounds")
+	}
+}
+
+func TestTreeCache(t *testing.T) {
+	cache := NewTreeCache(2)
+	builds := map[string]int{}
+	get := func(key string) *Tree {
+		tree, err := cache.Get(key, func() ([][32]byte, error) {
+			builds[key]++
+			return createLeafs(len(key)), nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tree
+	}
+
+	get("a")
+	get("bb")
+	if tree := get("a"); tree.LeafCount() != 1 {
+		t.Errorf("expected tree of key a, got %v leafs", tree.LeafCount())
+	}
+	// bb is the least recently used tree and gets evicted
+	get("ccc")
+	get("a")
+	get("bb")
+
+	if builds["a"] != 1 || builds["bb"] != 2 || builds["ccc"] != 1 {
+		t.Errorf("unexpected builds %v", builds)
+	}
+}
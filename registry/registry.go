@@ -0,0 +1,179 @@
+package registry
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	logger = utils.TrustlessApiLogger("registry")
+
+	// runtimeIndexers maps the runtime of a pool to the indexer that serves its data items.
+	// Pools of other runtimes can't be served and are skipped, e.g. state sync snapshots or
+	// block sync pools whose data items have no block results that the Tendermint leafs require.
+	runtimeIndexers = map[string]string{
+		"@kyvejs/tendermint":     "Tendermint",
+		"@kyvejs/celestia":       "Celestia",
+		"@kyvejs/evm":            "EVM",
+		"@kyvejs/ethereum-blobs": "EthBlobs",
+	}
+
+	invalidSlugCharacters = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+// Enabled returns whether pools are discovered from the source registry
+func Enabled() bool {
+	return viper.GetBool("registry.enabled")
+}
+
+// Load loads the source registry from a url or a local file
+func Load(source string) (*types.SourceRegistry, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		data, err = utils.GetFromUrlWithBackoff(source)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load source registry from %v: %w", source, err)
+	}
+
+	var registry types.SourceRegistry
+	if err := yaml.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("failed to parse source registry: %w", err)
+	}
+
+	return &registry, nil
+}
+
+// GetIndexer returns the name of the indexer for the runtime of a pool
+func GetIndexer(runtime string) (string, bool) {
+	indexer, ok := runtimeIndexers[runtime]
+	return indexer, ok
+}
+
+// GetPools returns the pools of the registry on the given chain that can be served.
+// The slug of a pool is derived from its source id, if a source has more than one pool the pool id is appended.
+// Pools that are already part of `existing` and slugs that are already taken are skipped.
+func GetPools(registry *types.SourceRegistry, chainId string, existing []config.PoolsConfig) []config.PoolsConfig {
+	pools := map[string]bool{}
+	slugs := map[string]bool{}
+	for _, pool := range existing {
+		pools[fmt.Sprintf("%v/%v", pool.ChainId, pool.PoolId)] = true
+		slugs[pool.Slug] = true
+	}
+
+	keys := make([]string, 0, len(registry.Entries))
+	for key := range registry.Entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var discovered []config.PoolsConfig
+	for _, key := range keys {
+		entry := registry.Entries[key]
+		network := getNetwork(entry, chainId)
+		if network == nil || network.Pools == nil {
+			continue
+		}
+
+		sourceId := entry.SourceID
+		if sourceId == "" {
+			sourceId = key
+		}
+
+		var sourcePools []config.PoolsConfig
+		for _, pool := range *network.Pools {
+			if pool.Id == nil {
+				continue
+			}
+			indexer, ok := GetIndexer(pool.Runtime)
+			if !ok {
+				logger.Debug().Str("source", sourceId).Int("poolId", *pool.Id).Str("runtime", pool.Runtime).Msg("skipping pool with unsupported runtime")
+				continue
+			}
+			if pools[fmt.Sprintf("%v/%v", chainId, *pool.Id)] {
+				continue
+			}
+			sourcePools = append(sourcePools, config.PoolsConfig{
+				ChainId: chainId,
+				PoolId:  int64(*pool.Id),
+				Indexer: indexer,
+			})
+		}
+		sort.Slice(sourcePools, func(i, j int) bool { return sourcePools[i].PoolId < sourcePools[j].PoolId })
+
+		slug := getSlug(sourceId)
+		if slug == "" {
+			slug = "pool"
+		}
+		for _, pool := range sourcePools {
+			pool.Slug = slug
+			if len(sourcePools) > 1 || slugs[pool.Slug] {
+				pool.Slug = fmt.Sprintf("%v-%v", slug, pool.PoolId)
+			}
+			if slugs[pool.Slug] {
+				logger.Warn().Str("slug", pool.Slug).Int64("poolId", pool.PoolId).Msg("skipping pool, slug is already taken")
+				continue
+			}
+
+			slugs[pool.Slug] = true
+			discovered = append(discovered, pool)
+		}
+	}
+
+	return discovered
+}
+
+// GetPoolsConfig returns the pools of the config together with the pools discovered from the registry if it is enabled.
+// If the registry can't be loaded, only the pools of the config are returned.
+func GetPoolsConfig() []config.PoolsConfig {
+	pools, err := getPoolsConfig()
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to discover pools, only serving the configured pools")
+		return config.GetPoolsConfig()
+	}
+	return pools
+}
+
+func getPoolsConfig() ([]config.PoolsConfig, error) {
+	pools, err := config.ParsePoolsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if !Enabled() {
+		return pools, nil
+	}
+
+	registry, err := Load(viper.GetString("registry.source"))
+	if err != nil {
+		return nil, err
+	}
+
+	return append(pools, GetPools(registry, viper.GetString("registry.chainid"), pools)...), nil
+}
+
+func getNetwork(entry types.Entry, chainId string) *types.NetworkProperties {
+	switch chainId {
+	case utils.ChainIdMainnet:
+		return entry.Networks.Kyve
+	case utils.ChainIdKaon:
+		return entry.Networks.Kaon
+	}
+	return nil
+}
+
+// getSlug derives a slug from a source id, e.g. "Cosmos Hub" becomes "cosmos-hub"
+func getSlug(sourceId string) string {
+	return strings.Trim(invalidSlugCharacters.ReplaceAllString(strings.ToLower(sourceId), "-"), "-")
+}
@@ -0,0 +1,111 @@
+package registry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/spf13/viper"
+)
+
+func TestGetPools(t *testing.T) {
+	registry, err := Load("testdata/registry.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	existing := []config.PoolsConfig{
+		{ChainId: "kyve-1", PoolId: 4, Indexer: "Tendermint", Slug: "osmosis-archive"},
+		{ChainId: "kyve-1", PoolId: 99, Indexer: "Height", Slug: "injective"},
+	}
+
+	expected := []config.PoolsConfig{
+		{ChainId: "kyve-1", PoolId: 0, Indexer: "Tendermint", Slug: "cosmoshub"},
+		{ChainId: "kyve-1", PoolId: 21, Indexer: "EthBlobs", Slug: "ethereum-mainnet-21"},
+		{ChainId: "kyve-1", PoolId: 22, Indexer: "EVM", Slug: "ethereum-mainnet-22"},
+		{ChainId: "kyve-1", PoolId: 3, Indexer: "Tendermint", Slug: "injective-3"},
+	}
+	if pools := GetPools(registry, "kyve-1", existing); !reflect.DeepEqual(pools, expected) {
+		t.Errorf("expected pools %+v, got %+v", expected, pools)
+	}
+
+	// the block sync pool is skipped, so the remaining pool of the source gets the plain slug
+	expected = []config.PoolsConfig{{ChainId: "kaon-1", PoolId: 1, Indexer: "Tendermint", Slug: "cosmoshub"}}
+	if pools := GetPools(registry, "kaon-1", nil); !reflect.DeepEqual(pools, expected) {
+		t.Errorf("expected pools %+v, got %+v", expected, pools)
+	}
+
+	if pools := GetPools(registry, "korellia-2", nil); len(pools) != 0 {
+		t.Errorf("expected no pools on a chain without registry entries, got %+v", pools)
+	}
+}
+
+func TestGetPoolsConfig(t *testing.T) {
+	data, err := os.ReadFile("testdata/registry.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	config.LoadDefaults()
+	pools := []config.PoolsConfig{{ChainId: "kyve-1", PoolId: 0, Indexer: "Tendermint", Slug: "cosmos"}}
+	viper.Set("pools", pools)
+	viper.Set("registry.source", server.URL)
+	defer viper.Reset()
+
+	if configured := GetPoolsConfig(); !reflect.DeepEqual(configured, pools) {
+		t.Errorf("expected only the configured pools with the registry disabled, got %+v", configured)
+	}
+
+	viper.Set("registry.enabled", true)
+	discovered := GetPoolsConfig()
+	if len(discovered) != 5 || !reflect.DeepEqual(discovered[0], pools[0]) {
+		t.Fatalf("expected the configured pool and 4 discovered pools, got %+v", discovered)
+	}
+	// the pool of the config takes precedence
+	for _, pool := range discovered[1:] {
+		if pool.PoolId == 0 {
+			t.Errorf("expected configured pool not to be discovered again, got %+v", pool)
+		}
+	}
+
+	viper.Set("registry.source", "testdata/missing.yml")
+	if configured := GetPoolsConfig(); !reflect.DeepEqual(configured, pools) {
+		t.Errorf("expected the configured pools if the registry can't be loaded, got %+v", configured)
+	}
+}
+
+func TestGetIndexer(t *testing.T) {
+	for runtime, expected := range map[string]string{
+		"@kyvejs/tendermint":       "Tendermint",
+		"@kyvejs/tendermint-bsync": "",
+		"@kyvejs/tendermint-ssync": "",
+		"@kyvejs/celestia":         "Celestia",
+		"@kyvejs/evm":              "EVM",
+		"@kyvejs/ethereum-blobs":   "EthBlobs",
+	} {
+		indexer, ok := GetIndexer(runtime)
+		if indexer != expected || ok != (expected != "") {
+			t.Errorf("runtime %v: expected indexer %q, got %q", runtime, expected, indexer)
+		}
+	}
+}
+
+func TestGetSlug(t *testing.T) {
+	for sourceId, slug := range map[string]string{
+		"cosmoshub":         "cosmoshub",
+		"Cosmos Hub":        "cosmos-hub",
+		" Ethereum (Blobs)": "ethereum-blobs",
+		"dydx_v4":           "dydx-v4",
+	} {
+		if got := getSlug(sourceId); got != slug {
+			t.Errorf("source id %q: expected slug %v, got %v", sourceId, slug, got)
+		}
+	}
+}
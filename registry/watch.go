@@ -0,0 +1,131 @@
+package registry
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/KYVENetwork/trustless-api/utils"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// watcher notifies the listeners whenever the pools of the config and the registry change
+type watcher struct {
+	mutex     sync.Mutex
+	started   bool
+	current   []config.PoolsConfig
+	listeners []func(pools []config.PoolsConfig)
+	// stop stops refreshing the pools of the registry
+	stop chan struct{}
+}
+
+var poolWatcher = &watcher{}
+
+// Watch calls `onChange` with all pools whenever they differ from the `current` pools or the config file was read again.
+// The pools are reloaded when the config file changes, every `registry.interval` seconds if the registry is enabled
+// and when a reload is triggered with Reload. Listeners read the other settings of the config during `onChange`,
+// so they are always called after the config file was read, even if the pools are unchanged.
+func Watch(current []config.PoolsConfig, onChange func(pools []config.PoolsConfig)) {
+	poolWatcher.watch(current, onChange)
+}
+
+// Reload reads the config file again and notifies the watchers.
+// If the config can't be read or is invalid, the current config and pools are kept and the error is returned, see config.ReloadConfig.
+func Reload() error {
+	if err := config.ReloadConfig(); err != nil {
+		return err
+	}
+
+	return poolWatcher.refresh(true)
+}
+
+func (w *watcher) watch(current []config.PoolsConfig, onChange func(pools []config.PoolsConfig)) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.listeners = append(w.listeners, onChange)
+	if w.started {
+		return
+	}
+	w.started = true
+	w.current = current
+
+	if viper.ConfigFileUsed() != "" {
+		viper.OnConfigChange(func(e fsnotify.Event) {
+			logger.Info().Str("file", e.Name).Msg("config file changed, reloading pools")
+			// viper has already read the changed file, it is read and validated again so an invalid config is rejected
+			if err := Reload(); err != nil {
+				logger.Error().Err(err).Msg("failed to reload config, keeping the current config and pools")
+			}
+		})
+		viper.WatchConfig()
+	}
+
+	interval := viper.GetInt64("registry.interval")
+	if interval <= 0 {
+		logger.Warn().Int64("interval", interval).Msg(fmt.Sprintf("invalid registry interval, refreshing the registry every %v seconds", utils.DefaultRegistryInterval))
+		interval = utils.DefaultRegistryInterval
+	}
+
+	ticker := time.NewTicker(time.Duration(interval) * time.Second)
+	stop := make(chan struct{})
+	w.stop = stop
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			if !Enabled() {
+				continue
+			}
+			if err := w.refresh(false); err != nil {
+				logger.Error().Err(err).Msg("failed to refresh pools from registry")
+			}
+		}
+	}()
+}
+
+// StopWatching stops refreshing the pools of the registry on shutdown, the listeners are no longer notified about changes of the registry
+func StopWatching() {
+	poolWatcher.mutex.Lock()
+	defer poolWatcher.mutex.Unlock()
+
+	if poolWatcher.stop != nil {
+		close(poolWatcher.stop)
+		poolWatcher.stop = nil
+	}
+}
+
+// refresh loads the pools and notifies the listeners if they have changed or `force` is set
+func (w *watcher) refresh(force bool) error {
+	pools, err := getPoolsConfig()
+	if err != nil {
+		return err
+	}
+
+	// the listeners are notified while locked, so they always receive the pools in order
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	changed := !reflect.DeepEqual(pools, w.current)
+	if !changed && !force {
+		return nil
+	}
+	w.current = pools
+
+	if changed {
+		logger.Info().Int("pools", len(pools)).Msg("pools changed")
+	}
+	for _, listener := range w.listeners {
+		listener(pools)
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/spf13/viper"
+)
+
+func TestReload(t *testing.T) {
+	config.LoadDefaults()
+	defer viper.Reset()
+
+	file := filepath.Join(t.TempDir(), "config.yml")
+	writeConfig := func(content string) {
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig("pools:\n  - {chainid: kyve-1, poolid: 1, indexer: Height, slug: height}\n")
+	if err := config.ReadConfig(file); err != nil {
+		t.Fatal(err)
+	}
+
+	current := config.GetPoolsConfig()
+	var notified [][]config.PoolsConfig
+	previous := poolWatcher
+	poolWatcher = &watcher{current: current, listeners: []func([]config.PoolsConfig){func(pools []config.PoolsConfig) {
+		notified = append(notified, pools)
+	}}}
+	defer func() { poolWatcher = previous }()
+
+	// the registry interval only notifies about changed pools
+	if err := poolWatcher.refresh(false); err != nil || len(notified) != 0 {
+		t.Fatalf("expected no notification for unchanged pools, got %v notifications, err %v", len(notified), err)
+	}
+	// a reload always notifies, so the listeners read the other settings of the config again
+	if err := Reload(); err != nil || len(notified) != 1 || !reflect.DeepEqual(notified[0], current) {
+		t.Fatalf("expected a notification with the unchanged pools, got %+v, err %v", notified, err)
+	}
+
+	writeConfig("pools:\n  - {chainid: kyve-1, poolid: 1, indexer: Height, slug: height, excludeProof: true}\n  - {chainid: kyve-1, poolid: 2, indexer: Tendermint, slug: tendermint}\n")
+	if err := Reload(); err != nil {
+		t.Fatal(err)
+	}
+	expected := []config.PoolsConfig{
+		{ChainId: "kyve-1", PoolId: 1, Indexer: "Height", Slug: "height", ExcludeProof: true},
+		{ChainId: "kyve-1", PoolId: 2, Indexer: "Tendermint", Slug: "tendermint"},
+	}
+	if len(notified) != 2 || !reflect.DeepEqual(notified[1], expected) {
+		t.Fatalf("expected a notification with pools %+v, got %+v", expected, notified)
+	}
+
+	// invalid pools are rejected and the current pools are kept
+	writeConfig("pools:\n  - {chainid: kyve-1, poolid: 3, indexer: Unknown, slug: unknown}\n")
+	if err := Reload(); err == nil {
+		t.Error("expected error for an unknown indexer")
+	}
+	if len(notified) != 2 || !reflect.DeepEqual(poolWatcher.current, expected) {
+		t.Errorf("expected the current pools to be kept, got %+v", poolWatcher.current)
+	}
+
+	// the entire config is validated, an invalid storage or database type is rejected and the current config is restored
+	for _, invalid := range []string{"storage: {type: ftp}\n", "database: {type: mysql}\n"} {
+		writeConfig("pools:\n  - {chainid: kyve-1, poolid: 4, indexer: Height, slug: other}\n" + invalid)
+		if err := Reload(); !errors.Is(err, config.ErrInvalidConfig) {
+			t.Errorf("%q: expected invalid config error, got %v", invalid, err)
+		}
+		if len(notified) != 2 || viper.GetString("storage.type") != "local" || viper.GetString("database.type") != "sqlite" {
+			t.Errorf("%q: expected the current config to be kept, got storage %v and database %v", invalid, viper.GetString("storage.type"), viper.GetString("database.type"))
+		}
+		if pools, err := config.ParsePoolsConfig(); err != nil || !reflect.DeepEqual(pools, expected) {
+			t.Errorf("%q: expected the current pools in the config, got %+v, %v", invalid, pools, err)
+		}
+	}
+}
+
+func TestWatchRegistry(t *testing.T) {
+	config.LoadDefaults()
+	defer viper.Reset()
+
+	var served atomic.Value
+	served.Store("osmosis:\n  networks:\n    kyve-1:\n      pools:\n        - {id: 1, runtime: '@kyvejs/tendermint'}\n")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(served.Load().(string)))
+	}))
+	defer server.Close()
+
+	viper.Set("registry.enabled", true)
+	viper.Set("registry.source", server.URL)
+	viper.Set("registry.interval", 1)
+
+	var mutex sync.Mutex
+	var notified [][]config.PoolsConfig
+	getNotified := func() int {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(notified)
+	}
+
+	previous := poolWatcher
+	poolWatcher = &watcher{}
+	defer func() { poolWatcher = previous }()
+
+	Watch(GetPoolsConfig(), func(pools []config.PoolsConfig) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		notified = append(notified, pools)
+	})
+
+	served.Store("osmosis:\n  networks:\n    kyve-1:\n      pools:\n        - {id: 2, runtime: '@kyvejs/tendermint'}\n")
+	for start := time.Now(); getNotified() == 0; time.Sleep(50 * time.Millisecond) {
+		if time.Since(start) > 5*time.Second {
+			t.Fatal("expected a notification after the registry changed")
+		}
+	}
+	mutex.Lock()
+	if expected := []config.PoolsConfig{{ChainId: "kyve-1", PoolId: 2, Indexer: "Tendermint", Slug: "osmosis"}}; !reflect.DeepEqual(notified[0], expected) {
+		t.Errorf("expected pools %+v, got %+v", expected, notified[0])
+	}
+	mutex.Unlock()
+
+	// the registry is no longer refreshed once watching is stopped
+	StopWatching()
+	served.Store("osmosis:\n  networks:\n    kyve-1:\n      pools:\n        - {id: 3, runtime: '@kyvejs/tendermint'}\n")
+	time.Sleep(1500 * time.Millisecond)
+	if count := getNotified(); count != 1 {
+		t.Errorf("expected no notifications after watching is stopped, got %v", count)
+	}
+}
+
+func TestWatchInvalidInterval(t *testing.T) {
+	config.LoadDefaults()
+	defer viper.Reset()
+	viper.Set("registry.interval", 0)
+
+	previous := poolWatcher
+	poolWatcher = &watcher{}
+	defer func() { poolWatcher = previous }()
+
+	// an invalid interval falls back to the default instead of never refreshing the registry
+	Watch(nil, func([]config.PoolsConfig) {})
+	StopWatching()
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"crypto/subtle"
+	"errors"
+	"net/http"
+
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/KYVENetwork/trustless-api/registry"
+	"github.com/gin-gonic/gin"
+)
+
+// reload reloads the pools of the config file and the registry, the request has to be authorized with the admin token
+func (apiServer *ApiServer) reload(c *gin.Context, token string) {
+	c.Header("Cache-Control", "no-cache")
+
+	if subtle.ConstantTimeCompare([]byte(c.GetHeader("Authorization")), []byte("Bearer "+token)) != 1 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized", "message": "invalid admin token"})
+		return
+	}
+
+	if err := registry.Reload(); err != nil {
+		logger.Error().Str("err", err.Error()).Msg("failed to reload pools")
+		if errors.Is(err, config.ErrInvalidConfig) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid config", "message": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal error", "message": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
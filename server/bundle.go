@@ -0,0 +1,74 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bundleResponse is the metadata of an indexed bundle, it contains everything a verifier needs
+// to cross-check the merkle root a proof folds up to against a KYVE node
+type bundleResponse struct {
+	ChainId           string          `json:"chainId"`
+	PoolId            int64           `json:"poolId"`
+	BundleId          int64           `json:"bundleId"`
+	StorageId         string          `json:"storageId"`
+	StorageProviderId string          `json:"storageProviderId"`
+	DataHash          string          `json:"dataHash"`
+	FromKey           string          `json:"fromKey"`
+	ToKey             string          `json:"toKey"`
+	BundleSummary     json.RawMessage `json:"bundleSummary"`
+	MerkleRoot        string          `json:"merkleRoot"`
+	LeafHashes        []string        `json:"leafHashes"`
+	IndexedAt         time.Time       `json:"indexedAt"`
+}
+
+// getBundle serves the metadata of an indexed bundle, e. g. `/{slug}/bundle?bundle_id=42`
+func (apiServer *ApiServer) getBundle(c *gin.Context, pool ServePool) {
+	bundleId, err := strconv.ParseInt(c.Query("bundle_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid params", "message": "bundle_id has to be a number"})
+		return
+	}
+
+	bundle, err := pool.Adapter.GetBundle(bundleId)
+	if err != nil {
+		status, message := getErrorStatus(err)
+		c.JSON(status, gin.H{"error": message, "message": err.Error()})
+		return
+	}
+
+	response := bundleResponse{
+		ChainId:           pool.Config.ChainId,
+		PoolId:            pool.Config.PoolId,
+		BundleId:          bundle.BundleID,
+		StorageId:         bundle.StorageID,
+		StorageProviderId: bundle.StorageProviderID,
+		DataHash:          bundle.DataHash,
+		FromKey:           bundle.FromKey,
+		ToKey:             bundle.ToKey,
+		MerkleRoot:        bundle.MerkleRoot,
+		LeafHashes:        []string{},
+		IndexedAt:         bundle.IndexedAt,
+	}
+
+	// the summary is served as it is stored on chain, as JSON if possible
+	if json.Valid([]byte(bundle.BundleSummary)) {
+		response.BundleSummary = json.RawMessage(bundle.BundleSummary)
+	} else {
+		response.BundleSummary, _ = json.Marshal(bundle.BundleSummary)
+	}
+
+	if bundle.LeafHashes != "" {
+		if err := json.Unmarshal([]byte(bundle.LeafHashes), &response.LeafHashes); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal error", "message": fmt.Sprintf("invalid leaf hashes: %v", err)})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
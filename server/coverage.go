@@ -0,0 +1,68 @@
+package server
+
+import (
+	"strconv"
+
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+)
+
+// getMissingDataItem describes why the data item of a query is not indexed, together with the current tip of the pool.
+// Only queries of key indices (e. g. block height) can be classified, others are always reported as not found.
+func (apiServer *ApiServer) getMissingDataItem(pool ServePool, indexId int, query []string) (*types.MissingDataItem, error) {
+	coverage, err := pool.Adapter.GetCoverage(pool.Config.BundleStartId)
+	if err != nil {
+		return nil, err
+	}
+
+	missing := types.MissingDataItem{
+		Reason:      utils.MissingNotFound,
+		TipBundleId: coverage.LatestBundleId,
+		TipKey:      coverage.HighestKey,
+	}
+
+	if utils.IsKeyIndex(indexId) && len(query) > 0 {
+		missing.Reason = classifyMissingKey(coverage, query[0])
+	}
+
+	missing.Retry = missing.Reason == utils.MissingNotYetIndexed || missing.Reason == utils.MissingGap
+	return &missing, nil
+}
+
+// classifyMissingKey returns the reason why the key is not part of the coverage
+func classifyMissingKey(coverage *types.Coverage, key string) string {
+	if coverage.LatestBundleId < 0 {
+		return utils.MissingNotYetIndexed
+	}
+
+	k, err := strconv.ParseInt(key, 10, 64)
+	if err != nil {
+		return utils.MissingNotFound
+	}
+
+	// keys are compared numerically, an empty key of a gap means the gap is unbounded on that side
+	isBefore := func(bound string) bool {
+		value, err := strconv.ParseInt(bound, 10, 64)
+		return err != nil || k < value
+	}
+	isAfter := func(bound string) bool {
+		value, err := strconv.ParseInt(bound, 10, 64)
+		return err != nil || k > value
+	}
+
+	for _, gap := range coverage.Gaps {
+		if isAfter(gap.AfterKey) && isBefore(gap.BeforeKey) {
+			return utils.MissingGap
+		}
+	}
+
+	if lowest, err := strconv.ParseInt(coverage.LowestKey, 10, 64); err == nil && k < lowest {
+		return utils.MissingOutOfRange
+	}
+
+	if highest, err := strconv.ParseInt(coverage.HighestKey, 10, 64); err == nil && k > highest {
+		return utils.MissingNotYetIndexed
+	}
+
+	return utils.MissingNotFound
+}
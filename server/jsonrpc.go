@@ -0,0 +1,242 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/KYVENetwork/trustless-api/indexer"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+// jsonRpcBatchThreads is the number of requests of a batch that are resolved concurrently
+const jsonRpcBatchThreads = 8
+
+type jsonRpcRequest struct {
+	JsonRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"`
+}
+
+// getJsonRpcMethods returns all JSON-RPC methods an indexer serves together with their endpoint
+func getJsonRpcMethods(idx indexer.Indexer) map[string]types.Endpoint {
+	methods := map[string]types.Endpoint{}
+	for _, endpoint := range idx.GetBindings() {
+		if endpoint.JsonRpcMethod != "" {
+			methods[endpoint.JsonRpcMethod] = endpoint
+		}
+	}
+	return methods
+}
+
+// serveJsonRpc serves a native JSON-RPC 2.0 request, e. g. `{"jsonrpc":"2.0","method":"block","params":{"height":"1"},"id":1}`
+// The request is dispatched to the endpoint that serves the method and the response carries the id of the request.
+// Batch requests of at most `maxBatchSize` requests are served with an array of responses, see serveJsonRpcBatch.
+func (apiServer *ApiServer) serveJsonRpc(c *gin.Context, pool ServePool, methods map[string]types.Endpoint, maxBatchSize int) {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusOK, utils.NewJsonRpcErrorResponse(nil, utils.JsonRpcParseError, "Parse error", err.Error()))
+		return
+	}
+
+	if body = bytes.TrimSpace(body); len(body) > 0 && body[0] == '[' {
+		apiServer.serveJsonRpcBatch(c, pool, methods, body, maxBatchSize)
+		return
+	}
+
+	var request jsonRpcRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		c.JSON(http.StatusOK, utils.NewJsonRpcErrorResponse(nil, utils.JsonRpcParseError, "Parse error", err.Error()))
+		return
+	}
+
+	options := apiServer.getProofOptions(c, pool.ExcludeProof)
+	response, proof := apiServer.handleJsonRpcRequest(pool, methods, &request, options)
+	apiServer.setProofHeader(c, proof, options)
+	c.Data(http.StatusOK, "application/json", response)
+}
+
+// serveJsonRpcBatch serves a JSON-RPC 2.0 batch request.
+// Each request of the batch is resolved independently and the responses are returned in the same order,
+// the proof of each response is attached inline with the `proof` field, see attachProof.
+func (apiServer *ApiServer) serveJsonRpcBatch(c *gin.Context, pool ServePool, methods map[string]types.Endpoint, body []byte, maxBatchSize int) {
+	var requests []jsonRpcRequest
+	if err := json.Unmarshal(body, &requests); err != nil {
+		c.JSON(http.StatusOK, utils.NewJsonRpcErrorResponse(nil, utils.JsonRpcParseError, "Parse error", err.Error()))
+		return
+	}
+
+	if len(requests) == 0 {
+		c.JSON(http.StatusOK, utils.NewJsonRpcErrorResponse(nil, utils.JsonRpcInvalidRequest, "Invalid request", "empty batch"))
+		return
+	}
+
+	if len(requests) > maxBatchSize {
+		c.JSON(http.StatusOK, utils.NewJsonRpcErrorResponse(nil, utils.JsonRpcInvalidRequest, "Invalid request", fmt.Sprintf("batch size exceeds limit of %v", maxBatchSize)))
+		return
+	}
+
+	responses := make([]json.RawMessage, len(requests))
+
+	// the requests are resolved concurrently, therefore the context is only read here
+	options := apiServer.getProofOptions(c, pool.ExcludeProof)
+
+	var g errgroup.Group
+	g.SetLimit(jsonRpcBatchThreads)
+	for index := range requests {
+		localIndex := index
+		g.Go(func() error {
+			response, proof := apiServer.handleJsonRpcRequest(pool, methods, &requests[localIndex], options)
+
+			proof, _ = options.transcode(proof)
+			response, err := attachProof(response, proof)
+			if err != nil {
+				return err
+			}
+			responses[localIndex] = response
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		c.JSON(http.StatusOK, utils.NewJsonRpcErrorResponse(nil, utils.JsonRpcInternalError, "Internal error", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, responses)
+}
+
+// attachProof adds the proof of a response of a batch as `proof` field, since one header can't hold the proofs of all responses.
+// The other fields of the response are kept, e. g. the block and block results a transaction is proven with.
+func attachProof(response json.RawMessage, proof string) (json.RawMessage, error) {
+	if proof == "" {
+		return response, nil
+	}
+
+	// the response is always a valid JSON-RPC response that we created ourselves
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(response, &fields); err != nil {
+		return nil, err
+	}
+
+	encodedProof, err := json.Marshal(proof)
+	if err != nil {
+		return nil, err
+	}
+	fields["proof"] = encodedProof
+	return json.Marshal(fields)
+}
+
+// handleJsonRpcRequest resolves a single JSON-RPC request and returns the encoded response together with the proof of its result.
+// The proofs of results with multiple items are attached to the response, see attachProofs.
+// Errors are returned as JSON-RPC error responses without a proof.
+func (apiServer *ApiServer) handleJsonRpcRequest(pool ServePool, methods map[string]types.Endpoint, request *jsonRpcRequest, options proofOptions) (json.RawMessage, string) {
+	errorResponse := func(code int, message string, data any) (json.RawMessage, string) {
+		response, _ := json.Marshal(utils.NewJsonRpcErrorResponse(request.ID, code, message, data))
+		return response, ""
+	}
+
+	if request.Method == "" {
+		return errorResponse(utils.JsonRpcInvalidRequest, "Invalid request", "missing method")
+	}
+
+	endpoint, ok := methods[request.Method]
+	if !ok {
+		return errorResponse(utils.JsonRpcMethodNotFound, "Method not found", request.Method)
+	}
+
+	getValue, err := parseJsonRpcParams(request.Params)
+	if err != nil {
+		return errorResponse(utils.JsonRpcInvalidParams, "Invalid params", err.Error())
+	}
+
+	indexId, query, err := apiServer.findSelectedParameter(&endpoint.QueryParameter, getValue)
+	if err != nil {
+		return errorResponse(utils.JsonRpcInvalidParams, "Invalid params", nil)
+	}
+
+	response, err := apiServer.resolveIndex(pool, query, indexId)
+	if err != nil {
+		status, message := getErrorStatus(err)
+		if status == http.StatusBadRequest {
+			return errorResponse(utils.JsonRpcInvalidParams, message, err.Error())
+		}
+		return errorResponse(utils.JsonRpcInternalError, message, apiServer.getErrorData(pool, indexId, query, err))
+	}
+
+	data, err := utils.SetJsonRpcId(*response.Data, request.ID)
+	if err != nil {
+		return errorResponse(utils.JsonRpcInternalError, "Internal error", err.Error())
+	}
+
+	data, err = apiServer.attachProofs(data, response.Proofs, options)
+	if err != nil {
+		return errorResponse(utils.JsonRpcInternalError, "Internal error", err.Error())
+	}
+
+	return data, response.Proof
+}
+
+// parseJsonRpcParams returns a function to look up the value of a parameter.
+// Params can either be passed by name `{"height": "1"}` or `[{"height": "1"}]`, or by position `["0x..."]`.
+func parseJsonRpcParams(params json.RawMessage) (func(position int, name string) string, error) {
+	params = bytes.TrimSpace(params)
+
+	if len(params) == 0 || bytes.Equal(params, []byte("null")) {
+		return func(int, string) string { return "" }, nil
+	}
+
+	switch params[0] {
+	case '{':
+		var named map[string]json.RawMessage
+		if err := json.Unmarshal(params, &named); err != nil {
+			return nil, err
+		}
+		return func(_ int, name string) string {
+			return jsonRpcParamToString(named[name])
+		}, nil
+	case '[':
+		var positional []json.RawMessage
+		if err := json.Unmarshal(params, &positional); err != nil {
+			return nil, err
+		}
+		// a single filter object, e. g. `[{"fromBlock": "0x1"}]`, passes its params by name
+		if len(positional) == 1 && bytes.HasPrefix(bytes.TrimSpace(positional[0]), []byte("{")) {
+			return parseJsonRpcParams(positional[0])
+		}
+		return func(position int, _ string) string {
+			if position >= len(positional) {
+				return ""
+			}
+			return jsonRpcParamToString(positional[position])
+		}, nil
+	}
+
+	return nil, fmt.Errorf("params have to be an object or an array: %w", types.ErrInvalidParams)
+}
+
+// jsonRpcParamToString converts a single param into the string that is used for the lookup,
+// arrays of strings are joined with commas
+func jsonRpcParamToString(param json.RawMessage) string {
+	var value string
+	if err := json.Unmarshal(param, &value); err == nil {
+		return value
+	}
+
+	var values []string
+	if err := json.Unmarshal(param, &values); err == nil {
+		return strings.Join(values, ",")
+	}
+
+	// numbers are used as they are
+	if bytes.Equal(param, []byte("null")) {
+		return ""
+	}
+	return string(param)
+}
@@ -0,0 +1,296 @@
+package server
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/KYVENetwork/trustless-api/db"
+	"github.com/KYVENetwork/trustless-api/files"
+	"github.com/KYVENetwork/trustless-api/merkle"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+// rangeThreads is the number of data items of a range that are resolved concurrently
+const rangeThreads = 8
+
+// getRangeParameter returns the parameter of an endpoint that can be queried as a range.
+// Only indices with a single numeric key, e. g. the block height, support range queries.
+func getRangeParameter(endpoint types.Endpoint) (types.ParameterIndex, bool) {
+	for _, param := range endpoint.QueryParameter {
+		if len(param.Parameter) == 1 && utils.IsKeyIndex(param.IndexId) {
+			return param, true
+		}
+	}
+	return types.ParameterIndex{}, false
+}
+
+// getRange serves all data items from `from_<param>` to `to_<param>` (inclusive), e. g. `/{slug}/block?from_height=1&to_height=10`
+// Ranges are served in pages of `maxRangeSize` items, `next` contains the key of the first item of the following page.
+// Without `to_<param>` the range is open and ends at the latest indexed item.
+// Instead of a proof for every item, the items of a bundle are proven together with one multi proof.
+func (apiServer *ApiServer) getRange(c *gin.Context, pool ServePool, param types.ParameterIndex, maxRangeSize int) {
+	name := param.Parameter[0]
+
+	from, err := strconv.ParseInt(c.Query("from_"+name), 10, 64)
+	if err != nil || from < 0 {
+		c.JSON(http.StatusBadRequest, pool.Indexer.GetErrorResponse("Invalid params", fmt.Sprintf("from_%v has to be a positive number", name)))
+		return
+	}
+
+	to := int64(math.MaxInt64)
+	if c.Query("to_"+name) != "" {
+		to, err = strconv.ParseInt(c.Query("to_"+name), 10, 64)
+		if err != nil || to < from {
+			c.JSON(http.StatusBadRequest, pool.Indexer.GetErrorResponse("Invalid params", fmt.Sprintf("to_%v has to be a number greater or equal to from_%v", name, name)))
+			return
+		}
+	}
+
+	response := types.RangeResponse{
+		Items:       []types.RangeItem{},
+		MultiProofs: []types.MultiProof{},
+	}
+
+	// one more item than the page size is loaded, it is the start of the next page
+	documents, err := pool.Adapter.GetRange(param.IndexId, from, to, maxRangeSize+1)
+	if err != nil {
+		status, message := getErrorStatus(err)
+		logger.Error().Str("query", c.Request.URL.String()).Str("err", err.Error()).Msg("failed to get range")
+		c.JSON(status, pool.Indexer.GetErrorResponse(message, err.Error()))
+		return
+	}
+
+	if len(documents) > maxRangeSize {
+		response.Next = documents[maxRangeSize].Value
+		documents = documents[:maxRangeSize]
+	}
+
+	options := apiServer.getProofOptions(c, pool.ExcludeProof)
+	proofs := make([]*types.Proof, len(documents))
+	response.Items = make([]types.RangeItem, len(documents))
+
+	var g errgroup.Group
+	g.SetLimit(rangeThreads)
+	for index := range documents {
+		localIndex := index
+		g.Go(func() error {
+			item, proof, err := resolveRangeItem(&documents[localIndex])
+			if err != nil {
+				return err
+			}
+			response.Items[localIndex] = *item
+			proofs[localIndex] = proof
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		status, message := getErrorStatus(err)
+		logger.Error().Str("query", c.Request.URL.String()).Str("err", err.Error()).Msg("failed to resolve range")
+		c.JSON(status, pool.Indexer.GetErrorResponse(message, err.Error()))
+		return
+	}
+
+	if options.include {
+		multiProofs, err := apiServer.getMultiProofs(pool, response.Items, proofs, options)
+		if err != nil {
+			logger.Error().Str("query", c.Request.URL.String()).Str("err", err.Error()).Msg("failed to create multi proofs")
+			c.JSON(http.StatusInternalServerError, pool.Indexer.GetErrorResponse("Internal error", err.Error()))
+			return
+		}
+		response.MultiProofs = multiProofs
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.JSON(http.StatusOK, response)
+}
+
+// resolveRangeItem loads a single data item of a range together with its decoded proof
+func resolveRangeItem(document *db.RangeDocument) (*types.RangeItem, *types.Proof, error) {
+	file := files.SavedFile{Type: document.FileType, Path: document.FilePath}
+	bytes, err := file.Resolve()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var trustlessDataItem types.TrustlessDataItem
+	if err := json.Unmarshal(bytes, &trustlessDataItem); err != nil {
+		return nil, nil, err
+	}
+
+	item := &types.RangeItem{
+		Key:      document.Value,
+		Value:    trustlessDataItem.Value,
+		BundleId: document.BundleID,
+	}
+
+	if trustlessDataItem.Proof == "" {
+		return item, nil, nil
+	}
+
+	proof, err := utils.DecodeProof(trustlessDataItem.Proof)
+	if err != nil {
+		return nil, nil, err
+	}
+	return item, proof, nil
+}
+
+// getMultiProofs creates one multi proof for every bundle that is covered by the items.
+// The part of the proof of an item below the bundle leaf is attached to the item as local proof.
+// Bundles that were indexed before the leafs were stored can't be proven with a multi proof,
+// their items carry the single proof instead. The proof of every other item has to match the stored leafs,
+// an inconsistent bundle fails the range instead of serving a multi proof that doesn't verify.
+func (apiServer *ApiServer) getMultiProofs(pool ServePool, items []types.RangeItem, proofs []*types.Proof, options proofOptions) ([]types.MultiProof, error) {
+	var bundleIds []int64
+	bundleItems := map[int64][]int{}
+	for index, proof := range proofs {
+		if proof == nil {
+			continue
+		}
+		if _, ok := bundleItems[proof.BundleId]; !ok {
+			bundleIds = append(bundleIds, proof.BundleId)
+		}
+		bundleItems[proof.BundleId] = append(bundleItems[proof.BundleId], index)
+	}
+
+	// every bundle only touches its own items, therefore they can be updated concurrently
+	multiProofs := make([]*types.MultiProof, len(bundleIds))
+
+	var g errgroup.Group
+	g.SetLimit(rangeThreads)
+	for bundleIndex, bundleId := range bundleIds {
+		localBundleIndex, localBundleId := bundleIndex, bundleId
+		g.Go(func() error {
+			tree, root, err := getBundleTree(pool, localBundleId)
+			if err != nil {
+				return err
+			}
+
+			depth := 0
+			if tree != nil {
+				depth = tree.Depth()
+			}
+			var leafIndices []int
+			for _, index := range bundleItems[localBundleId] {
+				proof := proofs[index]
+				item := &items[index]
+
+				// items of legacy bundles are served with the single proof
+				if tree == nil {
+					encoded, err := utils.EncodeProof(proof)
+					if err != nil {
+						return err
+					}
+					item.Proof, _ = options.transcode(encoded)
+					continue
+				}
+
+				// the bundle part of the proof has to be the proof of the stored leaf, otherwise the multi proof would not prove the item
+				if len(proof.Hashes) < depth {
+					return fmt.Errorf("proof of item %v is shorter than the tree of bundle %v", item.Key, localBundleId)
+				}
+				local, bundleProof := proof.Hashes[:len(proof.Hashes)-depth], proof.Hashes[len(proof.Hashes)-depth:]
+				item.LeafIndex = merkle.GetLeafIndex(bundleProof)
+				if expected, err := tree.GetProof(item.LeafIndex); err != nil || !slices.Equal(expected, bundleProof) {
+					return fmt.Errorf("proof of item %v does not match the stored leafs of bundle %v", item.Key, localBundleId)
+				}
+
+				item.DataItemKey = proof.DataItemKey
+				item.DataItemValueKey = proof.DataItemValueKey
+				item.LeafScheme = proof.LeafScheme
+				if len(local) > 0 {
+					item.LocalProof = local
+				}
+				leafIndices = append(leafIndices, item.LeafIndex)
+			}
+
+			if len(leafIndices) == 0 {
+				return nil
+			}
+
+			hashes, err := tree.GetMultiProof(leafIndices)
+			if err != nil {
+				return err
+			}
+			if hashes == nil {
+				// all leafs are covered by the range
+				hashes = []string{}
+			}
+
+			multiProofs[localBundleIndex] = &types.MultiProof{
+				ChainId:     pool.Config.ChainId,
+				PoolId:      pool.Config.PoolId,
+				BundleId:    localBundleId,
+				BundleRoot:  root,
+				LeafCount:   tree.LeafCount(),
+				LeafIndices: leafIndices,
+				Hashes:      hashes,
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	result := []types.MultiProof{}
+	for _, multiProof := range multiProofs {
+		if multiProof != nil {
+			result = append(result, *multiProof)
+		}
+	}
+	return result, nil
+}
+
+// getBundleTree returns the merkle tree of the stored leafs and the merkle root of a bundle,
+// the tree is nil if the bundle was indexed before its leafs were stored
+func getBundleTree(pool ServePool, bundleId int64) (*merkle.Tree, string, error) {
+	bundle, err := pool.Adapter.GetBundle(bundleId)
+	if err != nil {
+		if errors.Is(err, types.ErrNotFound) {
+			return nil, "", nil
+		}
+		return nil, "", err
+	}
+
+	if bundle.LeafHashes == "" {
+		return nil, "", nil
+	}
+
+	var leafHashes []string
+	if err := json.Unmarshal([]byte(bundle.LeafHashes), &leafHashes); err != nil {
+		return nil, "", fmt.Errorf("invalid leaf hashes: %w", err)
+	}
+	if len(leafHashes) == 0 {
+		return nil, "", nil
+	}
+
+	leafs := make([][32]byte, len(leafHashes))
+	for index, leafHash := range leafHashes {
+		decoded, err := hex.DecodeString(leafHash)
+		if err != nil || len(decoded) != 32 {
+			return nil, "", fmt.Errorf("invalid leaf hash %v", leafHash)
+		}
+		copy(leafs[index][:], decoded)
+	}
+
+	tree, err := merkle.NewTree(leafs)
+	if err != nil {
+		return nil, "", err
+	}
+
+	root := tree.Root()
+	if bundle.MerkleRoot != "" && hex.EncodeToString(root[:]) != bundle.MerkleRoot {
+		return nil, "", fmt.Errorf("stored leafs of bundle %v don't fold up to its merkle root %v", bundleId, bundle.MerkleRoot)
+	}
+
+	return tree, hex.EncodeToString(root[:]), nil
+}
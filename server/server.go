@@ -4,18 +4,26 @@ import (
 	"bytes"
 	_ "embed"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"html/template"
+	"maps"
+	"mime"
 	"net/http"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/KYVENetwork/trustless-api/types"
 
 	"github.com/KYVENetwork/trustless-api/config"
 	"github.com/KYVENetwork/trustless-api/db"
+	"github.com/KYVENetwork/trustless-api/files"
 	"github.com/KYVENetwork/trustless-api/indexer"
+	"github.com/KYVENetwork/trustless-api/registry"
+	"github.com/KYVENetwork/trustless-api/status"
 	"github.com/KYVENetwork/trustless-api/utils"
 	"github.com/gin-gonic/gin"
 	"github.com/spf13/viper"
@@ -34,6 +42,15 @@ var openapi string
 type ApiServer struct {
 	blobsAdapter db.Adapter
 	lineaAdapter db.Adapter
+
+	// router serves the current pools, it is replaced when the pools change
+	router atomic.Pointer[gin.Engine]
+	// adapters are the database adapters of the pools by chain id, pool id and indexer, they are reused when the pools change
+	adapters map[string]db.Adapter
+	// served are the slugs of the current pools, removed are the slugs of pools that are no longer served
+	served  map[string]bool
+	removed map[string]bool
+	mutex   sync.Mutex
 }
 
 type ServePool struct {
@@ -41,15 +58,91 @@ type ServePool struct {
 	Adapter      db.Adapter
 	Indexer      indexer.Indexer
 	ExcludeProof bool
+	Config       config.PoolsConfig
+}
+
+// serverSettings are the settings of the config that are used to serve requests. They are read when the router is created,
+// the handlers must not read them from viper as the config file can be reloaded at any time.
+type serverSettings struct {
+	adminToken   string
+	maxBatchSize int
+	maxRangeSize int
+	maxBundleLag int64
+	storage      files.SaveDataItem
+}
+
+func getServerSettings() (serverSettings, error) {
+	storage, err := config.GetSaveDataItemAdapter()
+	if err != nil {
+		return serverSettings{}, err
+	}
+
+	return serverSettings{
+		adminToken:   viper.GetString("server.admin-token"),
+		maxBatchSize: viper.GetInt("server.max-batch-size"),
+		maxRangeSize: viper.GetInt("server.max-range-size"),
+		maxBundleLag: viper.GetInt64("health.max-bundle-lag"),
+		storage:      storage,
+	}, nil
+}
+
+func newApiServer() *ApiServer {
+	return &ApiServer{
+		adapters: map[string]db.Adapter{},
+		served:   map[string]bool{},
+		removed:  map[string]bool{},
+	}
 }
 
 func StartApiServer() *ApiServer {
-	var blobsAdapter, lineaAdapter db.Adapter
 	port := viper.GetInt("server.port")
 
+	apiServer := newApiServer()
+
+	pools := registry.GetPoolsConfig()
+	apiServer.setPools(pools)
+
+	// pools that are added to or removed from the config file or the registry are served without a restart
+	registry.Watch(pools, apiServer.setPools)
+
+	if err := http.ListenAndServe(fmt.Sprintf(":%v", port), apiServer); err != nil {
+		logger.Error().Str("err", err.Error()).Msg("failed to run api server")
+	}
+
+	return apiServer
+}
+
+// ServeHTTP serves a request with the router of the current pools
+func (apiServer *ApiServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	apiServer.router.Load().ServeHTTP(w, r)
+}
+
+// setPools replaces the router with a router that serves the given pools with the current settings.
+// Requests to pools that were served before but are not part of the given pools are answered with 410 Gone.
+func (apiServer *ApiServer) setPools(poolsConfig []config.PoolsConfig) {
+	apiServer.mutex.Lock()
+	defer apiServer.mutex.Unlock()
+
+	settings, err := getServerSettings()
+	if err != nil {
+		logger.Error().Str("err", err.Error()).Msg("failed to read server settings, keeping the current pools")
+		return
+	}
+
 	var pools []ServePool
-	for _, p := range config.GetPoolsConfig() {
-		adapter := p.GetDatabaseAdapter()
+	served := map[string]bool{}
+	for _, p := range poolsConfig {
+		key := fmt.Sprintf("%v/%v/%v", p.ChainId, p.PoolId, p.Indexer)
+		adapter, ok := apiServer.adapters[key]
+		if !ok {
+			var err error
+			adapter, err = p.GetDatabaseAdapter()
+			if err != nil {
+				logger.Error().Str("err", err.Error()).Str("slug", p.Slug).Msg("failed to create database adapter, skipping pool")
+				continue
+			}
+			apiServer.adapters[key] = adapter
+		}
 		indexer := adapter.GetIndexer()
 
 		serverPool := ServePool{
@@ -57,20 +150,33 @@ func StartApiServer() *ApiServer {
 			Adapter:      adapter,
 			Slug:         p.Slug,
 			ExcludeProof: p.ExcludeProof,
+			Config:       p,
 		}
 		pools = append(pools, serverPool)
+		served[strings.Trim(p.Slug, "/")] = true
+	}
+
+	for slug := range apiServer.served {
+		if !served[slug] {
+			logger.Info().Str("slug", slug).Msg("pool is no longer served")
+			apiServer.removed[slug] = true
+		}
 	}
+	for slug := range served {
+		delete(apiServer.removed, slug)
+	}
+	apiServer.served = served
+
+	apiServer.router.Store(apiServer.newRouter(pools, settings))
+}
 
+// newRouter creates the router that serves all pools
+func (apiServer *ApiServer) newRouter(pools []ServePool, settings serverSettings) *gin.Engine {
 	openapiPaths, err := generateOpenApi(pools)
 	if err != nil {
 		logger.Error().Str("err", err.Error()).Msg("failed to generate openapi")
 	}
 
-	apiServer := &ApiServer{
-		blobsAdapter: blobsAdapter,
-		lineaAdapter: lineaAdapter,
-	}
-
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 
@@ -83,10 +189,9 @@ func StartApiServer() *ApiServer {
 	}
 
 	t.Execute(&templateBytes, OpenApi{string(openapiPaths), utils.GetVersion()})
-	openapi = templateBytes.String()
 
 	// Replace HTML entity for single quote with actual single quote
-	openapi = html.UnescapeString(openapi)
+	openapiSpec := html.UnescapeString(templateBytes.String())
 
 	r.GET("/", func(c *gin.Context) {
 		c.Data(http.StatusOK, "text/html", []byte(embeddedHTML))
@@ -94,7 +199,36 @@ func StartApiServer() *ApiServer {
 
 	// serve the openapi file, this is used by swagger ui to display the api
 	r.GET("/openapi.yml", func(c *gin.Context) {
-		c.Data(http.StatusOK, "application/yaml", []byte(openapi))
+		c.Data(http.StatusOK, "application/yaml", []byte(openapiSpec))
+	})
+
+	// serve the sync progress of all pools
+	r.GET("/status", func(c *gin.Context) {
+		apiServer.getStatus(c, pools)
+	})
+
+	// health probes
+	var probePools []status.Pool
+	for _, pool := range pools {
+		probePools = append(probePools, status.Pool{Config: pool.Config, Adapter: pool.Adapter})
+	}
+	r.GET("/healthz", gin.WrapF(status.HealthHandler(probePools, settings.storage)))
+	r.GET("/readyz", gin.WrapF(status.ReadinessHandler(probePools, settings.maxBundleLag)))
+
+	// reload the pools of the config file and the registry, only available if an admin token is configured
+	if token := settings.adminToken; token != "" {
+		r.POST("/admin/reload", func(c *gin.Context) {
+			apiServer.reload(c, token)
+		})
+	}
+
+	// pools that were removed are gone, all other unknown paths are not found
+	removed := maps.Clone(apiServer.removed)
+	r.NoRoute(func(c *gin.Context) {
+		slug, _, _ := strings.Cut(strings.TrimPrefix(c.Request.URL.Path, "/"), "/")
+		if removed[slug] {
+			c.JSON(http.StatusGone, gin.H{"error": "Gone", "message": fmt.Sprintf("pool %v is no longer served", slug)})
+		}
 	})
 
 	// Enable caching for successful responses only
@@ -107,46 +241,75 @@ func StartApiServer() *ApiServer {
 
 	for _, pool := range pools {
 		localPool := pool
+
+		if limiter, ok := localPool.Indexer.(indexer.RangeLimiter); ok {
+			limiter.SetMaxRangeSize(int64(settings.maxRangeSize))
+		}
+
+		r.GET(fmt.Sprintf("%v/status", localPool.Slug), func(ctx *gin.Context) {
+			apiServer.getPoolStatus(ctx, localPool)
+		})
+
+		r.GET(fmt.Sprintf("%v/bundle", localPool.Slug), func(ctx *gin.Context) {
+			apiServer.getBundle(ctx, localPool)
+		})
+
 		for p, endpoint := range localPool.Indexer.GetBindings() {
 			path := fmt.Sprintf("%v%v", localPool.Slug, p)
 			localEndpoint := endpoint
+			rangeParam, hasRange := getRangeParameter(localEndpoint)
 			r.GET(path, func(ctx *gin.Context) {
-				indexId, query, err := apiServer.findSelectedParameter(ctx, &localEndpoint.QueryParameter)
+				if hasRange && ctx.Query("from_"+rangeParam.Parameter[0]) != "" {
+					apiServer.getRange(ctx, localPool, rangeParam, settings.maxRangeSize)
+					return
+				}
+				indexId, query, err := apiServer.findSelectedParameter(&localEndpoint.QueryParameter, func(_ int, name string) string {
+					return ctx.Query(name)
+				})
 				if err != nil {
-					ctx.JSON(http.StatusInternalServerError, localPool.Indexer.GetErrorResponse("Invalid params", nil))
+					ctx.JSON(http.StatusBadRequest, localPool.Indexer.GetErrorResponse("Invalid params", nil))
 					return
 				}
 				apiServer.getIndex(ctx, localPool, query, indexId)
 			})
 		}
-	}
 
-	if err := r.Run(fmt.Sprintf(":%v", port)); err != nil {
-		logger.Error().Str("err", err.Error()).Msg("failed to run api server")
+		// pools that serve JSON-RPC methods can also be requested with native JSON-RPC requests
+		if methods := getJsonRpcMethods(localPool.Indexer); len(methods) > 0 {
+			r.POST(localPool.Slug, func(ctx *gin.Context) {
+				apiServer.serveJsonRpc(ctx, localPool, methods, settings.maxBatchSize)
+			})
+		}
 	}
 
-	return apiServer
+	return r
 }
 
-func (apiServer *ApiServer) findSelectedParameter(c *gin.Context, params *[]types.ParameterIndex) (int, []string, error) {
+// findSelectedParameter selects the first parameter index where all parameters have a value
+// and returns its index id together with the values of the parameters followed by the values of the optional parameters.
+// `getValue` returns the value of a parameter, either by its position or by its name.
+func (apiServer *ApiServer) findSelectedParameter(params *[]types.ParameterIndex, getValue func(position int, name string) string) (int, []string, error) {
 	// iterate over all params
 	// select the one where all params have a value set and return the build string from the parameter
 	for _, param := range *params {
 
 		var query []string
-		for _, parameterName := range param.Parameter {
-			if c.Query(parameterName) != "" {
-				query = append(query, c.Query(parameterName))
+		for position, parameterName := range param.Parameter {
+			if value := getValue(position, parameterName); value != "" {
+				query = append(query, value)
 			}
 		}
 
 		if len(query) == len(param.Parameter) {
+			for position, parameterName := range param.OptionalParameter {
+				query = append(query, getValue(len(param.Parameter)+position, parameterName))
+			}
 			return param.IndexId, query, nil
 		}
 	}
 
 	// no fitting parameter
-	return -1, []string{}, fmt.Errorf("invalid params")
+	return -1, []string{}, types.ErrInvalidParams
 }
 
 // getIndex will search the database for the given query and serve the correct data item if one is found
@@ -155,58 +318,195 @@ func (apiServer *ApiServer) findSelectedParameter(c *gin.Context, params *[]type
 // `index` - is the name of the index that will be used e. g. block_height
 // `indexId` - is the corresponding Id for the key e. g. block_height -> 0
 func (apiServer *ApiServer) getIndex(c *gin.Context, pool ServePool, query []string, indexId int) {
-
 	start := time.Now()
-	interceptResponse, err := pool.Indexer.InterceptRequest(pool.Adapter.Get, indexId, query)
+	response, err := apiServer.resolveIndex(pool, query, indexId)
+	if err != nil {
+		status, message := getErrorStatus(err)
+		if status >= http.StatusInternalServerError {
+			logger.Error().Str("query", c.Request.URL.String()).Str("err", err.Error()).Msg("failed to resolve data item")
+		}
+		c.JSON(status, pool.Indexer.GetErrorResponse(message, apiServer.getErrorData(pool, indexId, query, err)))
+		return
+	}
+	logger.Debug().Str("query", c.FullPath()).Msg(fmt.Sprintf("lookup took: %v", time.Since(start)))
+
+	options := apiServer.getProofOptions(c, pool.ExcludeProof)
+	data, err := apiServer.attachProofs(*response.Data, response.Proofs, options)
 	if err != nil {
+		logger.Error().Str("query", c.Request.URL.String()).Str("err", err.Error()).Msg("failed to attach proofs")
 		c.JSON(http.StatusInternalServerError, pool.Indexer.GetErrorResponse("Internal error", err.Error()))
 		return
 	}
-	if interceptResponse != nil {
-		logger.Debug().Str("query", c.FullPath()).Msg(fmt.Sprintf("intercept took: %v", time.Since(start)))
-		proofValue, proofParamExists := c.GetQuery("proof")
-		if !pool.ExcludeProof && (!proofParamExists || proofValue == "true") {
-			c.Header("x-kyve-proof", interceptResponse.Proof)
+
+	apiServer.setProofHeader(c, response.Proof, options)
+	// search results change whenever new data items are indexed
+	if utils.IsSearchIndex(indexId) {
+		c.Header("Cache-Control", "no-cache")
+	}
+	c.Data(http.StatusOK, "application/json", data)
+}
+
+// resolveIndex looks up the data item for the given query and returns the response body together with its proof.
+// If the index is an event search or the indexer intercepts the request, its response is returned instead.
+func (apiServer *ApiServer) resolveIndex(pool ServePool, query []string, indexId int) (*types.InterceptionResponse, error) {
+	if searcher, ok := pool.Indexer.(indexer.EventSearcher); ok {
+		searchResponse, err := searcher.SearchEvents(pool.Adapter.Get, pool.Adapter.SearchEvents, indexId, query)
+		if err != nil {
+			return nil, err
 		}
-		c.Data(http.StatusOK, "application/json", *interceptResponse.Data)
-		return
+		if searchResponse != nil {
+			return searchResponse, nil
+		}
+	}
+
+	interceptResponse, err := pool.Indexer.InterceptRequest(pool.Adapter.Get, indexId, query)
+	if err != nil {
+		return nil, err
+	}
+	if interceptResponse != nil {
+		return interceptResponse, nil
 	}
 
 	index := strings.Join(query, "-")
 	file, err := pool.Adapter.Get(indexId, index)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, pool.Indexer.GetErrorResponse("Internal error", err.Error()))
-		return
+		return nil, err
 	}
 	bytes, err := file.Resolve()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, pool.Indexer.GetErrorResponse("Internal error", err.Error()))
-		return
+		return nil, err
 	}
-	apiServer.serveFile(c, bytes, pool.ExcludeProof)
-}
 
-func (apiServer *ApiServer) serveFile(c *gin.Context, file []byte, excludeProof bool) {
 	var trustlessDataItem types.TrustlessDataItem
-	err := json.Unmarshal(file, &trustlessDataItem)
+	if err := json.Unmarshal(bytes, &trustlessDataItem); err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(trustlessDataItem.Value)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": err.Error(),
-		})
+		return nil, err
+	}
+
+	return &types.InterceptionResponse{
+		Data:  &data,
+		Proof: trustlessDataItem.Proof,
+	}, nil
+}
+
+// getErrorData returns the data that is served together with an error.
+// If the data item is not indexed, the data describes why it is missing.
+func (apiServer *ApiServer) getErrorData(pool ServePool, indexId int, query []string, err error) any {
+	if !errors.Is(err, types.ErrNotFound) {
+		return err.Error()
+	}
+
+	missing, coverageErr := apiServer.getMissingDataItem(pool, indexId, query)
+	if coverageErr != nil {
+		logger.Error().Str("err", coverageErr.Error()).Str("slug", pool.Slug).Msg("failed to get coverage")
+		return err.Error()
+	}
+
+	return missing
+}
+
+// getErrorStatus maps an error to the HTTP status code and message it is served with
+func getErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, types.ErrNotFound):
+		return http.StatusNotFound, "Not found"
+	case errors.Is(err, types.ErrInvalidParams):
+		return http.StatusBadRequest, "Invalid params"
+	case errors.Is(err, types.ErrStorage):
+		return http.StatusBadGateway, "Storage error"
+	}
+
+	return http.StatusInternalServerError, "Internal error"
+}
+
+// proofOptions are the proof settings requested by the client. They are read once on the request goroutine,
+// the gin context must not be accessed from the goroutines that resolve the items of a request.
+type proofOptions struct {
+	// include is false if the pool excludes proofs or the client disabled them with `proof=false`
+	include bool
+	version uint8
+}
+
+// getProofOptions reads the proof settings of the request
+func (apiServer *ApiServer) getProofOptions(c *gin.Context, excludeProof bool) proofOptions {
+	return proofOptions{
+		include: !excludeProof && c.Query("proof") != "false",
+		version: apiServer.getProofVersion(c),
+	}
+}
+
+// setProofHeader attaches the proof in the requested proof version to the response.
+func (apiServer *ApiServer) setProofHeader(c *gin.Context, proof string, options proofOptions) {
+	proof, version := options.transcode(proof)
+	if proof == "" {
 		return
 	}
 
-	// only send the proof if it is attached
-	proofValue, proofParamExists := c.GetQuery("proof")
+	c.Header("x-kyve-proof", proof)
+	c.Header("x-kyve-proof-version", fmt.Sprintf("%v", version))
+}
+
+// transcode returns the proof in the requested proof version together with the version it is encoded with.
+// The proof is only returned if there is one and proofs are included.
+func (options proofOptions) transcode(proof string) (string, uint8) {
+	if proof == "" || !options.include {
+		return "", 0
+	}
+
+	return utils.TranscodeProof(proof, options.version)
+}
+
+// attachProofs adds the proofs of the items of a response as `proofs` array to the response body.
+// The proofs are transcoded into the requested proof version and omitted in the same cases as the proof header.
+func (apiServer *ApiServer) attachProofs(data []byte, proofs []string, options proofOptions) ([]byte, error) {
+	if proofs == nil || !options.include {
+		return data, nil
+	}
+
+	transcoded := make([]string, len(proofs))
+	for index, proof := range proofs {
+		transcoded[index], _ = options.transcode(proof)
+	}
+
+	var body map[string]json.RawMessage
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, err
+	}
+	encoded, err := json.Marshal(transcoded)
+	if err != nil {
+		return nil, err
+	}
+	body["proofs"] = encoded
+
+	return json.Marshal(body)
+}
 
-	if trustlessDataItem.Proof != "" && !excludeProof {
-		if proofParamExists {
-			if proofValue != "false" {
-				c.Header("x-kyve-proof", trustlessDataItem.Proof)
+// getProofVersion returns the proof version requested by the client.
+// The version can be requested with the `proof_version` query parameter
+// or with the `kyve-proof-version` media type parameter of the Accept header, e. g. `Accept: application/json; kyve-proof-version=2`
+func (apiServer *ApiServer) getProofVersion(c *gin.Context) uint8 {
+	version := c.Query("proof_version")
+
+	if version == "" {
+		for _, accept := range strings.Split(c.GetHeader("Accept"), ",") {
+			_, params, err := mime.ParseMediaType(accept)
+			if err == nil && params["kyve-proof-version"] != "" {
+				version = params["kyve-proof-version"]
+				break
 			}
-		} else {
-			c.Header("x-kyve-proof", trustlessDataItem.Proof)
 		}
 	}
-	c.JSON(http.StatusOK, trustlessDataItem.Value)
+
+	switch version {
+	case "1":
+		return utils.ProofVersion1
+	case "2":
+		return utils.ProofVersion2
+	}
+
+	return utils.DefaultProofVersion
 }
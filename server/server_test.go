@@ -0,0 +1,1346 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KYVENetwork/trustless-api/collectors/bundles"
+	"github.com/KYVENetwork/trustless-api/collectors/chain"
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/KYVENetwork/trustless-api/crawler"
+	"github.com/KYVENetwork/trustless-api/db"
+	"github.com/KYVENetwork/trustless-api/db/adapters"
+	"github.com/KYVENetwork/trustless-api/files"
+	"github.com/KYVENetwork/trustless-api/indexer/helper"
+	"github.com/KYVENetwork/trustless-api/internal/testutil"
+	"github.com/KYVENetwork/trustless-api/merkle"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+	"github.com/KYVENetwork/trustless-api/verify"
+	"github.com/spf13/viper"
+	"golang.org/x/sync/semaphore"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+)
+
+const testSlug = "test"
+
+// testKeys are the keys of the data items the fixture pools are crawled with, the bundles contain 4, 5 and 3 data items
+var testKeys = []int{100, 103, 104, 108, 110, 111}
+
+// fixturePaths returns the paths of all data items with a proof the fixture serves for a key
+var fixturePaths = map[string]func(key int) []string{
+	"Height": func(key int) []string {
+		return []string{fmt.Sprintf("/value?height=%v", key)}
+	},
+	"EthBlobs": func(key int) []string {
+		return []string{
+			fmt.Sprintf("/beacon/blob_sidecars?block_height=%v", key),
+			fmt.Sprintf("/beacon/blob_sidecars?slot_number=%v", key+1000),
+		}
+	},
+	"Tendermint": func(key int) []string {
+		return []string{
+			fmt.Sprintf("/block?height=%v", key),
+			fmt.Sprintf("/block_results?height=%v", key),
+			fmt.Sprintf("/block_by_hash?hash=%v", testutil.BlockHash(key)),
+		}
+	},
+	"Celestia": func(key int) []string {
+		paths := []string{
+			fmt.Sprintf("/block?height=%v", key),
+			fmt.Sprintf("/block_results?height=%v", key),
+		}
+		for index, namespace := range testutil.CelestiaNamespaces {
+			paths = append(paths, fmt.Sprintf("/Get?height=%v&namespace=%v&commitment=%v", key, url.QueryEscape(namespace), url.QueryEscape(testutil.CelestiaCommitment(key, index))))
+		}
+		return paths
+	},
+	"EVM": func(key int) []string {
+		return []string{
+			fmt.Sprintf("/blockByHash?hash=%v", testutil.BlockHash(key)),
+			fmt.Sprintf("/blockByNumber?number=%v", key),
+			fmt.Sprintf("/blockByNumber?number=0x%x", key),
+			fmt.Sprintf("/blockReceipts?hash=%v", testutil.BlockHash(key)),
+			fmt.Sprintf("/transactionByHash?hash=%v", testutil.TransactionHash(key, 0)),
+			fmt.Sprintf("/transactionByHash?hash=%v", testutil.TransactionHash(key, 1)),
+		}
+	},
+}
+
+// startTestServer crawls the bundles of the fixture from a fake chain and serves the pool
+func startTestServer(t *testing.T, fixture testutil.Fixture) *httptest.Server {
+	return startTestPoolServer(t, fixture, config.PoolsConfig{ChainId: "kyve-1", PoolId: 1, Indexer: fixture.Indexer, Slug: testSlug})
+}
+
+// startTestPoolServer crawls the bundles of the fixture with the indexer of the pool config and serves the pool
+func startTestPoolServer(t *testing.T, fixture testutil.Fixture, poolConfig config.PoolsConfig) *httptest.Server {
+	testutil.LoadConfig(t)
+	fakeChain := testutil.NewChain(t, "kyve-1")
+	if err := fakeChain.AddFixtureBundles(1, fixture, 100, 4, 5, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	return serveTestPool(t, poolConfig)
+}
+
+// serveTestPool crawls the bundles of the pool from the fake chain and serves the pool
+func serveTestPool(t *testing.T, poolConfig config.PoolsConfig) *httptest.Server {
+	return serveTestPools(t, crawlTestPool(t, poolConfig))
+}
+
+// serveTestPools serves the pools with a new router
+func serveTestPools(t *testing.T, pools ...ServePool) *httptest.Server {
+	settings, err := getServerSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer((&ApiServer{}).newRouter(pools, settings))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// crawlTestPool crawls the bundles of the pool from the fake chain
+func crawlTestPool(t *testing.T, poolConfig config.PoolsConfig) ServePool {
+	idx, err := poolConfig.GetIndexer()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	adapter := adapters.GetSQLite(&files.LocalFileAdapter, idx, 1, "kyve-1")
+	c := crawler.CreateBundleCrawler(&adapter, chain.NewClient("kyve-1"), "kyve-1", 1, 0, semaphore.NewWeighted(4))
+	c.CrawlBundles()
+
+	return ServePool{
+		Slug:    testSlug,
+		Adapter: &adapter,
+		Indexer: idx,
+		Config:  poolConfig,
+	}
+}
+
+func get(t *testing.T, url string) (*http.Response, []byte) {
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return response, body
+}
+
+func TestServeVerifiedResponses(t *testing.T) {
+	for _, fixture := range testutil.Fixtures {
+		t.Run(fixture.Indexer, func(t *testing.T) {
+			server := startTestServer(t, fixture)
+
+			for _, key := range testKeys {
+				for _, path := range fixturePaths[fixture.Indexer](key) {
+					for _, version := range []string{"1", "2"} {
+						response, body := get(t, fmt.Sprintf("%v/%v%v&proof_version=%v", server.URL, testSlug, path, version))
+						if response.StatusCode != http.StatusOK {
+							t.Fatalf("%v: expected status 200, got %v: %s", path, response.StatusCode, body)
+						}
+
+						proof, err := verify.VerifyResponse(body, response.Header.Get(verify.ProofHeader))
+						if err != nil {
+							t.Fatalf("%v: %v", path, err)
+						}
+						if fmt.Sprintf("%v", proof.Version) != version {
+							t.Errorf("%v: expected proof version %v, got %v", path, version, proof.Version)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestServeRequestedItems(t *testing.T) {
+	for _, fixture := range testutil.Fixtures {
+		t.Run(fixture.Indexer, func(t *testing.T) {
+			server := startTestServer(t, fixture)
+
+			for keyIndex, key := range testKeys {
+				otherPaths := fixturePaths[fixture.Indexer](testKeys[(keyIndex+1)%len(testKeys)])
+				for pathIndex, path := range fixturePaths[fixture.Indexer](key) {
+					response, body := get(t, fmt.Sprintf("%v/%v%v", server.URL, testSlug, path))
+					proof, err := verify.VerifyResponse(body, response.Header.Get(verify.ProofHeader))
+					if err != nil {
+						t.Fatalf("%v: %v", path, err)
+					}
+
+					if err := verify.CheckRequest(getQuery(t, path), body, proof); err != nil {
+						t.Errorf("%v: %v", path, err)
+					}
+					// a valid item of the pool is not the item of another request
+					if err := verify.CheckRequest(getQuery(t, otherPaths[pathIndex]), body, proof); err == nil {
+						t.Errorf("%v: expected the item not to match %v", path, otherPaths[pathIndex])
+					}
+				}
+			}
+		})
+	}
+}
+
+// getQuery returns the query parameters of a path
+func getQuery(t *testing.T, path string) url.Values {
+	parsed, err := url.Parse(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed.Query()
+}
+
+func TestServeMissingDataItem(t *testing.T) {
+	server := startTestServer(t, testutil.Fixtures[0])
+
+	for key, reason := range map[int]string{99: utils.MissingOutOfRange, 112: utils.MissingNotYetIndexed} {
+		response, body := get(t, fmt.Sprintf("%v/%v/value?height=%v", server.URL, testSlug, key))
+		if response.StatusCode != http.StatusNotFound {
+			t.Fatalf("height %v: expected status 404, got %v", key, response.StatusCode)
+		}
+
+		var errorResponse struct {
+			Message types.MissingDataItem `json:"message"`
+		}
+		if err := json.Unmarshal(body, &errorResponse); err != nil {
+			t.Fatal(err)
+		}
+		if errorResponse.Message.Reason != reason {
+			t.Errorf("height %v: expected reason %v, got %v", key, reason, errorResponse.Message.Reason)
+		}
+	}
+}
+
+// startGapTestServer serves a Height pool whose second bundle is refused by the crawler, the bundles contain 4, 5 and 3 data items
+func startGapTestServer(t *testing.T) *httptest.Server {
+	return serveTestPools(t, crawlGapTestPool(t))
+}
+
+// crawlGapTestPool crawls a Height pool whose second bundle of the heights 104 to 108 is refused because of a wrong merkle root
+func crawlGapTestPool(t *testing.T) ServePool {
+	testutil.LoadConfig(t)
+	fakeChain := testutil.NewChain(t, "kyve-1")
+	fixture := testutil.Fixtures[0] // Height
+
+	if err := fakeChain.AddFixtureBundles(1, fixture, 100, 4); err != nil {
+		t.Fatal(err)
+	}
+	otherItems := fixture.DataItems(0, 5)
+	wrongRoot := merkle.GetMerkleRoot(*merkle.GetBundleHashes(&otherItems))
+	if _, err := fakeChain.AddBundleWithMerkleRoot(1, fixture.DataItems(104, 5), hex.EncodeToString(wrongRoot[:])); err != nil {
+		t.Fatal(err)
+	}
+	if err := fakeChain.AddFixtureBundles(1, fixture, 109, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	return crawlTestPool(t, config.PoolsConfig{ChainId: "kyve-1", PoolId: 1, Indexer: fixture.Indexer, Slug: testSlug})
+}
+
+func TestServeStatusWithGap(t *testing.T) {
+	server := startGapTestServer(t)
+
+	expected := types.PoolStatus{
+		Slug:                  testSlug,
+		ChainId:               "kyve-1",
+		PoolId:                1,
+		Indexer:               "Height",
+		LatestIndexedBundleId: 2,
+		LatestBundleId:        2,
+		MissingBundles:        1,
+		FromKey:               "100",
+		ToKey:                 "111",
+	}
+
+	response, body := get(t, fmt.Sprintf("%v/%v/status", server.URL, testSlug))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %v: %s", response.StatusCode, body)
+	}
+	var poolStatus types.PoolStatus
+	if err := json.Unmarshal(body, &poolStatus); err != nil {
+		t.Fatal(err)
+	}
+	if poolStatus != expected {
+		t.Errorf("expected pool status %+v, got %+v", expected, poolStatus)
+	}
+
+	response, body = get(t, fmt.Sprintf("%v/status", server.URL))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %v: %s", response.StatusCode, body)
+	}
+	var statuses []types.PoolStatus
+	if err := json.Unmarshal(body, &statuses); err != nil {
+		t.Fatal(err)
+	}
+	if len(statuses) != 1 || statuses[0] != expected {
+		t.Errorf("expected the status of the pool %+v, got %+v", expected, statuses)
+	}
+
+	// every data item that is not served is classified by the coverage of the pool
+	for key, reason := range map[string]string{
+		"99":  utils.MissingOutOfRange,
+		"104": utils.MissingGap,
+		"108": utils.MissingGap,
+		"112": utils.MissingNotYetIndexed,
+		"abc": utils.MissingNotFound,
+	} {
+		response, body := get(t, fmt.Sprintf("%v/%v/value?height=%v", server.URL, testSlug, key))
+		if response.StatusCode != http.StatusNotFound {
+			t.Fatalf("height %v: expected status 404, got %v", key, response.StatusCode)
+		}
+
+		var errorResponse struct {
+			Message types.MissingDataItem `json:"message"`
+		}
+		if err := json.Unmarshal(body, &errorResponse); err != nil {
+			t.Fatal(err)
+		}
+		if errorResponse.Message.Reason != reason || errorResponse.Message.TipKey != "111" {
+			t.Errorf("height %v: expected reason %v with tip 111, got %+v", key, reason, errorResponse.Message)
+		}
+	}
+}
+
+// unreachableAdapter is a database adapter whose database can't be reached
+type unreachableAdapter struct {
+	db.Adapter
+}
+
+func (unreachableAdapter) Ping() error {
+	return errors.New("connection refused")
+}
+
+func TestServeProbes(t *testing.T) {
+	testutil.LoadConfig(t)
+	viper.Set("health.max-bundle-lag", 1)
+	fakeChain := testutil.NewChain(t, "kyve-1")
+	fixture := testutil.Fixtures[0] // Height
+	if err := fakeChain.AddFixtureBundles(1, fixture, 100, 4, 5); err != nil {
+		t.Fatal(err)
+	}
+
+	pool := crawlTestPool(t, config.PoolsConfig{ChainId: "kyve-1", PoolId: 1, Indexer: fixture.Indexer, Slug: testSlug})
+	server := serveTestPools(t, pool)
+
+	unreachable := pool
+	unreachable.Adapter = unreachableAdapter{pool.Adapter}
+	unreachableServer := serveTestPools(t, unreachable)
+
+	expectProbe := func(url string, expectedStatus int) {
+		t.Helper()
+		response, body := get(t, url)
+		if response.StatusCode != expectedStatus {
+			t.Errorf("%v: expected status %v, got %v: %s", url, expectedStatus, response.StatusCode, body)
+		}
+	}
+
+	expectProbe(server.URL+"/healthz", http.StatusOK)
+	expectProbe(unreachableServer.URL+"/healthz", http.StatusServiceUnavailable)
+
+	// the pool is ready as long as it lags at most one bundle behind the chain
+	expectProbe(server.URL+"/readyz", http.StatusOK)
+	if err := fakeChain.AddFixtureBundles(1, fixture, 109, 3); err != nil {
+		t.Fatal(err)
+	}
+	expectProbe(server.URL+"/readyz", http.StatusOK)
+	if err := fakeChain.AddFixtureBundles(1, fixture, 112, 3); err != nil {
+		t.Fatal(err)
+	}
+	expectProbe(server.URL+"/readyz", http.StatusServiceUnavailable)
+}
+
+func TestServeBundle(t *testing.T) {
+	testutil.LoadConfig(t)
+	fakeChain := testutil.NewChain(t, "kyve-1")
+	fixture := testutil.Fixtures[0] // Height
+	if err := fakeChain.AddFixtureBundles(1, fixture, 100, 4, 5, 3); err != nil {
+		t.Fatal(err)
+	}
+	pool := crawlTestPool(t, config.PoolsConfig{ChainId: "kyve-1", PoolId: 1, Indexer: fixture.Indexer, Slug: testSlug})
+
+	// drop the metadata of the second bundle, the next crawl backfills it from the finalized bundle like for bundles indexed before the metadata was stored
+	database, err := gorm.Open(sqlite.Open(viper.GetString("database.dbname")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, bundleTable := db.GetTableNames(1, "kyve-1")
+	if err := database.Exec(fmt.Sprintf("DELETE FROM %v WHERE bundle_id = 1", bundleTable)).Error; err != nil {
+		t.Fatal(err)
+	}
+	recrawl := crawler.CreateBundleCrawler(pool.Adapter, chain.NewClient("kyve-1"), "kyve-1", 1, 0, semaphore.NewWeighted(4))
+	recrawl.CrawlBundles()
+
+	server := serveTestPools(t, pool)
+
+	getBundle := func(bundleId string, expectedStatus int) bundleResponse {
+		response, body := get(t, fmt.Sprintf("%v/%v/bundle?bundle_id=%v", server.URL, testSlug, bundleId))
+		if response.StatusCode != expectedStatus {
+			t.Fatalf("bundle %v: expected status %v, got %v: %s", bundleId, expectedStatus, response.StatusCode, body)
+		}
+		var bundle bundleResponse
+		if err := json.Unmarshal(body, &bundle); err != nil {
+			t.Fatal(err)
+		}
+		return bundle
+	}
+
+	for bundleId, expected := range map[int64]struct {
+		fromKey, toKey string
+		leafs          int
+	}{0: {"100", "103", 4}, 1: {"104", "108", 0}, 2: {"109", "111", 3}} {
+		finalizedBundle, err := fakeChain.GetFinalizedBundle(1, bundleId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		summaryRoot, err := bundles.GetSummaryMerkleRoot(finalizedBundle)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bundle := getBundle(fmt.Sprintf("%v", bundleId), http.StatusOK)
+		if bundle.BundleId != bundleId || bundle.ChainId != "kyve-1" || bundle.PoolId != 1 || bundle.StorageId != finalizedBundle.StorageId || bundle.DataHash != finalizedBundle.DataHash {
+			t.Errorf("bundle %v: expected the metadata of the finalized bundle, got %+v", bundleId, bundle)
+		}
+		if bundle.FromKey != expected.fromKey || bundle.ToKey != expected.toKey || len(bundle.LeafHashes) != expected.leafs {
+			t.Errorf("bundle %v: expected keys %v to %v with %v leafs, got %v to %v with %v leafs", bundleId, expected.fromKey, expected.toKey, expected.leafs, bundle.FromKey, bundle.ToKey, len(bundle.LeafHashes))
+		}
+
+		// the merkle root is only known for bundles that were indexed after the metadata was stored
+		if expected.leafs > 0 {
+			leafs := make([][32]byte, len(bundle.LeafHashes))
+			for index, leaf := range bundle.LeafHashes {
+				if _, err := hex.Decode(leafs[index][:], []byte(leaf)); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if root := merkle.GetMerkleRoot(leafs); bundle.MerkleRoot != summaryRoot || hex.EncodeToString(root[:]) != summaryRoot {
+				t.Errorf("bundle %v: expected merkle root %v of the leafs, got %v", bundleId, summaryRoot, bundle.MerkleRoot)
+			}
+		} else if bundle.MerkleRoot != "" {
+			t.Errorf("bundle %v: expected no merkle root for a backfilled bundle, got %v", bundleId, bundle.MerkleRoot)
+		}
+	}
+
+	getBundle("42", http.StatusNotFound)
+	getBundle("abc", http.StatusBadRequest)
+}
+
+func TestServeRange(t *testing.T) {
+	server := startTestServer(t, testutil.Fixtures[2]) // Tendermint
+
+	url := fmt.Sprintf("%v/%v/block?from_height=101&to_height=110", server.URL, testSlug)
+	response, body := get(t, url)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %v: %s", response.StatusCode, body)
+	}
+
+	var rangeResponse types.RangeResponse
+	if err := json.Unmarshal(body, &rangeResponse); err != nil {
+		t.Fatal(err)
+	}
+	if len(rangeResponse.Items) != 10 || len(rangeResponse.MultiProofs) != 3 {
+		t.Fatalf("expected 10 items of 3 bundles, got %v items of %v bundles", len(rangeResponse.Items), len(rangeResponse.MultiProofs))
+	}
+
+	proofs, err := verify.VerifyUrlItems(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proofs) != 10 {
+		t.Fatalf("expected 10 proofs, got %v", len(proofs))
+	}
+
+	// every change of the range has to fail the verification
+	for name, tamper := range map[string]func(r *types.RangeResponse){
+		"value": func(r *types.RangeResponse) { r.Items[3].Value = json.RawMessage(`{"result":{"tampered":true}}`) },
+		"key":   func(r *types.RangeResponse) { r.Items[3].Key = r.Items[4].Key },
+		"leaf index": func(r *types.RangeResponse) {
+			r.Items[3].LeafIndex, r.Items[4].LeafIndex = r.Items[4].LeafIndex, r.Items[3].LeafIndex
+		},
+		"local proof": func(r *types.RangeResponse) { r.Items[3].LocalProof = r.Items[4].LocalProof },
+		"missing item": func(r *types.RangeResponse) {
+			r.Items = append(r.Items[:3:3], r.Items[4:]...)
+		},
+		"multi proof hash": func(r *types.RangeResponse) {
+			r.MultiProofs[0].Hashes = append([]string{}, r.MultiProofs[0].Hashes...)
+			r.MultiProofs[0].Hashes[0] = strings.Repeat("00", 32)
+		},
+		"bundle root": func(r *types.RangeResponse) { r.MultiProofs[1].BundleRoot = strings.Repeat("00", 32) },
+		"missing multi proof": func(r *types.RangeResponse) {
+			r.MultiProofs = r.MultiProofs[1:]
+		},
+	} {
+		var tampered types.RangeResponse
+		if err := json.Unmarshal(body, &tampered); err != nil {
+			t.Fatal(err)
+		}
+		tamper(&tampered)
+
+		tamperedBody, err := json.Marshal(tampered)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := verify.VerifyRange(tamperedBody); err == nil {
+			t.Errorf("%v: expected the tampered range to fail the verification", name)
+		}
+	}
+}
+
+func TestServeRangeOfInconsistentBundle(t *testing.T) {
+	fixture := testutil.Fixtures[0] // Height
+	otherItems := fixture.DataItems(0, 5)
+	otherLeafs := *merkle.GetBundleHashes(&otherItems)
+	otherRoot := merkle.GetMerkleRoot(otherLeafs)
+	var otherLeafHashes []string
+	for _, leaf := range otherLeafs {
+		otherLeafHashes = append(otherLeafHashes, hex.EncodeToString(leaf[:]))
+	}
+
+	for name, merkleRoot := range map[string]string{
+		"leafs don't fold up to the merkle root": "",
+		"leafs are not the leafs of the items":   hex.EncodeToString(otherRoot[:]),
+	} {
+		testutil.LoadConfig(t)
+		fakeChain := testutil.NewChain(t, "kyve-1")
+		if err := fakeChain.AddFixtureBundles(1, fixture, 100, 4, 5); err != nil {
+			t.Fatal(err)
+		}
+		pool := crawlTestPool(t, config.PoolsConfig{ChainId: "kyve-1", PoolId: 1, Indexer: fixture.Indexer, Slug: testSlug})
+
+		// the stored leafs of the second bundle are replaced with the leafs of other items
+		database, err := gorm.Open(sqlite.Open(viper.GetString("database.dbname")), &gorm.Config{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		leafHashes, err := json.Marshal(otherLeafHashes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _, bundleTable := db.GetTableNames(1, "kyve-1")
+		update := database.Exec(fmt.Sprintf("UPDATE %v SET leaf_hashes = ? WHERE bundle_id = 1", bundleTable), string(leafHashes))
+		if merkleRoot != "" {
+			update = database.Exec(fmt.Sprintf("UPDATE %v SET leaf_hashes = ?, merkle_root = ? WHERE bundle_id = 1", bundleTable), string(leafHashes), merkleRoot)
+		}
+		if update.Error != nil {
+			t.Fatal(update.Error)
+		}
+		server := serveTestPools(t, pool)
+
+		response, body := get(t, fmt.Sprintf("%v/%v/value?from_height=100&to_height=103", server.URL, testSlug))
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("%v: expected status 200 for the consistent bundle, got %v: %s", name, response.StatusCode, body)
+		}
+
+		response, body = get(t, fmt.Sprintf("%v/%v/value?from_height=100&to_height=106", server.URL, testSlug))
+		if response.StatusCode != http.StatusInternalServerError {
+			t.Errorf("%v: expected status 500 for the inconsistent bundle, got %v: %s", name, response.StatusCode, body)
+		}
+	}
+}
+
+func TestServeRangePages(t *testing.T) {
+	pool := crawlGapTestPool(t)
+	viper.Set("server.max-range-size", 3)
+	server := serveTestPools(t, pool)
+
+	// the heights 104 to 108 are missing, open ranges end at the latest indexed height
+	for query, expectedPages := range map[string][][]string{
+		"from_height=100":               {{"100", "101", "102"}, {"103", "109", "110"}, {"111"}},
+		"from_height=101&to_height=110": {{"101", "102", "103"}, {"109", "110"}},
+		"from_height=104&to_height=108": {{}},
+		"from_height=112":               {{}},
+	} {
+		var pages [][]string
+		next := ""
+		for page := 0; page == 0 || next != ""; page++ {
+			url := fmt.Sprintf("%v/%v/value?%v", server.URL, testSlug, query)
+			if next != "" {
+				url = fmt.Sprintf("%v/%v/value?from_height=%v&%v", server.URL, testSlug, next, strings.Join(strings.Split(query, "&")[1:], "&"))
+			}
+			response, body := get(t, url)
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("%v: expected status 200, got %v: %s", query, response.StatusCode, body)
+			}
+
+			var rangeResponse types.RangeResponse
+			if err := json.Unmarshal(body, &rangeResponse); err != nil {
+				t.Fatal(err)
+			}
+			keys := []string{}
+			for _, item := range rangeResponse.Items {
+				keys = append(keys, item.Key)
+			}
+			pages = append(pages, keys)
+			next = rangeResponse.Next
+		}
+
+		if !reflect.DeepEqual(pages, expectedPages) {
+			t.Errorf("%v: expected pages %v, got %v", query, expectedPages, pages)
+		}
+	}
+}
+
+func TestServeRangeOfLegacyKeys(t *testing.T) {
+	testutil.LoadConfig(t)
+	fakeChain := testutil.NewChain(t, "kyve-1")
+	fixture := testutil.Fixtures[0] // Height
+	if err := fakeChain.AddFixtureBundles(1, fixture, 100, 4, 5); err != nil {
+		t.Fatal(err)
+	}
+	poolConfig := config.PoolsConfig{ChainId: "kyve-1", PoolId: 1, Indexer: fixture.Indexer, Slug: testSlug}
+	crawlTestPool(t, poolConfig)
+
+	// keys indexed before the numeric value was stored are migrated when the database is opened
+	database, err := gorm.Open(sqlite.Open(viper.GetString("database.dbname")), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, indexTable, _ := db.GetTableNames(1, "kyve-1")
+	if err := database.Exec(fmt.Sprintf("UPDATE %v SET numeric_value = NULL", indexTable)).Error; err != nil {
+		t.Fatal(err)
+	}
+	server := serveTestPools(t, crawlTestPool(t, poolConfig))
+
+	response, body := get(t, fmt.Sprintf("%v/%v/value?from_height=100", server.URL, testSlug))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %v: %s", response.StatusCode, body)
+	}
+	var rangeResponse types.RangeResponse
+	if err := json.Unmarshal(body, &rangeResponse); err != nil {
+		t.Fatal(err)
+	}
+	if len(rangeResponse.Items) != 9 || rangeResponse.Items[0].Key != "100" || rangeResponse.Items[8].Key != "108" {
+		t.Errorf("expected the items 100 to 108, got %+v", rangeResponse.Items)
+	}
+}
+
+// jsonRpcBatchResponse is a single response of a batch, the proof of each item is attached next to its result
+type jsonRpcBatchResponse struct {
+	JsonRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+	Proof   string          `json:"proof,omitempty"`
+	Proofs  []string        `json:"proofs,omitempty"`
+}
+
+func TestServeJsonRpcBatch(t *testing.T) {
+	server := startTestServer(t, testutil.Fixtures[2]) // Tendermint
+
+	request := `[
+		{"jsonrpc": "2.0", "method": "block", "params": {"height": "100"}, "id": 1},
+		{"jsonrpc": "2.0", "method": "block_results", "params": ["111"], "id": 2},
+		{"jsonrpc": "2.0", "method": "block", "params": {"height": "500"}, "id": 3},
+		{"jsonrpc": "2.0", "method": "tx", "params": {"hash": "` + testutil.TendermintTransactionHash(103, 1) + `"}, "id": 4}
+	]`
+	response, err := http.Post(fmt.Sprintf("%v/%v", server.URL, testSlug), "application/json", bytes.NewBufferString(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	var bodies []json.RawMessage
+	if err := json.NewDecoder(response.Body).Decode(&bodies); err != nil {
+		t.Fatal(err)
+	}
+	if len(bodies) != 4 {
+		t.Fatalf("expected 4 responses, got %v", len(bodies))
+	}
+
+	responses := make([]jsonRpcBatchResponse, len(bodies))
+	for index, body := range bodies {
+		if err := json.Unmarshal(body, &responses[index]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the fields next to the result are kept, a transaction is proven with its block and block results
+	for _, index := range []int{0, 1, 3} {
+		if _, err := verify.VerifyResponse(bodies[index], responses[index].Proof); err != nil {
+			t.Errorf("response %s: %v", responses[index].ID, err)
+		}
+	}
+
+	if responses[2].Error == nil || responses[2].Proof != "" {
+		t.Errorf("expected an error without proof for a missing block, got %+v", responses[2])
+	}
+}
+
+func TestServeTendermintTx(t *testing.T) {
+	server := startTestServer(t, testutil.Fixtures[2]) // Tendermint
+
+	getTx := func(response *http.Response, body []byte) helper.TendermintTx {
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("expected status 200, got %v: %s", response.StatusCode, body)
+		}
+
+		var tx struct {
+			Id     int                 `json:"id"`
+			Result helper.TendermintTx `json:"result"`
+		}
+		if err := json.Unmarshal(body, &tx); err != nil {
+			t.Fatal(err)
+		}
+
+		proof, err := verify.VerifyResponse(body, response.Header.Get(verify.ProofHeader))
+		if err != nil {
+			t.Fatalf("%v: %v", tx.Result.Hash, err)
+		}
+		if proof.LeafScheme != utils.LeafSchemeTendermintTx {
+			t.Errorf("%v: expected leaf scheme %v, got %v", tx.Result.Hash, utils.LeafSchemeTendermintTx, proof.LeafScheme)
+		}
+		return tx.Result
+	}
+
+	for _, key := range testKeys {
+		for index := 0; index < 2; index++ {
+			hash := testutil.TendermintTransactionHash(key, index)
+			txUrl := fmt.Sprintf("%v/%v/tx?hash=0x%v", server.URL, testSlug, strings.ToLower(hash))
+			tx := getTx(get(t, txUrl))
+
+			if tx.Hash != hash || tx.Height != fmt.Sprintf("%v", key) || tx.Index != uint32(index) {
+				t.Errorf("expected transaction %v at height %v, got %v at height %v", hash, key, tx.Hash, tx.Height)
+			}
+			if !bytes.Equal(tx.Tx, testutil.TendermintTransaction(key, index)) {
+				t.Errorf("%v: expected tx %s, got %s", hash, testutil.TendermintTransaction(key, index), tx.Tx)
+			}
+
+			var txResult struct {
+				GasUsed string `json:"gas_used"`
+			}
+			if err := json.Unmarshal(tx.TxResult, &txResult); err != nil || txResult.GasUsed != fmt.Sprintf("%v", 100000+index) {
+				t.Errorf("%v: expected the DeliverTx result of the transaction, got %s", hash, tx.TxResult)
+			}
+
+			// every transaction of the block verifies with the same proof, the hash binds it to the requested transaction
+			if _, err := verify.VerifyUrl(txUrl); err != nil {
+				t.Errorf("%v: %v", hash, err)
+			}
+			response, body := get(t, txUrl)
+			proof, err := utils.DecodeProof(response.Header.Get(verify.ProofHeader))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := verify.CheckRequest(url.Values{"hash": {testutil.TendermintTransactionHash(key, 1-index)}}, body, proof); err == nil {
+				t.Errorf("%v: expected the transaction not to be the other transaction of the block", hash)
+			}
+		}
+	}
+
+	// every change of the transaction, its result or the block results has to fail the verification
+	txResponse, txBody := get(t, fmt.Sprintf("%v/%v/tx?hash=%v", server.URL, testSlug, testutil.TendermintTransactionHash(104, 0)))
+	for name, tamper := range map[string]func(response map[string]any){
+		"tx_result": func(response map[string]any) {
+			response["result"].(map[string]any)["tx_result"].(map[string]any)["code"] = 1
+		},
+		"tx": func(response map[string]any) {
+			response["result"].(map[string]any)["tx"] = base64.StdEncoding.EncodeToString(testutil.TendermintTransaction(500, 0))
+		},
+		"index": func(response map[string]any) { response["result"].(map[string]any)["index"] = 1 },
+		"unknown field": func(response map[string]any) {
+			response["result"].(map[string]any)["tampered"] = true
+		},
+		"block_results": func(response map[string]any) {
+			response["block_results"].(map[string]any)["txs_results"].([]any)[0].(map[string]any)["code"] = 1
+		},
+		"missing block": func(response map[string]any) { delete(response, "block") },
+	} {
+		var tampered map[string]any
+		if err := json.Unmarshal(txBody, &tampered); err != nil {
+			t.Fatal(err)
+		}
+		tamper(tampered)
+		tamperedBody, err := json.Marshal(tampered)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := verify.VerifyResponse(tamperedBody, txResponse.Header.Get(verify.ProofHeader)); err == nil {
+			t.Errorf("%v: expected the tampered transaction to fail the verification", name)
+		}
+	}
+
+	// JSON-RPC requests to CometBFT pass the hash base64 encoded
+	hash, err := hex.DecodeString(testutil.TendermintTransactionHash(104, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	request := fmt.Sprintf(`{"jsonrpc": "2.0", "method": "tx", "params": {"hash": "%v", "prove": true}, "id": 7}`, base64.StdEncoding.EncodeToString(hash))
+	response, err := http.Post(fmt.Sprintf("%v/%v", server.URL, testSlug), "application/json", bytes.NewBufferString(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx := getTx(response, body); tx.Hash != testutil.TendermintTransactionHash(104, 1) {
+		t.Errorf("expected transaction %v, got %v", testutil.TendermintTransactionHash(104, 1), tx.Hash)
+	}
+
+	if response, _ := get(t, fmt.Sprintf("%v/%v/tx?hash=%v", server.URL, testSlug, testutil.TendermintTransactionHash(500, 0))); response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown transaction, got %v", response.StatusCode)
+	}
+	if response, _ := get(t, fmt.Sprintf("%v/%v/tx?hash=0x1234", server.URL, testSlug)); response.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid hash, got %v", response.StatusCode)
+	}
+}
+
+func TestServeTendermintTxOfInvalidDataHash(t *testing.T) {
+	testutil.LoadConfig(t)
+	fakeChain := testutil.NewChain(t, "kyve-1")
+	fixture := testutil.Fixtures[2] // Tendermint
+
+	// the data hash of the second block does not match its transactions
+	dataItems := fixture.DataItems(100, 3)
+	var value map[string]any
+	if err := json.Unmarshal(dataItems[1].Value, &value); err != nil {
+		t.Fatal(err)
+	}
+	value["block"].(map[string]any)["block"].(map[string]any)["header"].(map[string]any)["data_hash"] = strings.Repeat("AB", 32)
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dataItems[1].Value = encoded
+	if _, err := fakeChain.AddBundle(1, fixture.GetIndexer(), dataItems); err != nil {
+		t.Fatal(err)
+	}
+	server := serveTestPool(t, config.PoolsConfig{ChainId: "kyve-1", PoolId: 1, Indexer: fixture.Indexer, Slug: testSlug})
+
+	// the block is indexed without its transactions, the other blocks of the bundle are not affected
+	for path, expectedStatus := range map[string]int{
+		"block?height=101": http.StatusOK,
+		"tx?hash=" + testutil.TendermintTransactionHash(101, 0): http.StatusNotFound,
+		"tx?hash=" + testutil.TendermintTransactionHash(102, 0): http.StatusOK,
+	} {
+		response, body := get(t, fmt.Sprintf("%v/%v/%v", server.URL, testSlug, path))
+		if response.StatusCode != expectedStatus {
+			t.Errorf("%v: expected status %v, got %v: %s", path, expectedStatus, response.StatusCode, body)
+		}
+	}
+}
+
+func TestServeTendermintEventSearch(t *testing.T) {
+	server := startTestServer(t, testutil.Fixtures[2]) // Tendermint
+
+	type txSearch struct {
+		Result struct {
+			Txs        []helper.TendermintTx `json:"txs"`
+			TotalCount string                `json:"total_count"`
+		} `json:"result"`
+	}
+
+	search := func(path string, query url.Values, expectedStatus int) []byte {
+		response, body := get(t, fmt.Sprintf("%v/%v/%v?%v", server.URL, testSlug, path, query.Encode()))
+		if response.StatusCode != expectedStatus {
+			t.Fatalf("%v: expected status %v, got %v: %s", query, expectedStatus, response.StatusCode, body)
+		}
+		// search results change with every indexed bundle and must not be cached
+		if expectedStatus == http.StatusOK && response.Header.Get("Cache-Control") != "no-cache" {
+			t.Errorf("%v: expected no caching of search results, got Cache-Control %v", query, response.Header.Get("Cache-Control"))
+		}
+		return body
+	}
+
+	searchTxs := func(query url.Values) txSearch {
+		var result txSearch
+		if err := json.Unmarshal(search("tx_search", query, http.StatusOK), &result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	for query, expectedHeights := range map[string][]int{
+		"transfer.recipient='" + testutil.TendermintRecipient(0) + "'":                                      {100, 101, 102, 103, 104, 105, 106, 107, 108, 109, 110, 111},
+		"tm.event='Tx' AND transfer.recipient='" + testutil.TendermintRecipient(1) + "' AND tx.height>=105": {105, 106, 107, 108, 109, 110, 111},
+		"transfer.amount CONTAINS '11' AND tx.height < 111":                                                 {110},
+		"tx.height=104 AND transfer.amount EXISTS":                                                          {104},
+		"transfer.recipient='kyve1unknown'":                                                                 {},
+	} {
+		result := searchTxs(url.Values{"query": {query}})
+		if result.Result.TotalCount != fmt.Sprintf("%v", len(expectedHeights)) || len(result.Result.Txs) != len(expectedHeights) {
+			t.Fatalf("%v: expected %v transactions, got %v of %v", query, len(expectedHeights), len(result.Result.Txs), result.Result.TotalCount)
+		}
+		for index, tx := range result.Result.Txs {
+			if tx.Height != fmt.Sprintf("%v", expectedHeights[index]) {
+				t.Errorf("%v: expected transaction %v at height %v, got %v", query, index, expectedHeights[index], tx.Height)
+			}
+			if tx.Proof.RootHash != "" {
+				t.Errorf("%v: expected no proof without prove=true", query)
+			}
+		}
+	}
+
+	// the proofs are included with prove=true, the query may be wrapped in double quotes like in a request to CometBFT
+	hash := testutil.TendermintTransactionHash(108, 1)
+	body := search("tx_search", url.Values{"query": {fmt.Sprintf(`"transfer.recipient='%v' AND tx.height>=107"`, testutil.TendermintRecipient(1))}, "prove": {"true"}, "per_page": {"3"}}, http.StatusOK)
+	proofs, err := verify.VerifyItems(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result txSearch
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(proofs) != 3 || len(result.Result.Txs) != 3 || result.Result.TotalCount != "5" {
+		t.Fatalf("expected the first 3 of 5 proven transactions, got %v proofs of %s", len(proofs), body)
+	}
+	for index, tx := range result.Result.Txs {
+		if expected := testutil.TendermintTransactionHash(107+index, 1); tx.Hash != expected {
+			t.Errorf("expected transaction %v, got %v", expected, tx.Hash)
+		}
+		if proofs[index].LeafScheme != utils.LeafSchemeTendermintTx {
+			t.Errorf("expected the transaction %v to be proven with leaf scheme %v, got %v", index, utils.LeafSchemeTendermintTx, proofs[index].LeafScheme)
+		}
+	}
+
+	// a changed transaction result or swapped blocks fail the verification
+	for name, tamper := range map[string]func(response map[string]any){
+		"tx_result": func(response map[string]any) {
+			txs := response["result"].(map[string]any)["txs"].([]any)
+			txs[1].(map[string]any)["tx_result"].(map[string]any)["code"] = 1
+		},
+		"blocks": func(response map[string]any) {
+			blocks := response["blocks"].([]any)
+			blocks[0], blocks[1] = blocks[1], blocks[0]
+		},
+		"block_results": func(response map[string]any) {
+			response["block_results"] = response["block_results"].([]any)[1:]
+		},
+	} {
+		var tampered map[string]any
+		if err := json.Unmarshal(body, &tampered); err != nil {
+			t.Fatal(err)
+		}
+		tamper(tampered)
+		tamperedBody, err := json.Marshal(tampered)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := verify.VerifyItems(tamperedBody); err == nil {
+			t.Errorf("%v: expected the tampered transactions to fail the verification", name)
+		}
+	}
+
+	result = searchTxs(url.Values{"query": {fmt.Sprintf(`"tx.hash='%v'"`, hash)}, "prove": {"true"}})
+	if len(result.Result.Txs) != 1 || result.Result.Txs[0].Hash != hash {
+		t.Fatalf("expected transaction %v, got %+v", hash, result.Result.Txs)
+	}
+
+	// pages are counted from the start of the order
+	result = searchTxs(url.Values{"query": {"transfer.recipient='" + testutil.TendermintRecipient(1) + "'"}, "page": {"3"}, "per_page": {"5"}, "order_by": {"desc"}})
+	if result.Result.TotalCount != "12" || len(result.Result.Txs) != 2 || result.Result.Txs[0].Height != "101" || result.Result.Txs[1].Height != "100" {
+		t.Errorf("expected the transactions at height 101 and 100 of 12 transactions, got %+v", result.Result)
+	}
+
+	for _, query := range []url.Values{
+		{"query": {"transfer.recipient='" + testutil.TendermintRecipient(1) + "'"}, "page": {"4"}, "per_page": {"5"}},
+		{"query": {"transfer.recipient=" + testutil.TendermintRecipient(1)}},
+		{"query": {"transfer.recipient='" + testutil.TendermintRecipient(1)}},
+		{"query": {"tx.height>'104'"}},
+		{"query": {"tx.height>=104 OR tx.height<100"}},
+		{"query": {"tm.event='Tx'"}},
+		{"query": {"tx.height=104"}, "order_by": {"height"}},
+	} {
+		search("tx_search", query, http.StatusBadRequest)
+	}
+
+	// blocks are ordered descending by default and served with a proof for each block
+	body = search("block_search", url.Values{"query": {"commission.validator='" + testutil.TendermintValidator(0) + "' AND block.height<=108"}}, http.StatusOK)
+	proofs, err = verify.VerifyItems(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocks struct {
+		Result struct {
+			Blocks     []types.TendermintBlock `json:"blocks"`
+			TotalCount string                  `json:"total_count"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &blocks); err != nil {
+		t.Fatal(err)
+	}
+	if len(proofs) != 5 || blocks.Result.TotalCount != "5" {
+		t.Fatalf("expected 5 blocks, got %v of %v", len(proofs), blocks.Result.TotalCount)
+	}
+	for index, block := range blocks.Result.Blocks {
+		if expected := fmt.Sprintf("%v", 108-2*index); block.Block.Header.Height != expected {
+			t.Errorf("expected block %v at height %v, got %v", index, expected, block.Block.Header.Height)
+		}
+	}
+
+	request := fmt.Sprintf(`{"jsonrpc": "2.0", "method": "tx_search", "params": {"query": "transfer.recipient='%v' AND tx.height<102", "prove": true, "page": "1", "per_page": "30", "order_by": "asc"}, "id": 7}`, testutil.TendermintRecipient(0))
+	response, err := http.Post(fmt.Sprintf("%v/%v", server.URL, testSlug), "application/json", bytes.NewBufferString(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, err = io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result = txSearch{}
+	if err := json.Unmarshal(body, &result); err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Result.Txs) != 2 || result.Result.Txs[1].Hash != testutil.TendermintTransactionHash(101, 0) || result.Result.Txs[1].Proof.RootHash == "" {
+		t.Errorf("expected the proven transactions at height 100 and 101, got %s", body)
+	}
+	if _, err := verify.VerifyItems(body); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestServeCelestiaBlockSearch(t *testing.T) {
+	server := startTestServer(t, testutil.Fixtures[3]) // Celestia
+
+	// the attributes of the block events are base64 encoded
+	query := url.Values{"query": {"commission.validator='" + testutil.TendermintValidator(1) + "'"}, "order_by": {"asc"}, "per_page": {"4"}}
+	response, body := get(t, fmt.Sprintf("%v/%v/block_search?%v", server.URL, testSlug, query.Encode()))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %v: %s", response.StatusCode, body)
+	}
+
+	proofs, err := verify.VerifyItems(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blocks struct {
+		Result struct {
+			Blocks     []types.TendermintBlock `json:"blocks"`
+			TotalCount string                  `json:"total_count"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &blocks); err != nil {
+		t.Fatal(err)
+	}
+	if len(proofs) != 4 || blocks.Result.TotalCount != "6" || blocks.Result.Blocks[0].Block.Header.Height != "101" {
+		t.Errorf("expected the first 4 of 6 blocks starting at height 101, got %s", body)
+	}
+
+	if response, _ := get(t, fmt.Sprintf("%v/%v/tx_search?%v", server.URL, testSlug, query.Encode())); response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected no tx_search for celestia, got status %v", response.StatusCode)
+	}
+}
+
+func TestServeEVMTransactionReceipt(t *testing.T) {
+	server := startTestServer(t, testutil.Fixtures[4]) // EVM
+
+	response, body := get(t, fmt.Sprintf("%v/%v/transactionReceipt?hash=%v", server.URL, testSlug, testutil.TransactionHash(105, 1)))
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %v: %s", response.StatusCode, body)
+	}
+	proof, err := verify.VerifyResponse(body, response.Header.Get(verify.ProofHeader))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.LeafScheme != utils.LeafSchemeEVMReceipt {
+		t.Errorf("expected leaf scheme %v, got %v", utils.LeafSchemeEVMReceipt, proof.LeafScheme)
+	}
+
+	var receipt struct {
+		Result struct {
+			TransactionHash string `json:"transactionHash"`
+		} `json:"result"`
+		BlockReceipts []json.RawMessage `json:"blockReceipts"`
+	}
+	if err := json.Unmarshal(body, &receipt); err != nil {
+		t.Fatal(err)
+	}
+	if receipt.Result.TransactionHash != testutil.TransactionHash(105, 1) {
+		t.Errorf("expected receipt of transaction %v, got %v", testutil.TransactionHash(105, 1), receipt.Result.TransactionHash)
+	}
+
+	// a receipt that is not one of the proven receipts of the block must not verify
+	tampered := bytes.Replace(body, []byte(`"result":{"status":`), []byte(`"result":{"forged":true,"status":`), 1)
+	if bytes.Equal(tampered, body) {
+		t.Fatalf("failed to tamper with the receipt %s", body)
+	}
+	if _, err := verify.VerifyResponse(tampered, response.Header.Get(verify.ProofHeader)); err == nil {
+		t.Error("expected a forged receipt to fail verification")
+	}
+
+	// every receipt of the block verifies, only its transaction hash binds it to the requested transaction
+	if err := verify.CheckRequest(url.Values{"hash": {strings.ToUpper(testutil.TransactionHash(105, 1))}}, body, proof); err != nil {
+		t.Errorf("expected the receipt of the requested transaction, got %v", err)
+	}
+	if err := verify.CheckRequest(url.Values{"hash": {testutil.TransactionHash(105, 0)}}, body, proof); err == nil {
+		t.Error("expected the receipt not to match another transaction of the block")
+	}
+
+	// the proof can't be downgraded, a version 1 proof would not tell the verifier to check the receipt
+	response, _ = get(t, fmt.Sprintf("%v/%v/transactionReceipt?hash=%v&proof_version=1", server.URL, testSlug, testutil.TransactionHash(105, 1)))
+	if proof, err := utils.DecodeProof(response.Header.Get(verify.ProofHeader)); err != nil || proof.Version != utils.ProofVersion2 {
+		t.Errorf("expected a version 2 proof, got %v (%v)", proof, err)
+	}
+
+	if response, _ := get(t, fmt.Sprintf("%v/%v/transactionReceipt?hash=%v", server.URL, testSlug, testutil.TransactionHash(500, 0))); response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown transaction, got %v", response.StatusCode)
+	}
+	if response, _ := get(t, fmt.Sprintf("%v/%v/blockByNumber?number=latest", server.URL, testSlug)); response.StatusCode != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid block number, got %v", response.StatusCode)
+	}
+}
+
+func TestServeEVMPrecomputedTransactions(t *testing.T) {
+	fixture := testutil.Fixtures[4] // EVM
+
+	var paths []string
+	for _, key := range testKeys {
+		for index := 0; index < 2; index++ {
+			paths = append(paths,
+				fmt.Sprintf("/transactionByHash?hash=%v", testutil.TransactionHash(key, index)),
+				fmt.Sprintf("/transactionReceipt?hash=%v", testutil.TransactionHash(key, index)),
+			)
+		}
+	}
+
+	// the transactions served from the intermediate items of the blocks
+	type served struct {
+		body  []byte
+		proof string
+	}
+	expected := map[string]served{}
+	server := startTestServer(t, fixture)
+	for _, path := range paths {
+		response, body := get(t, fmt.Sprintf("%v/%v%v", server.URL, testSlug, path))
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("%v: expected status 200, got %v: %s", path, response.StatusCode, body)
+		}
+		expected[path] = served{body: body, proof: response.Header.Get(verify.ProofHeader)}
+	}
+
+	server = startTestPoolServer(t, fixture, config.PoolsConfig{ChainId: "kyve-1", PoolId: 1, Indexer: fixture.Indexer, Slug: testSlug, PrecomputeTransactions: true})
+	for _, path := range paths {
+		response, body := get(t, fmt.Sprintf("%v/%v%v", server.URL, testSlug, path))
+		if response.StatusCode != http.StatusOK {
+			t.Fatalf("%v: expected status 200, got %v: %s", path, response.StatusCode, body)
+		}
+		if !bytes.Equal(body, expected[path].body) {
+			t.Errorf("%v: expected body %s, got %s", path, expected[path].body, body)
+		}
+		if proof := response.Header.Get(verify.ProofHeader); proof != expected[path].proof {
+			t.Errorf("%v: expected proof %v, got %v", path, expected[path].proof, proof)
+		}
+		if _, err := verify.VerifyResponse(body, response.Header.Get(verify.ProofHeader)); err != nil {
+			t.Errorf("%v: %v", path, err)
+		}
+	}
+
+	for _, key := range testKeys {
+		for _, path := range fixturePaths[fixture.Indexer](key) {
+			response, body := get(t, fmt.Sprintf("%v/%v%v", server.URL, testSlug, path))
+			if response.StatusCode != http.StatusOK {
+				t.Fatalf("%v: expected status 200, got %v: %s", path, response.StatusCode, body)
+			}
+			if _, err := verify.VerifyResponse(body, response.Header.Get(verify.ProofHeader)); err != nil {
+				t.Errorf("%v: %v", path, err)
+			}
+		}
+	}
+}
+
+func TestServeEVMLogs(t *testing.T) {
+	server := startTestServer(t, testutil.Fixtures[4]) // EVM
+
+	getLogs := func(query string, expectedStatus int) []byte {
+		response, body := get(t, fmt.Sprintf("%v/%v/logs?%v", server.URL, testSlug, query))
+		if response.StatusCode != expectedStatus {
+			t.Fatalf("%v: expected status %v, got %v: %s", query, expectedStatus, response.StatusCode, body)
+		}
+		return body
+	}
+
+	for query, expectedLogs := range map[string]int{
+		"blockHash=" + testutil.BlockHash(103):                               2,
+		"fromBlock=103&toBlock=0x6c&proof_version=2":                         12,
+		"fromBlock=103&toBlock=108&address=" + testutil.EVMLogAddress(1):     6,
+		"fromBlock=103&toBlock=108&topics=," + testutil.EVMLogTopic(105):     2,
+		"fromBlock=103&toBlock=108&topics=0x01|" + testutil.EVMTransferTopic: 12,
+		"fromBlock=103&toBlock=108&topics=0x01":                              0,
+	} {
+		body := getLogs(query, http.StatusOK)
+		proofs, err := verify.VerifyItems(body)
+		if err != nil {
+			t.Fatalf("%v: %v", query, err)
+		}
+		if len(proofs) != expectedLogs {
+			t.Errorf("%v: expected %v logs, got %v", query, expectedLogs, len(proofs))
+		}
+	}
+
+	// the logs of a range are only served if every block is indexed
+	getLogs("fromBlock=99&toBlock=100", http.StatusNotFound)
+	getLogs("blockHash="+testutil.BlockHash(103)+"&fromBlock=103", http.StatusBadRequest)
+	getLogs("fromBlock=100&toBlock=1000", http.StatusBadRequest)
+	getLogs("address="+testutil.EVMLogAddress(0), http.StatusBadRequest)
+
+	var withoutProofs map[string]json.RawMessage
+	if err := json.Unmarshal(getLogs("fromBlock=103&proof=false", http.StatusOK), &withoutProofs); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := withoutProofs["proofs"]; ok {
+		t.Error("expected no proofs with proof=false")
+	}
+
+	request := fmt.Sprintf(`{"jsonrpc": "2.0", "method": "eth_getLogs", "params": [{"fromBlock": "0x67", "toBlock": "0x6c", "topics": [null, [%q, %q]]}], "id": 7}`, testutil.EVMLogTopic(105), testutil.EVMLogTopic(106))
+	response, err := http.Post(fmt.Sprintf("%v/%v", server.URL, testSlug), "application/json", bytes.NewBufferString(request))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proofs, err := verify.VerifyItems(body)
+	if err != nil {
+		t.Fatalf("%v: %s", err, body)
+	}
+	if len(proofs) != 4 {
+		t.Errorf("expected 4 logs of eth_getLogs, got %v", len(proofs))
+	}
+	var rpcResponse struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(body, &rpcResponse); err != nil || rpcResponse.ID != 7 {
+		t.Errorf("expected the id of the request, got %s", body)
+	}
+}
+
+func TestServeNewPools(t *testing.T) {
+	testutil.LoadConfig(t)
+	fakeChain := testutil.NewChain(t, "kyve-1")
+
+	var pools []config.PoolsConfig
+	for poolId, fixture := range testutil.Fixtures[:2] {
+		if err := fakeChain.AddFixtureBundles(int64(poolId), fixture, 100, 3); err != nil {
+			t.Fatal(err)
+		}
+		pool := config.PoolsConfig{ChainId: "kyve-1", PoolId: int64(poolId), Indexer: fixture.Indexer, Slug: fmt.Sprintf("pool-%v", poolId)}
+		adapter, err := pool.GetDatabaseAdapter()
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := crawler.CreateBundleCrawler(adapter, fakeChain, "kyve-1", pool.PoolId, 0, semaphore.NewWeighted(1))
+		c.CrawlBundles()
+		pools = append(pools, pool)
+	}
+
+	apiServer := newApiServer()
+	apiServer.setPools(pools[:1])
+	server := httptest.NewServer(apiServer)
+	defer server.Close()
+
+	newPoolUrl := fmt.Sprintf("%v/pool-1%v", server.URL, fixturePaths["EthBlobs"](101)[0])
+	if response, _ := get(t, newPoolUrl); response.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected status 404 before the pool is added, got %v", response.StatusCode)
+	}
+
+	apiServer.setPools(pools)
+	response, body := get(t, newPoolUrl)
+	if response.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200 after the pool is added, got %v: %s", response.StatusCode, body)
+	}
+	if _, err := verify.VerifyResponse(body, response.Header.Get(verify.ProofHeader)); err != nil {
+		t.Fatal(err)
+	}
+
+	if response, _ := get(t, fmt.Sprintf("%v/pool-0/value?height=101", server.URL)); response.StatusCode != http.StatusOK {
+		t.Errorf("expected the existing pool to be served, got status %v", response.StatusCode)
+	}
+	if len(apiServer.adapters) != 2 {
+		t.Errorf("expected the adapters to be reused, got %v adapters", len(apiServer.adapters))
+	}
+
+	// remove the new pool again and exclude the proofs of the existing pool
+	pools[0].ExcludeProof = true
+	apiServer.setPools(pools[:1])
+	if response, _ := get(t, newPoolUrl); response.StatusCode != http.StatusGone {
+		t.Errorf("expected status 410 after the pool is removed, got %v", response.StatusCode)
+	}
+	if response, _ := get(t, fmt.Sprintf("%v/unknown/value?height=101", server.URL)); response.StatusCode != http.StatusNotFound {
+		t.Errorf("expected status 404 for an unknown pool, got %v", response.StatusCode)
+	}
+	response, _ = get(t, fmt.Sprintf("%v/pool-0/value?height=101", server.URL))
+	if response.StatusCode != http.StatusOK || response.Header.Get(verify.ProofHeader) != "" {
+		t.Errorf("expected the existing pool to be served without proof, got status %v", response.StatusCode)
+	}
+
+	// the pool is served again once it is added back
+	apiServer.setPools(pools)
+	if response, _ := get(t, newPoolUrl); response.StatusCode != http.StatusOK {
+		t.Errorf("expected status 200 after the pool is added back, got %v", response.StatusCode)
+	}
+}
+
+func TestServeAdminReload(t *testing.T) {
+	testutil.LoadConfig(t)
+
+	pools := []config.PoolsConfig{{ChainId: "kyve-1", PoolId: 0, Indexer: "Height", Slug: "height"}}
+	apiServer := newApiServer()
+	apiServer.setPools(pools)
+	server := httptest.NewServer(apiServer)
+	defer server.Close()
+
+	reload := func(token string) int {
+		request, err := http.NewRequest(http.MethodPost, server.URL+"/admin/reload", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		request.Header.Set("Authorization", "Bearer "+token)
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = response.Body.Close()
+		return response.StatusCode
+	}
+
+	if status := reload("secret"); status != http.StatusNotFound {
+		t.Errorf("expected status 404 without admin token, got %v", status)
+	}
+
+	viper.Set("server.admin-token", "secret")
+	viper.Set("pools", pools)
+	t.Cleanup(func() {
+		viper.Set("server.admin-token", "")
+		viper.Set("pools", nil)
+	})
+	apiServer.setPools(pools)
+	if status := reload("wrong"); status != http.StatusUnauthorized {
+		t.Errorf("expected status 401 with a wrong token, got %v", status)
+	}
+	if status := reload("secret"); status != http.StatusOK {
+		t.Errorf("expected status 200 with the admin token, got %v", status)
+	}
+
+	// an invalid config is rejected, the current config is kept
+	viper.Set("server.max-batch-size", 0)
+	if status := reload("secret"); status != http.StatusBadRequest {
+		t.Errorf("expected status 400 for an invalid config, got %v", status)
+	}
+	viper.Set("server.max-batch-size", 100)
+	if response, _ := get(t, server.URL+"/height/value?height=100"); response.StatusCode == http.StatusGone {
+		t.Errorf("expected the pool to be served after a rejected reload, got status %v", response.StatusCode)
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"net/http"
+
+	"github.com/KYVENetwork/trustless-api/status"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+// getPoolStatus serves the sync progress of a single pool
+func (apiServer *ApiServer) getPoolStatus(c *gin.Context, pool ServePool) {
+	poolStatus, err := status.GetPoolStatus(pool.Config, pool.Adapter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal error", "message": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.JSON(http.StatusOK, poolStatus)
+}
+
+// getStatus serves the sync progress of all pools
+func (apiServer *ApiServer) getStatus(c *gin.Context, pools []ServePool) {
+	statuses := make([]*types.PoolStatus, len(pools))
+
+	var g errgroup.Group
+	for index := range pools {
+		localIndex := index
+		g.Go(func() error {
+			poolStatus, err := status.GetPoolStatus(pools[localIndex].Config, pools[localIndex].Adapter)
+			statuses[localIndex] = poolStatus
+			return err
+		})
+	}
+	if err := g.Wait(); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal error", "message": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.JSON(http.StatusOK, statuses)
+}
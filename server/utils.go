@@ -3,7 +3,10 @@ package server
 import (
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
+	"github.com/KYVENetwork/trustless-api/types"
 	"gopkg.in/yaml.v3"
 )
 
@@ -38,6 +41,55 @@ func generateOpenApi(pools []ServePool) ([]byte, error) {
 					}
 					parameters = append(parameters, currentParameter)
 				}
+
+				if len(param.OptionalParameter) != len(param.OptionalDescription) {
+					logger.Error().Msg("optional parameter and description length mismatch")
+					continue
+				}
+
+				for i, parameterName := range param.OptionalParameter {
+					parameters = append(parameters, map[string]interface{}{
+						"name":        parameterName,
+						"in":          "query",
+						"description": param.OptionalDescription[i],
+						"required":    false,
+						"schema": map[string]interface{}{
+							"type": "string",
+						},
+					})
+				}
+			}
+
+			// endpoints with a single numeric key can be queried as a range
+			var responseSchema interface{} = map[string]string{
+				"$ref": fmt.Sprintf("#/components/schemas/%v", value.Schema),
+			}
+			if rangeParam, ok := getRangeParameter(value); ok {
+				name := rangeParam.Parameter[0]
+				parameters = append(parameters, map[string]interface{}{
+					"name":        fmt.Sprintf("from_%v", name),
+					"in":          "query",
+					"description": "start of a range query (inclusive), serves a `RangeResponse` instead of a single data item",
+					"required":    false,
+					"schema": map[string]interface{}{
+						"type": "string",
+					},
+				})
+				parameters = append(parameters, map[string]interface{}{
+					"name":        fmt.Sprintf("to_%v", name),
+					"in":          "query",
+					"description": "end of a range query (inclusive), ranges are paged by `max-range-size`",
+					"required":    false,
+					"schema": map[string]interface{}{
+						"type": "string",
+					},
+				})
+				responseSchema = map[string]interface{}{
+					"oneOf": []map[string]string{
+						{"$ref": fmt.Sprintf("#/components/schemas/%v", value.Schema)},
+						{"$ref": "#/components/schemas/RangeResponse"},
+					},
+				}
 			}
 
 			if !p.ExcludeProof {
@@ -50,6 +102,15 @@ func generateOpenApi(pools []ServePool) ([]byte, error) {
 						"type": "string",
 					},
 				})
+				parameters = append(parameters, map[string]interface{}{
+					"name":        "proof_version",
+					"in":          "query",
+					"description": "version of the KYVE Proof, `1` (default) or `2`. Can also be requested with the Accept header, e.g. `application/json; kyve-proof-version=2`",
+					"required":    false,
+					"schema": map[string]interface{}{
+						"type": "string",
+					},
+				})
 			}
 
 			path["parameters"] = parameters
@@ -65,6 +126,13 @@ func generateOpenApi(pools []ServePool) ([]byte, error) {
 							"example": "AIQAAAA...Jhhf6ut",
 						},
 					},
+					"x-kyve-proof-version": map[string]interface{}{
+						"description": "Version the KYVE Data Item Inclusion Proof is encoded with.",
+						"schema": map[string]string{
+							"type":    "string",
+							"example": "2",
+						},
+					},
 				}
 			}
 
@@ -73,29 +141,43 @@ func generateOpenApi(pools []ServePool) ([]byte, error) {
 					"description": "successful operation",
 					"content": map[string]interface{}{
 						"application/json": map[string]interface{}{
-							"schema": map[string]string{
-								"$ref": fmt.Sprintf("#/components/schemas/%v", value.Schema),
-							},
+							"schema": responseSchema,
 						},
 					},
 					"headers": headers,
 				},
-				http.StatusNotFound: map[string]interface{}{
-					"description": "not found",
-					"content": map[string]interface{}{
-						"application/json": map[string]interface{}{
-							"schema": map[string]string{
-								"$ref": fmt.Sprintf("#/components/schemas/%vError", value.Schema),
-							},
-						},
-					},
-				},
+				http.StatusBadRequest:          generateErrorResponse("invalid params", value.Schema),
+				http.StatusNotFound:            generateErrorResponse("not found, the error data describes why the data item is missing, see `MissingDataItem`", value.Schema),
+				http.StatusInternalServerError: generateErrorResponse("internal error", value.Schema),
+				http.StatusBadGateway:          generateErrorResponse("data item could not be loaded from the storage", value.Schema),
 			}
-			// because we only support get requests we set the method to get
 			paths[fmt.Sprintf("/%v%v", p.Slug, prefix)] = map[string]interface{}{
 				"get": path,
 			}
 		}
+
+		paths[fmt.Sprintf("/%v/status", p.Slug)] = map[string]interface{}{
+			"get": generateStatusPath([]string{p.Slug}, "Sync progress and indexed range of the pool", map[string]string{
+				"$ref": "#/components/schemas/PoolStatus",
+			}),
+		}
+
+		paths[fmt.Sprintf("/%v/bundle", p.Slug)] = map[string]interface{}{
+			"get": generateBundlePath(p.Slug),
+		}
+
+		if methods := getJsonRpcMethods(adapterIndexer); len(methods) > 0 {
+			paths[fmt.Sprintf("/%v", p.Slug)] = map[string]interface{}{
+				"post": generateJsonRpcPath(p, methods),
+			}
+		}
+	}
+
+	paths["/status"] = map[string]interface{}{
+		"get": generateStatusPath([]string{"status"}, "Sync progress and indexed range of all pools", map[string]interface{}{
+			"type":  "array",
+			"items": map[string]string{"$ref": "#/components/schemas/PoolStatus"},
+		}),
 	}
 
 	ymlString, err := yaml.Marshal(map[string]interface{}{
@@ -108,3 +190,146 @@ func generateOpenApi(pools []ServePool) ([]byte, error) {
 
 	return ymlString, nil
 }
+
+// generateStatusPath generates the OpenAPI path of a status endpoint
+func generateStatusPath(tags []string, description string, schema interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"tags":        tags,
+		"description": description,
+		"responses": map[int32]interface{}{
+			http.StatusOK: map[string]interface{}{
+				"description": "successful operation",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": schema,
+					},
+				},
+			},
+		},
+	}
+}
+
+// generateBundlePath generates the OpenAPI path of the bundle endpoint of a pool
+func generateBundlePath(slug string) map[string]interface{} {
+	errorResponse := func(description string) map[string]interface{} {
+		return map[string]interface{}{
+			"description": description,
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]string{
+						"$ref": "#/components/schemas/DataItemError",
+					},
+				},
+			},
+		}
+	}
+
+	return map[string]interface{}{
+		"tags":        []string{slug},
+		"description": "Metadata of an indexed bundle, including the merkle root computed by the indexer and the leaf hashes it is computed from",
+		"parameters": []map[string]interface{}{
+			{
+				"name":        "bundle_id",
+				"in":          "query",
+				"description": "bundle id",
+				"required":    true,
+				"schema": map[string]interface{}{
+					"type": "integer",
+				},
+			},
+		},
+		"responses": map[int32]interface{}{
+			http.StatusOK: map[string]interface{}{
+				"description": "successful operation",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]string{
+							"$ref": "#/components/schemas/Bundle",
+						},
+					},
+				},
+			},
+			http.StatusBadRequest: errorResponse("invalid params"),
+			http.StatusNotFound:   errorResponse("bundle is not indexed"),
+		},
+	}
+}
+
+// generateErrorResponse generates the OpenAPI response of an error, the error schema of an endpoint is its schema with the suffix `Error`
+func generateErrorResponse(description string, schema string) map[string]interface{} {
+	return map[string]interface{}{
+		"description": description,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]string{
+					"$ref": fmt.Sprintf("#/components/schemas/%vError", schema),
+				},
+			},
+		},
+	}
+}
+
+// generateJsonRpcPath generates the OpenAPI path of the native JSON-RPC endpoint of a pool
+func generateJsonRpcPath(pool ServePool, methods map[string]types.Endpoint) map[string]interface{} {
+	var methodNames []string
+	for method := range methods {
+		methodNames = append(methodNames, method)
+	}
+	sort.Strings(methodNames)
+
+	path := map[string]interface{}{}
+	path["tags"] = []string{pool.Slug}
+	path["description"] = fmt.Sprintf("Native JSON-RPC 2.0 endpoint, batch requests are answered with an array of responses that carry their proof inline. Supported methods: `%v`", strings.Join(methodNames, "`, `"))
+	path["requestBody"] = map[string]interface{}{
+		"required": true,
+		"content": map[string]interface{}{
+			"application/json": map[string]interface{}{
+				"schema": map[string]interface{}{
+					"oneOf": []map[string]interface{}{
+						{"$ref": "#/components/schemas/JsonRPCRequest"},
+						{
+							"type":  "array",
+							"items": map[string]string{"$ref": "#/components/schemas/JsonRPCRequest"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	var headers map[string]interface{}
+	if !pool.ExcludeProof {
+		headers = map[string]interface{}{
+			"x-kyve-proof": map[string]interface{}{
+				"description": "KYVE Data Item Inclusion Proof Base64 encoded.",
+				"schema": map[string]string{
+					"type":    "string",
+					"example": "AIQAAAA...Jhhf6ut",
+				},
+			},
+		}
+	}
+
+	path["responses"] = map[int32]interface{}{
+		http.StatusOK: map[string]interface{}{
+			"description": "successful operation, errors are returned as JSON-RPC error",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{
+						"oneOf": []map[string]interface{}{
+							{"$ref": "#/components/schemas/JsonRPC"},
+							{"$ref": "#/components/schemas/JsonRPCError"},
+							{
+								"type":  "array",
+								"items": map[string]string{"$ref": "#/components/schemas/JsonRPCBatchResponse"},
+							},
+						},
+					},
+				},
+			},
+			"headers": headers,
+		},
+	}
+
+	return path
+}
@@ -0,0 +1,112 @@
+package status
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/KYVENetwork/trustless-api/db"
+	"github.com/KYVENetwork/trustless-api/files"
+	"github.com/KYVENetwork/trustless-api/utils"
+)
+
+var (
+	logger = utils.TrustlessApiLogger("status")
+)
+
+// Pool is a pool whose health is checked
+type Pool struct {
+	Config  config.PoolsConfig
+	Adapter db.Adapter
+}
+
+// CheckHealth checks the database connection of all pools and whether the storage is reachable
+func CheckHealth(pools []Pool, storage files.SaveDataItem) error {
+	var errs []error
+	for _, pool := range pools {
+		if err := pool.Adapter.Ping(); err != nil {
+			errs = append(errs, fmt.Errorf("database of pool %v is not reachable: %w", pool.Config.Slug, err))
+		}
+	}
+
+	if err := storage.Ping(); err != nil {
+		errs = append(errs, fmt.Errorf("storage is not reachable: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckReadiness checks that no pool lags more than `maxBundleLag` bundles behind the chain.
+// If the latest bundle on chain can't be fetched, the lag of the pool is unknown and the pool is considered ready.
+func CheckReadiness(pools []Pool, maxBundleLag int64) error {
+	var errs []error
+	for _, pool := range pools {
+		poolStatus, err := GetPoolStatus(pool.Config, pool.Adapter)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to get status of pool %v: %w", pool.Config.Slug, err))
+			continue
+		}
+
+		if poolStatus.LatestBundleId < 0 {
+			logger.Warn().Str("slug", pool.Config.Slug).Str("err", poolStatus.Error).Msg("unknown bundle lag")
+			continue
+		}
+
+		lag := poolStatus.LatestBundleId - max(poolStatus.LatestIndexedBundleId, poolStatus.BundleStartId-1)
+		if lag > maxBundleLag {
+			errs = append(errs, fmt.Errorf("pool %v lags %v bundles behind the chain, max %v", pool.Config.Slug, lag, maxBundleLag))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// HealthHandler serves the liveness probe, see CheckHealth
+func HealthHandler(pools []Pool, storage files.SaveDataItem) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeProbe(w, CheckHealth(pools, storage))
+	}
+}
+
+// ReadinessHandler serves the readiness probe, see CheckReadiness
+func ReadinessHandler(pools []Pool, maxBundleLag int64) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		writeProbe(w, CheckReadiness(pools, maxBundleLag))
+	}
+}
+
+// StartProbes serves `/healthz` and `/readyz` on the given port, this is used by processes without an api server.
+// The pools are checked with the pools and the maximum bundle lag returned by `getPools` at the time of the request.
+func StartProbes(port string, getPools func() ([]Pool, int64), storage files.SaveDataItem) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		pools, _ := getPools()
+		HealthHandler(pools, storage)(w, r)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ReadinessHandler(getPools())(w, r)
+	})
+	go func() {
+		err := http.ListenAndServe(":"+port, mux)
+		if err != nil {
+			logger.Error().Str("err", err.Error()).Msg("probes start error")
+		}
+	}()
+	logger.Info().Str("port", port).Msg("Started health probes")
+}
+
+func writeProbe(w http.ResponseWriter, err error) {
+	response := map[string]string{"status": "ok"}
+	status := http.StatusOK
+	if err != nil {
+		response = map[string]string{"status": "unavailable", "error": err.Error()}
+		status = http.StatusServiceUnavailable
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
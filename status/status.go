@@ -0,0 +1,50 @@
+package status
+
+import (
+	"github.com/KYVENetwork/trustless-api/collectors/chain"
+	"github.com/KYVENetwork/trustless-api/config"
+	"github.com/KYVENetwork/trustless-api/db"
+	"github.com/KYVENetwork/trustless-api/types"
+)
+
+// GetPoolStatus returns the sync progress of a pool.
+// The indexed range is taken from the coverage of the adapter, the latest bundle on chain from the pool info.
+// If the pool info can't be fetched, the latest bundle on chain is -1 and the error is part of the status.
+func GetPoolStatus(poolConfig config.PoolsConfig, adapter db.Adapter) (*types.PoolStatus, error) {
+	coverage, err := adapter.GetCoverage(poolConfig.BundleStartId)
+	if err != nil {
+		return nil, err
+	}
+
+	status := types.PoolStatus{
+		Slug:                  poolConfig.Slug,
+		ChainId:               poolConfig.ChainId,
+		PoolId:                poolConfig.PoolId,
+		Indexer:               poolConfig.Indexer,
+		BundleStartId:         poolConfig.BundleStartId,
+		LatestIndexedBundleId: coverage.LatestBundleId,
+		LatestBundleId:        -1,
+		FromKey:               coverage.LowestKey,
+		ToKey:                 coverage.HighestKey,
+	}
+
+	for _, gap := range coverage.Gaps {
+		status.MissingBundles += gap.ToBundleId - gap.FromBundleId + 1
+	}
+
+	poolInfo, err := chain.NewClient(poolConfig.ChainId).GetPoolInfo(poolConfig.PoolId)
+	if err != nil {
+		status.Error = err.Error()
+		return &status, nil
+	}
+
+	status.LatestBundleId = poolInfo.Pool.Data.TotalBundles - 1
+
+	// all bundles after the latest indexed bundle are missing as well
+	lastCovered := max(coverage.LatestBundleId, poolConfig.BundleStartId-1)
+	if status.LatestBundleId > lastCovered {
+		status.MissingBundles += status.LatestBundleId - lastCovered
+	}
+
+	return &status, nil
+}
@@ -0,0 +1,12 @@
+package types
+
+import "errors"
+
+var (
+	// ErrNotFound is returned if the requested data item is not indexed
+	ErrNotFound = errors.New("not found")
+	// ErrInvalidParams is returned if the request parameters can't be used to look up a data item
+	ErrInvalidParams = errors.New("invalid params")
+	// ErrStorage is returned if an indexed data item can't be loaded from the file storage
+	ErrStorage = errors.New("storage error")
+)
@@ -42,10 +42,13 @@ type DataItem struct {
 }
 
 type Bundle struct {
-	DataItems []DataItem
-	PoolId    int64
-	BundleId  int64
-	ChainId   string
+	DataItems       []DataItem
+	PoolId          int64
+	BundleId        int64
+	ChainId         string
+	FinalizedBundle *FinalizedBundle // metadata of the bundle on chain, nil if unknown
+	MerkleRoot      string           // hex encoded merkle root computed from the indexed data items
+	Leafs           [][32]byte       // leafs of the indexed data items the merkle root is computed from
 }
 
 type Pagination struct {
@@ -124,6 +127,7 @@ type TrustlessDataItem struct {
 	Value    json.RawMessage `json:"value"`
 	Proof    string          `json:"proof,omitempty"` // proof is not included if excludeProof is true
 	Indices  []Index         `json:"-"`
+	Events   []Event         `json:"-"` // events are stored in the database to search the data item by its events
 	PoolId   int64           `json:"-"`
 	BundleId int64           `json:"-"`
 	ChainId  string          `json:"-"`
@@ -134,6 +138,34 @@ type Index struct {
 	IndexId int
 }
 
+// Event is a single attribute of an ABCI event, block events have the tx index -1
+type Event struct {
+	CompositeKey string // `<event type>.<attribute key>`, e. g. transfer.recipient
+	Value        string
+	Height       int64
+	TxIndex      int
+}
+
+// EventCondition is a single condition of an event query, e. g. `transfer.recipient='kyve1...'`
+type EventCondition struct {
+	CompositeKey string
+	// Operator is one of =, <, <=, >, >=, CONTAINS and EXISTS
+	Operator string
+	Value    string
+	// Number is set if the operand is a number, the condition then compares the numeric value of the attribute
+	Number *float64
+}
+
+// EventSearch selects the data items whose events match all conditions
+type EventSearch struct {
+	Conditions []EventCondition
+	// TxEvents searches the events of transactions if set, otherwise the events of blocks
+	TxEvents   bool
+	Page       int
+	PerPage    int
+	Descending bool
+}
+
 type MerkleNode struct {
 	Left bool   `json:"left"`
 	Hash string `json:"hash"`
@@ -165,6 +197,9 @@ type ParameterIndex struct {
 	Parameter   []string
 	Description []string
 	Schema      string
+	// OptionalParameter are passed to the indexer after the parameters, an optional parameter that is not set is passed as empty string
+	OptionalParameter   []string
+	OptionalDescription []string
 }
 
 type TendermintDataItem struct {
@@ -178,23 +213,40 @@ type TendermintValue struct {
 }
 
 type Proof struct {
+	Version          uint8        `json:"version"`
 	Hashes           []MerkleNode `json:"proof"`
 	BundleId         int64        `json:"bundleId"`
 	ChainId          string       `json:"chainId"`
 	PoolId           int64        `json:"poolId"`
 	DataItemKey      string       `json:"dataItemKey"`
 	DataItemValueKey string       `json:"dataItemValueKey"`
+	LeafScheme       uint8        `json:"leafScheme,omitempty"` // only available since version 2
+	BundleRoot       string       `json:"bundleRoot,omitempty"` // only available since version 2
 }
 
 type Endpoint struct {
 	QueryParameter []ParameterIndex
 	Schema         string
+	JsonRpcMethod  string // name of the JSON-RPC method that is served by this endpoint, empty if the endpoint is only served via GET
 }
 
 type TendermintBlock struct {
 	BlockId struct {
 		Hash string `json:"hash"`
 	} `json:"block_id"`
+	Block struct {
+		Header struct {
+			Height   string `json:"height"`
+			DataHash string `json:"data_hash"`
+		} `json:"header"`
+		Data struct {
+			Txs [][]byte `json:"txs"` // base64 encoded in the response
+		} `json:"data"`
+	} `json:"block"`
+}
+
+type TendermintBlockResults struct {
+	TxsResults []json.RawMessage `json:"txs_results"`
 }
 
 type CelestiaBlob struct {
@@ -208,4 +260,80 @@ type CelestiaBlob struct {
 type InterceptionResponse struct {
 	Data  *[]byte
 	Proof string
+	// Proofs are the proofs of the items of a response that contains multiple items, they are served in the `proofs` array next to the items
+	Proofs []string
+}
+
+// Coverage describes the range of data items a pool has indexed
+type Coverage struct {
+	BundleStartId  int64         `json:"bundleStartId"`
+	LatestBundleId int64         `json:"latestBundleId"` // -1 if no bundle is indexed yet
+	LowestKey      string        `json:"lowestKey"`
+	HighestKey     string        `json:"highestKey"`
+	Gaps           []CoverageGap `json:"gaps"`
+}
+
+// CoverageGap is a range of bundles that are not indexed yet.
+// The gap contains all keys between AfterKey and BeforeKey, an empty key means the gap is unbounded on that side.
+type CoverageGap struct {
+	FromBundleId int64  `json:"fromBundleId"`
+	ToBundleId   int64  `json:"toBundleId"`
+	AfterKey     string `json:"afterKey"`
+	BeforeKey    string `json:"beforeKey"`
+}
+
+// MissingDataItem is served as error data if a requested data item is not indexed
+type MissingDataItem struct {
+	Reason      string `json:"reason"`
+	Retry       bool   `json:"retry"` // whether the data item might be indexed later on
+	TipBundleId int64  `json:"tipBundleId"`
+	TipKey      string `json:"tipKey"`
+}
+
+// PoolStatus describes the sync progress of a pool
+type PoolStatus struct {
+	Slug                  string `json:"slug"`
+	ChainId               string `json:"chainId"`
+	PoolId                int64  `json:"poolId"`
+	Indexer               string `json:"indexer"`
+	BundleStartId         int64  `json:"bundleStartId"`
+	LatestIndexedBundleId int64  `json:"latestIndexedBundleId"` // -1 if no bundle is indexed yet
+	LatestBundleId        int64  `json:"latestBundleId"`        // latest bundle on chain, -1 if it could not be fetched
+	MissingBundles        int64  `json:"missingBundles"`
+	FromKey               string `json:"fromKey"`
+	ToKey                 string `json:"toKey"`
+	Error                 string `json:"error,omitempty"`
+}
+
+// MultiProof proves multiple leafs of the same bundle, see merkle.GetMultiProof
+type MultiProof struct {
+	ChainId     string   `json:"chainId"`
+	PoolId      int64    `json:"poolId"`
+	BundleId    int64    `json:"bundleId"`
+	BundleRoot  string   `json:"bundleRoot"`
+	LeafCount   int      `json:"leafCount"`
+	LeafIndices []int    `json:"leafIndices"`
+	Hashes      []string `json:"hashes"`
+}
+
+// RangeItem is a single data item of a range query.
+// The leaf of the item is computed like the leaf of a single proof (see verify.GetLeaf) and folded with the local proof,
+// the result is the leaf with the index `LeafIndex` of the multi proof of the bundle.
+type RangeItem struct {
+	Key              string          `json:"key"`
+	Value            json.RawMessage `json:"value"`
+	BundleId         int64           `json:"bundleId"`
+	LeafIndex        int             `json:"leafIndex"`
+	DataItemKey      string          `json:"dataItemKey,omitempty"`
+	DataItemValueKey string          `json:"dataItemValueKey,omitempty"`
+	LeafScheme       uint8           `json:"leafScheme,omitempty"`
+	LocalProof       []MerkleNode    `json:"localProof,omitempty"`
+	Proof            string          `json:"proof,omitempty"` // the encoded single proof, only if there is no multi proof for the bundle
+}
+
+// RangeResponse is served for range queries
+type RangeResponse struct {
+	Items       []RangeItem  `json:"items"`
+	MultiProofs []MultiProof `json:"multiProofs"`
+	Next        string       `json:"next,omitempty"` // start of the next page, empty if this is the last page
 }
@@ -29,6 +29,61 @@ const (
 	IndexEVMTransaction         = 10
 	IndexEVMReceipt             = 11
 	IndexEVMLog                 = 12
+	// IndexEVMTransactionReceipt selects the receipt of a transaction, the data item is looked up with IndexEVMTransaction
+	IndexEVMTransactionReceipt = 13
+	IndexTendermintTx          = 14
+	// IndexTendermintTxSearch and IndexTendermintBlockSearch select an event search, the data items are looked up by their events
+	IndexTendermintTxSearch    = 15
+	IndexTendermintBlockSearch = 16
+)
+
+const (
+	ProofVersion1 = 1
+	ProofVersion2 = 2
+
+	// DefaultProofVersion is served if the client does not request a specific proof version
+	DefaultProofVersion = ProofVersion1
+)
+
+// Leaf schemes describe how the leaf of a proof is constructed from the served response
+const (
+	// LeafSchemeUnknown is used for version 1 proofs, which don't contain a leaf scheme
+	LeafSchemeUnknown = 0
+	// LeafSchemeDataItem the leaf is the hash of the data item `{"key": dataItemKey, "value": response[dataItemValueKey]}` (Height, EthBlobs)
+	LeafSchemeDataItem = 1
+	// LeafSchemeTendermint the leaf is the hash of `response[dataItemValueKey]`, nested in the block/block_results layout of the Tendermint indexer
+	LeafSchemeTendermint = 2
+	// LeafSchemeCelestia the leaf is the hash of `response[dataItemValueKey]`, nested in the block/block_results/blobs layout of the Celestia indexer
+	LeafSchemeCelestia = 3
+	// LeafSchemeEVM the leaf is the hash of `response[dataItemValueKey]`, nested in the block/transactions/receipts/logs layout of the EVM indexer
+	LeafSchemeEVM = 4
+	// LeafSchemeEVMReceipt the leaf is the hash of `response[dataItemValueKey]`, the receipts of the block nested in the layout of the EVM indexer,
+	// the served receipt `response.result` has to be one of them
+	LeafSchemeEVMReceipt = 5
+	// LeafSchemeTendermintTx the leaf is the hash of the block and block results `response.block` and `response.block_results`
+	// nested in the layout of the Tendermint indexer, the served transaction `response[dataItemValueKey]` has to be part of both
+	LeafSchemeTendermintTx = 6
+)
+
+// JSON-RPC 2.0 error codes
+const (
+	JsonRpcParseError     = -32700
+	JsonRpcInvalidRequest = -32600
+	JsonRpcMethodNotFound = -32601
+	JsonRpcInvalidParams  = -32602
+	JsonRpcInternalError  = -32603
+)
+
+// Reasons why a requested data item is not indexed
+const (
+	// MissingOutOfRange the data item is before the first indexed data item of the pool
+	MissingOutOfRange = "out_of_range"
+	// MissingNotYetIndexed the data item is after the latest indexed data item of the pool
+	MissingNotYetIndexed = "not_yet_indexed"
+	// MissingGap the data item is part of a bundle that has not been indexed yet
+	MissingGap = "gap"
+	// MissingNotFound the data item is within the indexed range but does not exist
+	MissingNotFound = "not_found"
 )
 
 const (
@@ -39,4 +94,6 @@ const (
 const (
 	DefaultChainId     = ChainIdMainnet
 	DefaultRegistryURL = "https://raw.githubusercontent.com/KYVENetwork/source-registry/main/.github/registry.yml"
+	// DefaultRegistryInterval is the default interval in seconds in which the pools of the registry are refreshed
+	DefaultRegistryInterval = 600
 )
@@ -10,6 +10,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"math"
 	"net/http"
 	"runtime"
 	runtimeDebug "runtime/debug"
@@ -174,9 +175,24 @@ func GetUniqueDataitemName(item *types.TrustlessDataItem) string {
 	return CreateSha256Checksum([]byte(output))
 }
 
+// IsKeyIndex returns whether the first query parameter of the index is the key of the data item, e. g. the block height
+func IsKeyIndex(indexId int) bool {
+	switch indexId {
+	case IndexBlockHeight, IndexBlobByNamespace, IndexTendermintBlock, IndexTendermintBlockResults, IndexAllBlobsByNamespace:
+		return true
+	}
+	return false
+}
+
+// IsSearchIndex returns whether the index is an event search, its results change whenever new data items are indexed
+func IsSearchIndex(indexId int) bool {
+	return indexId == IndexTendermintTxSearch || indexId == IndexTendermintBlockSearch
+}
+
 // EncodeProof encodes the proof of a data item into a byte array
-// encoded in big endian
-// Structure:
+// encoded in big endian, the structure depends on the version of the proof.
+//
+// Structure version 1:
 // - 1 	bytes: version (uint8)
 // - 2  bytes: poolId (uint16)
 // - 8  bytes: bundleId (uint64)
@@ -187,23 +203,56 @@ func GetUniqueDataitemName(item *types.TrustlessDataItem) string {
 //   - 1 byte:  left (true/false)
 //   - 32 bytes: hash (sha256)
 //
+// Structure version 2:
+// - 1 	bytes: version (uint8)
+// - poolId (unsigned varint)
+// - 8  bytes: bundleId (uint64)
+// - 1  byte: leafScheme (uint8), describes how the leaf is constructed, see LeafScheme constants
+// - 32 bytes: bundleRoot, the merkle root of the bundle the proof folds up to
+// - chainId (string, null terminated)
+// - dataItemKey (string, null terminated)
+// - dataItemValueKey (string, null terminated)
+// - Array of merkle nodes:
+//   - 1 byte:  left (true/false)
+//   - 32 bytes: hash (sha256)
+//
 // returns the proof as Base64
-func EncodeProof(poolId, bundleId int64, chainId string, dataItemKey, dataItemValueKey string, proof []types.MerkleNode) string {
+func EncodeProof(proof *types.Proof) (string, error) {
 	bytes := make([]byte, 0, 32)
 
-	// version
-	bytes = append(bytes, 1)
-
-	bytes = binary.BigEndian.AppendUint16(bytes, uint16(poolId))
-	bytes = binary.BigEndian.AppendUint64(bytes, uint64(bundleId))
+	switch proof.Version {
+	case ProofVersion1:
+		if proof.PoolId < 0 || proof.PoolId > math.MaxUint16 {
+			return "", fmt.Errorf("pool id %v can't be encoded with proof version %v", proof.PoolId, proof.Version)
+		}
+		// the leaf alone does not prove the served receipt or transaction, the verifier has to know the leaf scheme
+		if proof.LeafScheme == LeafSchemeEVMReceipt || proof.LeafScheme == LeafSchemeTendermintTx {
+			return "", fmt.Errorf("leaf scheme %v can't be encoded with proof version %v", proof.LeafScheme, proof.Version)
+		}
+		bytes = append(bytes, ProofVersion1)
+		bytes = binary.BigEndian.AppendUint16(bytes, uint16(proof.PoolId))
+		bytes = binary.BigEndian.AppendUint64(bytes, uint64(proof.BundleId))
+	case ProofVersion2:
+		bundleRoot, err := hex.DecodeString(proof.BundleRoot)
+		if err != nil || len(bundleRoot) != 32 {
+			return "", fmt.Errorf("invalid bundle root %v", proof.BundleRoot)
+		}
+		bytes = append(bytes, ProofVersion2)
+		bytes = binary.AppendUvarint(bytes, uint64(proof.PoolId))
+		bytes = binary.BigEndian.AppendUint64(bytes, uint64(proof.BundleId))
+		bytes = append(bytes, proof.LeafScheme)
+		bytes = append(bytes, bundleRoot...)
+	default:
+		return "", fmt.Errorf("unknown proof version %v", proof.Version)
+	}
 
 	// Append chainId, dataItemKey, and dataItemValueKey as null-terminated strings
-	for _, str := range []string{chainId, dataItemKey, dataItemValueKey} {
+	for _, str := range []string{proof.ChainId, proof.DataItemKey, proof.DataItemValueKey} {
 		bytes = append(bytes, str...)
 		bytes = append(bytes, 0)
 	}
 
-	for _, merkleNode := range proof {
+	for _, merkleNode := range proof.Hashes {
 		if merkleNode.Left {
 			bytes = append(bytes, 1)
 		} else {
@@ -213,12 +262,12 @@ func EncodeProof(poolId, bundleId int64, chainId string, dataItemKey, dataItemVa
 		bytes = append(bytes, hashBytes...)
 	}
 
-	return base64.StdEncoding.EncodeToString(bytes)
+	return base64.StdEncoding.EncodeToString(bytes), nil
 }
 
 // DecodeProof decodes the proof of a data item from a byte array
 // encodedProofString is the base64 string of the proof
-// see EncodeProof for more information
+// both proof versions are supported, see EncodeProof for more information
 // returns the proof as a struct
 func DecodeProof(encodedProofString string) (*types.Proof, error) {
 
@@ -233,17 +282,33 @@ func DecodeProof(encodedProofString string) (*types.Proof, error) {
 
 	proof := &types.Proof{}
 
-	version := encodedProof[0]
-
-	if version != 1 {
+	proof.Version = encodedProof[0]
+
+	switch proof.Version {
+	case ProofVersion1:
+		proof.PoolId = int64(binary.BigEndian.Uint16(encodedProof[1:3]))
+		proof.BundleId = int64(binary.BigEndian.Uint64(encodedProof[3:11]))
+		encodedProof = encodedProof[11:]
+	case ProofVersion2:
+		poolId, n := binary.Uvarint(encodedProof[1:])
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid pool id encoding")
+		}
+		encodedProof = encodedProof[1+n:]
+		// bundleId, leafScheme & bundleRoot
+		if len(encodedProof) < 41 {
+			return nil, fmt.Errorf("encoded proof is too short")
+		}
+		proof.PoolId = int64(poolId)
+		proof.BundleId = int64(binary.BigEndian.Uint64(encodedProof[0:8]))
+		proof.LeafScheme = encodedProof[8]
+		proof.BundleRoot = hex.EncodeToString(encodedProof[9:41])
+		encodedProof = encodedProof[41:]
+	default:
 		return nil, fmt.Errorf("invalid version")
 	}
 
-	proof.PoolId = int64(binary.BigEndian.Uint16(encodedProof[1:3]))
-	proof.BundleId = int64(binary.BigEndian.Uint64(encodedProof[3:11]))
-
 	// Convert the byte slice to null-terminated strings
-	encodedProof = encodedProof[11:]
 	fields := []struct {
 		name  string
 		value *string
@@ -279,6 +344,28 @@ func DecodeProof(encodedProofString string) (*types.Proof, error) {
 	return proof, nil
 }
 
+// TranscodeProof re-encodes an encoded proof with the requested version and returns it together with the version it is encoded with.
+// If the proof can't be represented in the requested version, e. g. a version 1 proof lacks the leaf scheme and bundle root,
+// the pool id does not fit into a version 1 proof or the leaf scheme is required to verify the response, the proof is returned unchanged.
+func TranscodeProof(encodedProof string, version uint8) (string, uint8) {
+	proof, err := DecodeProof(encodedProof)
+	if err != nil {
+		return encodedProof, 0
+	}
+
+	if proof.Version == version || proof.Version == ProofVersion1 {
+		return encodedProof, proof.Version
+	}
+
+	proof.Version = version
+	transcodedProof, err := EncodeProof(proof)
+	if err != nil {
+		return encodedProof, ProofVersion2
+	}
+
+	return transcodedProof, version
+}
+
 func WrapIntoJsonRpcResponse(result interface{}) ([]byte, error) {
 	type Response struct {
 		JsonRPC string      `json:"jsonrpc"`
@@ -301,24 +388,50 @@ func WrapIntoJsonRpcResponse(result interface{}) ([]byte, error) {
 }
 
 func WrapIntoJsonRpcErrorResponse(errorMessage string, data any) any {
+	return NewJsonRpcErrorResponse(json.RawMessage("-1"), JsonRpcInternalError, errorMessage, data)
+}
+
+// NewJsonRpcErrorResponse creates a JSON-RPC 2.0 error response for the request with the given id
+func NewJsonRpcErrorResponse(id json.RawMessage, code int, errorMessage string, data any) any {
 	type ErrorResponse struct {
 		Message string `json:"message"`
 		Code    int    `json:"code"`
 		Data    any    `json:"data"`
 	}
 
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
 	response := struct {
-		JsonRPC string        `json:"jsonrpc"`
-		ID      int           `json:"id"`
-		Error   ErrorResponse `json:"error"`
+		JsonRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Error   ErrorResponse   `json:"error"`
 	}{
 		JsonRPC: "2.0",
-		ID:      -1,
+		ID:      id,
 		Error: ErrorResponse{
 			Message: errorMessage,
-			Code:    -32603,
+			Code:    code,
 			Data:    data,
 		},
 	}
 	return response
 }
+
+// SetJsonRpcId replaces the id of an encoded JSON-RPC 2.0 response with the id of the request.
+// The proofs only cover the result and the fields next to it, therefore the id can be changed without invalidating them.
+// Fields next to the result, e. g. the block of a transaction, are kept.
+func SetJsonRpcId(response []byte, id json.RawMessage) ([]byte, error) {
+	var envelope map[string]json.RawMessage
+	if err := json.Unmarshal(response, &envelope); err != nil {
+		return nil, err
+	}
+
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+	envelope["id"] = id
+
+	return json.Marshal(envelope)
+}
@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/KYVENetwork/trustless-api/types"
+)
+
+func TestProofEncoding(t *testing.T) {
+	proof := types.Proof{
+		Version:          ProofVersion2,
+		PoolId:           70000,
+		BundleId:         1337,
+		ChainId:          ChainIdMainnet,
+		DataItemKey:      "42",
+		DataItemValueKey: "value",
+		LeafScheme:       LeafSchemeDataItem,
+		BundleRoot:       strings.Repeat("ab", 32),
+		Hashes: []types.MerkleNode{
+			{Left: true, Hash: strings.Repeat("01", 32)},
+			{Left: false, Hash: strings.Repeat("02", 32)},
+		},
+	}
+
+	encoded, err := EncodeProof(&proof)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, err := DecodeProof(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(*decoded, proof) {
+		t.Errorf("expected %+v, got %+v", proof, *decoded)
+	}
+
+	// pool ids above 65535 can't be downgraded to version 1
+	if transcoded, version := TranscodeProof(encoded, ProofVersion1); transcoded != encoded || version != ProofVersion2 {
+		t.Errorf("expected proof to stay at version 2, got version %v", version)
+	}
+
+	proof.PoolId = 21
+	encoded, _ = EncodeProof(&proof)
+
+	transcoded, version := TranscodeProof(encoded, ProofVersion1)
+	if version != ProofVersion1 {
+		t.Fatalf("expected version 1, got %v", version)
+	}
+
+	decoded, err = DecodeProof(transcoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if decoded.PoolId != 21 || decoded.LeafScheme != LeafSchemeUnknown || decoded.BundleRoot != "" || !reflect.DeepEqual(decoded.Hashes, proof.Hashes) {
+		t.Errorf("unexpected version 1 proof %+v", *decoded)
+	}
+
+	// the receipt and transaction leaf schemes have to be known to verify the response, therefore they can't be downgraded to version 1
+	for _, leafScheme := range []uint8{LeafSchemeEVMReceipt, LeafSchemeTendermintTx} {
+		proof.LeafScheme = leafScheme
+		encoded, _ = EncodeProof(&proof)
+		if transcoded, version := TranscodeProof(encoded, ProofVersion1); transcoded != encoded || version != ProofVersion2 {
+			t.Errorf("leaf scheme %v: expected proof to stay at version 2, got version %v", leafScheme, version)
+		}
+	}
+}
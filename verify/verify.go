@@ -0,0 +1,668 @@
+package verify
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/KYVENetwork/trustless-api/collectors/bundles"
+	"github.com/KYVENetwork/trustless-api/collectors/chain"
+	"github.com/KYVENetwork/trustless-api/merkle"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+)
+
+// ProofHeader is the response header the server attaches the encoded proof to
+const ProofHeader = "x-kyve-proof"
+
+// GetLeaf recomputes the hash a proof starts folding from, based on the served response body.
+//
+// The leaf is constructed the same way the indexers construct it:
+//   - with the leaf scheme `LeafSchemeDataItem` (HeightIndexer, EthBlobsIndexer) the leaf is the hash of the
+//     original data item `{"key": dataItemKey, "value": response[dataItemValueKey]}`
+//   - otherwise (TendermintIndexer, CelestiaIndexer, EVMIndexer) the leaf is the hash of `response[dataItemValueKey]`,
+//     the key of the data item and the sibling sub-roots are already part of the proof's merkle nodes
+//   - with the leaf scheme `LeafSchemeEVMReceipt` the leaf is the hash of the block's receipts `response[dataItemValueKey]`,
+//     the served receipt `response.result` has to be one of them
+//   - with the leaf scheme `LeafSchemeTendermintTx` the leaf is the merkle root of the hashes of `response.block` and `response.block_results`,
+//     the served transaction `response[dataItemValueKey]` has to be part of both, see getTendermintTxLeaf
+//
+// Version 1 proofs don't contain a leaf scheme, for those the data item layout is used if the proof has a `dataItemKey`.
+func GetLeaf(body []byte, proof *types.Proof) ([32]byte, error) {
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return [32]byte{}, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	value, ok := response[proof.DataItemValueKey]
+	if !ok {
+		return [32]byte{}, fmt.Errorf("response has no field %v", proof.DataItemValueKey)
+	}
+
+	switch proof.LeafScheme {
+	case utils.LeafSchemeDataItem:
+		return utils.CalculateSHA256Hash(types.DataItem{Key: proof.DataItemKey, Value: value}), nil
+	case utils.LeafSchemeTendermint, utils.LeafSchemeCelestia, utils.LeafSchemeEVM:
+		return utils.CalculateSHA256Hash(value), nil
+	case utils.LeafSchemeEVMReceipt:
+		if !containsItem(value, response["result"]) {
+			return [32]byte{}, fmt.Errorf("served receipt is not one of the receipts in field %v", proof.DataItemValueKey)
+		}
+		return utils.CalculateSHA256Hash(value), nil
+	case utils.LeafSchemeTendermintTx:
+		return getTendermintTxLeaf(value, response["block"], response["block_results"])
+	case utils.LeafSchemeUnknown:
+		if proof.DataItemKey != "" {
+			return utils.CalculateSHA256Hash(types.DataItem{Key: proof.DataItemKey, Value: value}), nil
+		}
+		return utils.CalculateSHA256Hash(value), nil
+	}
+
+	return [32]byte{}, fmt.Errorf("unknown leaf scheme %v", proof.LeafScheme)
+}
+
+// containsItem checks if the array contains the item, the JSON of both is compared in its compact form
+func containsItem(array, item json.RawMessage) bool {
+	var items []json.RawMessage
+	if item == nil || json.Unmarshal(array, &items) != nil {
+		return false
+	}
+
+	itemHash := utils.CalculateSHA256Hash(item)
+	for _, i := range items {
+		if utils.CalculateSHA256Hash(i) == itemHash {
+			return true
+		}
+	}
+	return false
+}
+
+// getTendermintTxLeaf checks that the served transaction is the transaction of the block at its index and that its DeliverTx result
+// is the result at the same index of the block results. The CometBFT proof of the transaction has to fold up to the `data_hash`
+// of the block header. Returns the leaf of the block and block results, like the Tendermint indexer constructs it.
+func getTendermintTxLeaf(value, block, blockResults json.RawMessage) ([32]byte, error) {
+	if block == nil || blockResults == nil {
+		return [32]byte{}, fmt.Errorf("response has no block and block_results of the transaction")
+	}
+
+	var tx struct {
+		Hash     string          `json:"hash"`
+		Height   string          `json:"height"`
+		Index    int             `json:"index"`
+		TxResult json.RawMessage `json:"tx_result"`
+		Tx       []byte          `json:"tx"`
+		Proof    struct {
+			RootHash string             `json:"root_hash"`
+			Data     []byte             `json:"data"`
+			Proof    merkle.SimpleProof `json:"proof"`
+		} `json:"proof"`
+	}
+	if err := json.Unmarshal(value, &tx); err != nil {
+		return [32]byte{}, fmt.Errorf("failed to parse transaction: %w", err)
+	}
+	// only the fields above are proven, therefore the transaction must not contain any other field
+	if utils.CalculateSHA256Hash(tx) != utils.CalculateSHA256Hash(value) {
+		return [32]byte{}, fmt.Errorf("transaction contains unknown fields")
+	}
+
+	var parsedBlock types.TendermintBlock
+	if err := json.Unmarshal(block, &parsedBlock); err != nil {
+		return [32]byte{}, fmt.Errorf("failed to parse block: %w", err)
+	}
+	var parsedBlockResults types.TendermintBlockResults
+	if err := json.Unmarshal(blockResults, &parsedBlockResults); err != nil {
+		return [32]byte{}, fmt.Errorf("failed to parse block_results: %w", err)
+	}
+
+	txHash := sha256.Sum256(tx.Tx)
+	if !strings.EqualFold(tx.Hash, hex.EncodeToString(txHash[:])) {
+		return [32]byte{}, fmt.Errorf("transaction hash %v does not match the transaction", tx.Hash)
+	}
+
+	txs := parsedBlock.Block.Data.Txs
+	if tx.Height != parsedBlock.Block.Header.Height || tx.Index < 0 || tx.Index >= len(txs) || !bytes.Equal(txs[tx.Index], tx.Tx) {
+		return [32]byte{}, fmt.Errorf("transaction is not the transaction %v of block %v", tx.Index, parsedBlock.Block.Header.Height)
+	}
+
+	txsResults := parsedBlockResults.TxsResults
+	if tx.Index >= len(txsResults) || utils.CalculateSHA256Hash(txsResults[tx.Index]) != utils.CalculateSHA256Hash(tx.TxResult) {
+		return [32]byte{}, fmt.Errorf("tx_result is not the result %v of block_results", tx.Index)
+	}
+
+	dataHash, err := hex.DecodeString(parsedBlock.Block.Header.DataHash)
+	if err != nil || !strings.EqualFold(tx.Proof.RootHash, parsedBlock.Block.Header.DataHash) || !bytes.Equal(tx.Proof.Data, tx.Tx) ||
+		tx.Proof.Proof.Index != int64(tx.Index) {
+		return [32]byte{}, fmt.Errorf("proof of the transaction is not a proof of the data hash of its block")
+	}
+	if err := tx.Proof.Proof.Verify(dataHash, txHash[:]); err != nil {
+		return [32]byte{}, fmt.Errorf("invalid proof of the transaction: %w", err)
+	}
+
+	return merkle.GetMerkleRoot([][32]byte{utils.CalculateSHA256Hash(block), utils.CalculateSHA256Hash(blockResults)}), nil
+}
+
+// GetMerkleRoot computes the bundle merkle root the served response and its proof fold up to
+func GetMerkleRoot(body []byte, proof *types.Proof) ([32]byte, error) {
+	leaf, err := GetLeaf(body, proof)
+	if err != nil {
+		return [32]byte{}, err
+	}
+
+	return merkle.GetMerkleRootFromProof(leaf, proof.Hashes)
+}
+
+// GetBundleMerkleRoot fetches the finalized bundle the proof points to from the chain
+// and returns the merkle root stored in its bundle summary
+func GetBundleMerkleRoot(proof *types.Proof) (string, error) {
+	finalizedBundle, err := chain.NewClient(proof.ChainId).GetFinalizedBundle(proof.PoolId, proof.BundleId)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch bundle %v of pool %v: %w", proof.BundleId, proof.PoolId, err)
+	}
+
+	return bundles.GetSummaryMerkleRoot(finalizedBundle)
+}
+
+// VerifyResponse verifies a served response body against its encoded proof and the merkle root on chain.
+// Returns the decoded proof if the response is valid.
+func VerifyResponse(body []byte, encodedProof string) (*types.Proof, error) {
+	if encodedProof == "" {
+		return nil, fmt.Errorf("response has no proof")
+	}
+
+	proof, err := utils.DecodeProof(encodedProof)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode proof: %w", err)
+	}
+
+	computedRoot, err := GetMerkleRoot(body, proof)
+	if err != nil {
+		return nil, err
+	}
+
+	// version 2 proofs contain the bundle root they claim to fold up to
+	if proof.BundleRoot != "" && hex.EncodeToString(computedRoot[:]) != proof.BundleRoot {
+		return nil, fmt.Errorf("proof does not fold up to its bundle root: expected = %v computed = %x", proof.BundleRoot, computedRoot)
+	}
+
+	bundleRoot, err := GetBundleMerkleRoot(proof)
+	if err != nil {
+		return nil, err
+	}
+
+	if hex.EncodeToString(computedRoot[:]) != bundleRoot {
+		return nil, fmt.Errorf("merkle root mismatch: expected = %v computed = %x", bundleRoot, computedRoot)
+	}
+
+	return proof, nil
+}
+
+// keyParameters are the query parameters that select a data item by its key, e. g. `/value?height=100`
+var keyParameters = map[string]bool{"height": true, "block_height": true, "number": true}
+
+// fieldParameters maps the query parameters that select an item by one of its fields to the field of the served value
+var fieldParameters = map[string]string{"slot_number": "slot", "namespace": "namespace", "commitment": "commitment"}
+
+// CheckRequest checks that a verified response is the item the request asked for. A valid proof only shows that the item
+// is part of the pool, so every parameter that selects the item has to match the served item:
+//   - a key parameter (`height`, `block_height`, `number`) has to be the key of the data item, which is either the `dataItemKey`
+//     of the proof or, if the key is nested in the leaf layout, the hash of the key has to be one of the nodes of the proof
+//   - a `hash` has to be the hash of the served value, see getHashes. The receipt of a transaction is only proven to be one
+//     of the receipts of its block (LeafSchemeEVMReceipt), its `transactionHash` binds it to the requested transaction.
+//     Likewise, a Tendermint transaction is only proven to be part of its block (LeafSchemeTendermintTx), its `hash` binds it
+//   - a field parameter, e. g. `slot_number`, has to match the field of the served value
+//
+// The served value is `response.result` or, if the response has no result, `response[dataItemValueKey]`. Other parameters
+// don't select the item and are ignored.
+func CheckRequest(query url.Values, body []byte, proof *types.Proof) error {
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	value, ok := response["result"]
+	if !ok {
+		value = response[proof.DataItemValueKey]
+	}
+
+	for parameter := range query {
+		requested := query.Get(parameter)
+
+		switch field, isField := fieldParameters[parameter]; {
+		case keyParameters[parameter]:
+			if !hasKey(proof, requested) {
+				return fmt.Errorf("served item is not the requested item with %v %v", parameter, requested)
+			}
+		case parameter == "hash":
+			if !slices.Contains(getHashes(value), normalizeHash(requested)) {
+				return fmt.Errorf("served item is not the requested item with hash %v", requested)
+			}
+		case isField:
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(value, &fields); err != nil || !matchesField(fields[field], requested) {
+				return fmt.Errorf("served item is not the requested item with %v %v", parameter, requested)
+			}
+		}
+	}
+
+	return nil
+}
+
+// hasKey checks if the proof proves a data item with the given key, numbers can be requested as hex, e. g. EVM block numbers
+func hasKey(proof *types.Proof, key string) bool {
+	if strings.HasPrefix(key, "0x") {
+		number, err := strconv.ParseInt(key[2:], 16, 64)
+		if err != nil {
+			return false
+		}
+		key = strconv.FormatInt(number, 10)
+	}
+
+	if proof.DataItemKey != "" {
+		return proof.DataItemKey == key
+	}
+
+	keyHash := sha256.Sum256([]byte(key))
+	for _, node := range proof.Hashes {
+		if node.Hash == hex.EncodeToString(keyHash[:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// getHashes returns the normalized hashes a served value can be requested with: the `hash` of a block or transaction,
+// the `transactionHash` of an EVM receipt, the `block_id.hash` of a Tendermint block and, if the value is an array,
+// the `blockHash` that all of its items share
+func getHashes(value json.RawMessage) []string {
+	var items []struct {
+		BlockHash string `json:"blockHash"`
+	}
+	if err := json.Unmarshal(value, &items); err == nil {
+		for _, item := range items {
+			if item.BlockHash != items[0].BlockHash {
+				return nil
+			}
+		}
+		if len(items) == 0 || items[0].BlockHash == "" {
+			return nil
+		}
+		return []string{normalizeHash(items[0].BlockHash)}
+	}
+
+	var object struct {
+		Hash            string `json:"hash"`
+		TransactionHash string `json:"transactionHash"`
+		BlockId         struct {
+			Hash string `json:"hash"`
+		} `json:"block_id"`
+	}
+	if err := json.Unmarshal(value, &object); err != nil {
+		return nil
+	}
+
+	var hashes []string
+	for _, hash := range []string{object.Hash, object.TransactionHash, object.BlockId.Hash} {
+		if hash != "" {
+			hashes = append(hashes, normalizeHash(hash))
+		}
+	}
+	return hashes
+}
+
+// normalizeHash returns the lower case hex of a hash, the hash is hex with or without 0x or base64 encoded like Tendermint transaction hashes
+func normalizeHash(hash string) string {
+	trimmed := strings.TrimPrefix(strings.TrimPrefix(hash, "0x"), "0X")
+	if _, err := hex.DecodeString(trimmed); err == nil {
+		return strings.ToLower(trimmed)
+	}
+	if raw, err := base64.StdEncoding.DecodeString(hash); err == nil {
+		return hex.EncodeToString(raw)
+	}
+	return strings.ToLower(hash)
+}
+
+// matchesField checks if a field of the served value is the requested value, strings are compared as they are and other values by their JSON
+func matchesField(field json.RawMessage, requested string) bool {
+	if field == nil {
+		return false
+	}
+
+	var served string
+	if err := json.Unmarshal(field, &served); err == nil {
+		return served == requested
+	}
+	return string(field) == requested
+}
+
+// VerifyItems verifies a response that serves multiple items with a proof for each item in the `proofs` array, e. g. EVM logs or the blocks of `block_search`.
+// Every item is verified as if it was served on its own, the merkle root of each bundle is only fetched once.
+// The transactions of `tx_search` are verified with the item of the same index of the `blocks` and `block_results` arrays.
+// Returns the decoded proofs in the order of the items.
+func VerifyItems(body []byte) ([]*types.Proof, error) {
+	var response map[string]json.RawMessage
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	var encodedProofs []string
+	if err := json.Unmarshal(response["proofs"], &encodedProofs); err != nil {
+		return nil, fmt.Errorf("response has no proofs: %w", err)
+	}
+
+	bundleRoots := map[string]string{}
+	proofs := make([]*types.Proof, 0, len(encodedProofs))
+	for index, encodedProof := range encodedProofs {
+		proof, err := utils.DecodeProof(encodedProof)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode proof %v: %w", index, err)
+		}
+
+		items := getItems(response[proof.DataItemValueKey])
+		if len(items) != len(encodedProofs) {
+			return nil, fmt.Errorf("response field %v has to contain one item for each proof", proof.DataItemValueKey)
+		}
+
+		itemResponse := map[string]json.RawMessage{proof.DataItemValueKey: items[index]}
+
+		// transactions are proven together with their block and block results, which are served in the same order as the transactions
+		if proof.LeafScheme == utils.LeafSchemeTendermintTx {
+			blocks, blockResults := getItems(response["blocks"]), getItems(response["block_results"])
+			if len(blocks) != len(encodedProofs) || len(blockResults) != len(encodedProofs) {
+				return nil, fmt.Errorf("response fields blocks and block_results have to contain one item for each proof")
+			}
+			itemResponse["block"], itemResponse["block_results"] = blocks[index], blockResults[index]
+		}
+
+		itemBody, err := json.Marshal(itemResponse)
+		if err != nil {
+			return nil, err
+		}
+
+		computedRoot, err := GetMerkleRoot(itemBody, proof)
+		if err != nil {
+			return nil, fmt.Errorf("item %v: %w", index, err)
+		}
+
+		key := fmt.Sprintf("%v/%v/%v", proof.ChainId, proof.PoolId, proof.BundleId)
+		bundleRoot, ok := bundleRoots[key]
+		if !ok {
+			bundleRoot, err = GetBundleMerkleRoot(proof)
+			if err != nil {
+				return nil, err
+			}
+			bundleRoots[key] = bundleRoot
+		}
+
+		if hex.EncodeToString(computedRoot[:]) != bundleRoot {
+			return nil, fmt.Errorf("item %v: merkle root mismatch: expected = %v computed = %x", index, bundleRoot, computedRoot)
+		}
+		proofs = append(proofs, proof)
+	}
+
+	return proofs, nil
+}
+
+// getItems returns the items of a response field, the field is either the array of items itself
+// or an object with the array of items as its only array, e. g. the `blocks` of `block_search`
+func getItems(field json.RawMessage) []json.RawMessage {
+	var items []json.RawMessage
+	if err := json.Unmarshal(field, &items); err == nil {
+		return items
+	}
+
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(field, &object); err != nil {
+		return nil
+	}
+
+	var found []json.RawMessage
+	for _, value := range object {
+		var array []json.RawMessage
+		if err := json.Unmarshal(value, &array); err == nil && array != nil {
+			if found != nil {
+				return nil
+			}
+			found = array
+		}
+	}
+	return found
+}
+
+// VerifyRange verifies a range response, see types.RangeResponse. The leaf of every item is computed like for a single proof
+// and folded with its local proof, the leafs of a bundle are folded together with the hashes of its multi proof up to
+// the bundle root, which has to be the merkle root on chain. Items of legacy bundles carry a single proof and are verified
+// like a single response. Every item has to be proven and its key has to be the key of the proven data item.
+// Returns a proof for each item in the order of the items.
+func VerifyRange(body []byte) ([]*types.Proof, error) {
+	var response types.RangeResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	multiProofs := map[int64]*types.MultiProof{}
+	for index := range response.MultiProofs {
+		multiProof := &response.MultiProofs[index]
+		if _, ok := multiProofs[multiProof.BundleId]; ok {
+			return nil, fmt.Errorf("bundle %v has more than one multi proof", multiProof.BundleId)
+		}
+		multiProofs[multiProof.BundleId] = multiProof
+	}
+
+	proofs := make([]*types.Proof, len(response.Items))
+	bundleLeafs := map[int64]map[int][32]byte{}
+	for index, item := range response.Items {
+		if item.Proof != "" {
+			proof, err := VerifyResponse(item.Value, item.Proof)
+			if err != nil {
+				return nil, fmt.Errorf("item %v: %w", item.Key, err)
+			}
+			if !hasKey(proof, item.Key) {
+				return nil, fmt.Errorf("item %v: proof does not prove the key of the item", item.Key)
+			}
+			proofs[index] = proof
+			continue
+		}
+
+		multiProof, ok := multiProofs[item.BundleId]
+		if !ok {
+			return nil, fmt.Errorf("item %v has no proof", item.Key)
+		}
+
+		proof := &types.Proof{
+			ChainId:          multiProof.ChainId,
+			PoolId:           multiProof.PoolId,
+			BundleId:         multiProof.BundleId,
+			BundleRoot:       multiProof.BundleRoot,
+			DataItemKey:      item.DataItemKey,
+			DataItemValueKey: item.DataItemValueKey,
+			LeafScheme:       item.LeafScheme,
+			Hashes:           item.LocalProof,
+		}
+		if !hasKey(proof, item.Key) {
+			return nil, fmt.Errorf("item %v: proof does not prove the key of the item", item.Key)
+		}
+
+		leaf, err := GetMerkleRoot(item.Value, proof)
+		if err != nil {
+			return nil, fmt.Errorf("item %v: %w", item.Key, err)
+		}
+
+		if bundleLeafs[item.BundleId] == nil {
+			bundleLeafs[item.BundleId] = map[int][32]byte{}
+		}
+		if _, ok := bundleLeafs[item.BundleId][item.LeafIndex]; ok {
+			return nil, fmt.Errorf("item %v: leaf %v of bundle %v is proven twice", item.Key, item.LeafIndex, item.BundleId)
+		}
+		bundleLeafs[item.BundleId][item.LeafIndex] = leaf
+		proofs[index] = proof
+	}
+
+	for _, multiProof := range response.MultiProofs {
+		// the multi proof has to prove exactly the leafs of its items
+		leafs := bundleLeafs[multiProof.BundleId]
+		if len(leafs) != len(multiProof.LeafIndices) {
+			return nil, fmt.Errorf("bundle %v: multi proof proves %v leafs, the range contains %v", multiProof.BundleId, len(multiProof.LeafIndices), len(leafs))
+		}
+		for _, leafIndex := range multiProof.LeafIndices {
+			if _, ok := leafs[leafIndex]; !ok {
+				return nil, fmt.Errorf("bundle %v: range contains no item with leaf %v", multiProof.BundleId, leafIndex)
+			}
+		}
+
+		computedRoot, err := merkle.GetMerkleRootFromMultiProof(multiProof.LeafCount, leafs, multiProof.Hashes)
+		if err != nil {
+			return nil, fmt.Errorf("bundle %v: %w", multiProof.BundleId, err)
+		}
+		if hex.EncodeToString(computedRoot[:]) != multiProof.BundleRoot {
+			return nil, fmt.Errorf("bundle %v: multi proof does not fold up to its bundle root: expected = %v computed = %x", multiProof.BundleId, multiProof.BundleRoot, computedRoot)
+		}
+
+		bundleRoot, err := GetBundleMerkleRoot(&types.Proof{ChainId: multiProof.ChainId, PoolId: multiProof.PoolId, BundleId: multiProof.BundleId})
+		if err != nil {
+			return nil, err
+		}
+		if hex.EncodeToString(computedRoot[:]) != bundleRoot {
+			return nil, fmt.Errorf("bundle %v: merkle root mismatch: expected = %v computed = %x", multiProof.BundleId, bundleRoot, computedRoot)
+		}
+	}
+
+	return proofs, nil
+}
+
+// checkRange checks that the items of a verified range response are within the requested range,
+// e. g. `from_height` and `to_height` (both inclusive)
+func checkRange(query url.Values, body []byte) error {
+	var response types.RangeResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	for parameter := range keyParameters {
+		bounds := []int64{math.MinInt64, math.MaxInt64}
+		for index, prefix := range []string{"from_", "to_"} {
+			if requested := query.Get(prefix + parameter); requested != "" {
+				bound, err := strconv.ParseInt(requested, 10, 64)
+				if err != nil {
+					return fmt.Errorf("invalid %v%v %v", prefix, parameter, requested)
+				}
+				bounds[index] = bound
+			}
+		}
+
+		for _, item := range response.Items {
+			key, err := strconv.ParseInt(item.Key, 10, 64)
+			if err != nil || key < bounds[0] || key > bounds[1] {
+				return fmt.Errorf("served item %v is not within the requested range of %v", item.Key, parameter)
+			}
+		}
+	}
+
+	return nil
+}
+
+// VerifyUrl requests the given trustless api url, verifies the response and checks that it is the requested item, see CheckRequest
+func VerifyUrl(requestUrl string) (*types.Proof, error) {
+	query, err := getQuery(requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	body, encodedProof, err := get(requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	proof, err := VerifyResponse(body, encodedProof)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckRequest(query, body, proof); err != nil {
+		return nil, err
+	}
+	return proof, nil
+}
+
+// VerifyUrlItems requests the given trustless api url and verifies every item of the response.
+// Responses with a single proof in the proof header are verified with VerifyResponse, range responses with VerifyRange
+// and responses with a `proofs` array with VerifyItems. The items of a response with a `proofs` array are selected by a filter,
+// e. g. a search query, therefore only single items are checked with CheckRequest and the items of a range with its bounds.
+func VerifyUrlItems(requestUrl string) ([]*types.Proof, error) {
+	query, err := getQuery(requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	body, encodedProof, err := get(requestUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if encodedProof == "" {
+		var response map[string]json.RawMessage
+		if err := json.Unmarshal(body, &response); err != nil {
+			return nil, fmt.Errorf("failed to parse response: %w", err)
+		}
+		if _, ok := response["multiProofs"]; !ok {
+			return VerifyItems(body)
+		}
+
+		proofs, err := VerifyRange(body)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkRange(query, body); err != nil {
+			return nil, err
+		}
+		return proofs, nil
+	}
+
+	proof, err := VerifyResponse(body, encodedProof)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := CheckRequest(query, body, proof); err != nil {
+		return nil, err
+	}
+	return []*types.Proof{proof}, nil
+}
+
+// getQuery returns the query parameters of the url
+func getQuery(requestUrl string) (url.Values, error) {
+	parsed, err := url.Parse(requestUrl)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %v: %w", requestUrl, err)
+	}
+	return parsed.Query(), nil
+}
+
+// get requests the url and returns the response body together with the proof header
+func get(url string) ([]byte, string, error) {
+	response, err := http.Get(url)
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("got status code %d != 200", response.StatusCode)
+	}
+
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return body, response.Header.Get(ProofHeader), nil
+}
@@ -0,0 +1,215 @@
+package verify
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KYVENetwork/trustless-api/files"
+	"github.com/KYVENetwork/trustless-api/indexer"
+	"github.com/KYVENetwork/trustless-api/indexer/helper"
+	"github.com/KYVENetwork/trustless-api/internal/testutil"
+	"github.com/KYVENetwork/trustless-api/merkle"
+	"github.com/KYVENetwork/trustless-api/types"
+	"github.com/KYVENetwork/trustless-api/utils"
+	"github.com/spf13/viper"
+)
+
+func createBundle(size int, value func(height int) string) *types.Bundle {
+	bundle := types.Bundle{PoolId: 1, BundleId: 42, ChainId: "kyve-1"}
+	for i := 0; i < size; i++ {
+		bundle.DataItems = append(bundle.DataItems, types.DataItem{
+			Key:   fmt.Sprintf("%v", 100+i),
+			Value: json.RawMessage(value(100 + i)),
+		})
+	}
+	return &bundle
+}
+
+// createFixtureBundle creates a bundle with the data items of a fixture
+func createFixtureBundle(fixture testutil.Fixture, size int) *types.Bundle {
+	return &types.Bundle{PoolId: 1, BundleId: 42, ChainId: "kyve-1", DataItems: fixture.DataItems(100, size)}
+}
+
+// tamper changes the field of the body the proof verifies, objects get an additional field,
+// arrays an additional item and any other value is replaced
+func tamper(t *testing.T, body []byte, key string) []byte {
+	var response map[string]any
+	if err := json.Unmarshal(body, &response); err != nil {
+		t.Fatal(err)
+	}
+
+	switch value := response[key].(type) {
+	case map[string]any:
+		value["tampered"] = true
+	case []any:
+		response[key] = append(value, "tampered")
+	default:
+		response[key] = "tampered"
+	}
+
+	tampered, err := json.Marshal(response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tampered
+}
+
+// servedResponse is a response body together with its encoded proof
+type servedResponse struct {
+	index string
+	body  []byte
+	proof string
+}
+
+// servedItems mimics the server, which serves the value of the trustless data item together with its proof.
+// Items without proof are not served with a proof.
+func servedItems(t *testing.T, _ indexer.Indexer, items []types.TrustlessDataItem) []servedResponse {
+	var responses []servedResponse
+	for _, item := range items {
+		if item.Proof == "" {
+			continue
+		}
+
+		body, err := json.Marshal(item.Value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		responses = append(responses, servedResponse{item.Indices[0].Index, body, item.Proof})
+	}
+	return responses
+}
+
+// servedInterceptions mimics the server for indexers that create the response and its proof on request, e. g. EVM.
+// The items are stored as local files and every index of an item is requested from the indexer.
+func servedInterceptions(t *testing.T, idx indexer.Indexer, items []types.TrustlessDataItem) []servedResponse {
+	viper.Set("storage.path", t.TempDir())
+	t.Cleanup(func() { viper.Set("storage.path", nil) })
+
+	saved := map[string]files.SavedFile{}
+	for i := range items {
+		file, err := files.LocalFileAdapter.Save(&items[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, index := range items[i].Indices {
+			saved[fmt.Sprintf("%v/%v", index.IndexId, index.Index)] = file
+		}
+	}
+	get := func(indexId int, key string) (files.SavedFile, error) {
+		file, ok := saved[fmt.Sprintf("%v/%v", indexId, key)]
+		if !ok {
+			return files.SavedFile{}, types.ErrNotFound
+		}
+		return file, nil
+	}
+
+	var responses []servedResponse
+	for _, item := range items {
+		for _, index := range item.Indices {
+			response, err := idx.InterceptRequest(get, index.IndexId, []string{index.Index})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if response == nil || response.Proof == "" {
+				continue
+			}
+			responses = append(responses, servedResponse{index.Index, *response.Data, response.Proof})
+		}
+	}
+	return responses
+}
+
+func TestVerifyIndexedBundles(t *testing.T) {
+	heightBundle := createBundle(5, func(height int) string {
+		return fmt.Sprintf(`{"height": %v, "hash": "<%v>"}`, height, height)
+	})
+	tendermintBundle := createBundle(3, func(height int) string {
+		return fmt.Sprintf(`{"block": {"block_id": {"hash": "%v"}}, "block_results": {"height": "%v"}}`, height, height)
+	})
+
+	tests := []struct {
+		name    string
+		indexer indexer.Indexer
+		bundle  *types.Bundle
+		serve   func(t *testing.T, idx indexer.Indexer, items []types.TrustlessDataItem) []servedResponse
+	}{
+		{"Height", &indexer.HeightIndexer, heightBundle, servedItems},
+		{"Tendermint", &indexer.TendermintIndexer, tendermintBundle, servedItems},
+		{"TendermintTx", &indexer.TendermintIndexer, createFixtureBundle(testutil.Fixtures[2], 3), servedInterceptions},
+		{"EthBlobs", &indexer.EthBlobIndexer, createFixtureBundle(testutil.Fixtures[1], 4), servedItems},
+		{"Celestia", &indexer.CelestiaIndexer, createFixtureBundle(testutil.Fixtures[3], 3), servedItems},
+		{"EVM", &indexer.EVMIndexer, createFixtureBundle(testutil.Fixtures[4], 3), servedInterceptions},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			items, leafs, err := test.indexer.IndexBundle(test.bundle)
+			if err != nil {
+				t.Fatal(err)
+			}
+			expectedRoot := merkle.GetMerkleRoot(*leafs)
+
+			responses := test.serve(t, test.indexer, *items)
+			for _, response := range responses {
+				proof, err := utils.DecodeProof(response.proof)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				root, err := GetMerkleRoot(response.body, proof)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if root != expectedRoot {
+					t.Errorf("item %v: expected root %x, got %x", response.index, expectedRoot, root)
+				}
+				if proof.BundleRoot != hex.EncodeToString(expectedRoot[:]) {
+					t.Errorf("item %v: expected the bundle root %x in the proof, got %v", response.index, expectedRoot, proof.BundleRoot)
+				}
+
+				// a tampered item either fails to compute its leaf or folds up to another root
+				tamperedRoot, err := GetMerkleRoot(tamper(t, response.body, proof.DataItemValueKey), proof)
+				if err == nil && tamperedRoot == expectedRoot {
+					t.Errorf("item %v: tampered item folds up to the bundle root", response.index)
+				}
+			}
+
+			if len(responses) == 0 {
+				t.Error("expected responses with a proof")
+			}
+		})
+	}
+}
+
+func TestVerifyCorruptedBundleProof(t *testing.T) {
+	items, _, err := indexer.EVMIndexer.IndexBundle(createFixtureBundle(testutil.Fixtures[4], 3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// corrupt the stored bundle proof of every block, the bundle root of the proofs must not be derived from it
+	for i, item := range *items {
+		var intermediateItem helper.IntermediateItem
+		if err := json.Unmarshal(item.Value, &intermediateItem); err != nil || intermediateItem.BundleRoot == "" {
+			continue
+		}
+		intermediateItem.BundleProof[0].Hash = strings.Repeat("00", 32)
+		if (*items)[i].Value, err = json.Marshal(intermediateItem); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	responses := servedInterceptions(t, &indexer.EVMIndexer, *items)
+	for _, response := range responses {
+		if _, err := VerifyResponse(response.body, response.proof); err == nil || !strings.Contains(err.Error(), "does not fold up to its bundle root") {
+			t.Errorf("item %v: expected the proof not to fold up to its bundle root, got %v", response.index, err)
+		}
+	}
+	if len(responses) == 0 {
+		t.Error("expected responses with a proof")
+	}
+}